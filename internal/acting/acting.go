@@ -0,0 +1,50 @@
+// Package acting carries the profile a request acts as. A guardian manages
+// dependents from their own account and picks one per request with the
+// X-Profile-ID header; services calling each other forward it unchanged.
+package acting
+
+import (
+	"context"
+	"monorepo/pkg/utils"
+	"net/http"
+	"strings"
+)
+
+// Header selects the acting profile. Without it a request acts as the
+// account's own profile.
+const Header = "X-Profile-ID"
+
+type contextKey struct{}
+
+// Middleware stores the requested profile in the request context. Whether the
+// caller may act as it is checked by the user service when the profile is resolved.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		profileID := strings.TrimSpace(r.Header.Get(Header))
+		if profileID != "" {
+			r = r.WithContext(WithProfileID(r.Context(), profileID))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithProfileID returns a copy of ctx acting as profileID.
+func WithProfileID(ctx context.Context, profileID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, profileID)
+}
+
+// ProfileID returns the acting profile, or an empty string for the account's own.
+func ProfileID(ctx context.Context) string {
+	profileID, _ := ctx.Value(contextKey{}).(string)
+	return profileID
+}
+
+// Headers appends the acting profile header to headers when one is set.
+func Headers(ctx context.Context, headers []utils.Header) []utils.Header {
+	if profileID := ProfileID(ctx); profileID != "" {
+		headers = append(headers, utils.Header{Key: Header, Value: profileID})
+	}
+
+	return headers
+}
@@ -15,14 +15,35 @@ type Environment struct {
 	SMTPPort           int    `env:"SMTP_PORT"`
 	SMTPAuthEmail      string `env:"SMTP_AUTH_EMAIL"`
 	SMTPAuthPassword   string `env:"SMTP_AUTH_PASSWORD"`
+	MailTransport      string `env:"MAIL_TRANSPORT"`
+	MailWorkers        int    `env:"MAIL_WORKERS"`
+	MailMaxAttempts    int    `env:"MAIL_MAX_ATTEMPTS"`
+	SMSProvider        string `env:"SMS_PROVIDER"`
+	SMSFrom            string `env:"SMS_FROM"`
+	TwilioAccountSID   string `env:"TWILIO_ACCOUNT_SID"`
+	TwilioAuthToken    string `env:"TWILIO_AUTH_TOKEN"`
 	CsMail             string `env:"CS_MAIL"`
 	ResetPasswordUrl   string `env:"RESET_PASSWORD_URL"`
-	DirPath            string `env:"DIR_PATH"`
+	ChangeEmailUrl     string `env:"CHANGE_EMAIL_URL"`
+	VerifyEmailUrl     string `env:"VERIFY_EMAIL_URL"`
+	ContactConsentUrl  string `env:"CONTACT_CONSENT_URL"`
+	MFAIssuer          string `env:"MFA_ISSUER"`
+	MFARequiredRoles   string `env:"MFA_REQUIRED_ROLES"`
+	DeletionGraceDays  int    `env:"DELETION_GRACE_DAYS"`
 	OSSEndpoint        string `env:"OSS_ENDPOINT"`
 	OSSAccessKeyID     string `env:"OSS_ACCESS_KEY_ID"`
 	OSSAccessKeySecret string `env:"OSS_ACCESS_KEY_SECRET"`
 	OSSBucketName      string `env:"OSS_BUCKET_NAME"`
+	StorageDriver      string `env:"STORAGE_DRIVER"`
+	StorageDir         string `env:"STORAGE_DIR"`
+	StorageBaseURL     string `env:"STORAGE_BASE_URL"`
+	S3Endpoint         string `env:"S3_ENDPOINT"`
+	S3Region           string `env:"S3_REGION"`
+	S3BucketName       string `env:"S3_BUCKET_NAME"`
+	S3AccessKeyID      string `env:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey  string `env:"S3_SECRET_ACCESS_KEY"`
 	BaseURLUser        string `env:"BASE_URL_USER"`
 	Capacity           string `env:"CAPACITY"`
 	BaseURLClinic      string `env:"BASE_URL_CLINIC"`
+	MacroPresets       string `env:"MACRO_PRESETS"`
 }
@@ -0,0 +1,7 @@
+package constants
+
+const (
+	DeletionPending   = "pending"
+	DeletionCanceled  = "canceled"
+	DeletionCompleted = "completed"
+)
@@ -0,0 +1,21 @@
+package constants
+
+const (
+	AccountActive   = "active"
+	AccountDisabled = "disabled"
+)
+
+const (
+	AuditSearchUsers   = "search_users"
+	AuditViewUser      = "view_user"
+	AuditViewAsSupport = "view_as_support"
+	AuditAccountStatus = "account_status"
+	AuditAssignRole    = "assign_role"
+	AuditPasswordReset = "password_reset"
+	AuditAssignClinic  = "assign_clinic"
+	AuditRemoveClinic  = "remove_clinic"
+
+	// reads of a patient's records by clinic staff
+	AuditViewPatientSummary  = "view_patient_summary"
+	AuditViewPatientContacts = "view_patient_contacts"
+)
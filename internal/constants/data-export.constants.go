@@ -0,0 +1,8 @@
+package constants
+
+const (
+	ExportPending   = "pending"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+	ExportExpired   = "expired"
+)
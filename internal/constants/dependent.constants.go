@@ -0,0 +1,14 @@
+package constants
+
+const (
+	RelationshipChild       = "child"
+	RelationshipParent      = "parent"
+	RelationshipSpouse      = "spouse"
+	RelationshipSibling     = "sibling"
+	RelationshipGrandparent = "grandparent"
+	RelationshipOther       = "other"
+)
+
+// DependentAdultAge is the age a dependent may take over their own profile,
+// matching the age an e-KTP is issued.
+const DependentAdultAge = 17
@@ -0,0 +1,8 @@
+package constants
+
+const (
+	ConsentNone     = "none"
+	ConsentPending  = "pending"
+	ConsentAccepted = "accepted"
+	ConsentDeclined = "declined"
+)
@@ -0,0 +1,13 @@
+package constants
+
+const (
+	MealBreakfast = "breakfast"
+	MealLunch     = "lunch"
+	MealDinner    = "dinner"
+	MealSnack     = "snack"
+)
+
+const (
+	FoodSourceCatalog = "catalog"
+	FoodSourceCustom  = "custom"
+)
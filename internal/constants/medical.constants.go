@@ -0,0 +1,22 @@
+package constants
+
+const (
+	AllergyFood        = "food"
+	AllergyDrug        = "drug"
+	AllergyEnvironment = "environment"
+	AllergyOther       = "other"
+)
+
+const (
+	SeverityMild            = "mild"
+	SeverityModerate        = "moderate"
+	SeveritySevere          = "severe"
+	SeverityLifeThreatening = "life_threatening"
+	SeverityUnknown         = "unknown"
+)
+
+const (
+	ConditionActive    = "active"
+	ConditionRemission = "remission"
+	ConditionResolved  = "resolved"
+)
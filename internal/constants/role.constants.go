@@ -0,0 +1,7 @@
+package constants
+
+const (
+	RoleUser  = "user"
+	RoleStaff = "staff"
+	RoleAdmin = "admin"
+)
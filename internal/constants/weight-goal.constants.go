@@ -5,6 +5,12 @@ const (
 	WeightGoalLoss     = "loss"
 	WeightGoalMaintain = "maintain"
 
+	WeightGoalActive      = "active"
+	WeightGoalAchieved    = "achieved"
+	WeightGoalMaintaining = "maintaining"
+	WeightGoalAbandoned   = "abandoned"
+	WeightGoalSuperseded  = "superseded"
+
 	GenderMale   = "male"
 	GenderFemale = "female"
 )
@@ -30,3 +36,21 @@ const (
 	WeeklyWeightPaceNormalVal  float64 = 0.5
 	WeeklyWeightPaceStrictVal  float64 = 1
 )
+
+const (
+	WeightTrendAhead  = "ahead"
+	WeightTrendBehind = "behind"
+)
+
+const (
+	IntakeSourceDiary  = "diary"
+	IntakeSourceBudget = "budget"
+
+	CalorieAdjustManual = "manual"
+	CalorieAdjustAuto   = "auto"
+)
+
+const (
+	WeightImportNew      = "new"
+	WeightImportExisting = "existing"
+)
@@ -29,3 +29,456 @@ CREATE TABLE IF NOT EXISTS TABLE public.user_message (
 	read_at timestamptz NULL,
 	CONSTRAINT user_message_pkey PRIMARY KEY (id)
 );
+
+CREATE TABLE IF NOT EXISTS public.user_session (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	refresh_token_id text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	revoked_at timestamptz NULL,
+	deleted_at timestamptz NULL,
+	CONSTRAINT user_session_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public.email_change (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	new_email text NOT NULL,
+	change_token text NOT NULL,
+	is_used bool NOT NULL DEFAULT false,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT email_change_pkey PRIMARY KEY (id)
+);
+
+-- accounts from before verification signed in through firebase, which verified them
+ALTER TABLE public."user" ADD COLUMN IF NOT EXISTS email_verified bool NOT NULL DEFAULT true;
+ALTER TABLE public."user" ALTER COLUMN email_verified SET DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS public.email_verification (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	verify_token text NOT NULL,
+	is_used bool NOT NULL DEFAULT false,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT email_verification_pkey PRIMARY KEY (id)
+);
+
+ALTER TABLE public."user" ADD COLUMN IF NOT EXISTS "role" text NOT NULL DEFAULT 'user';
+
+CREATE TABLE IF NOT EXISTS public.user_mfa (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	secret text NOT NULL,
+	last_used_step int8 NOT NULL DEFAULT 0,
+	enabled_at timestamptz NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT user_mfa_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public.user_recovery_code (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	code_hash text NOT NULL,
+	used_at timestamptz NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT user_recovery_code_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public.mfa_challenge (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	challenge_token text NOT NULL,
+	attempts int4 NOT NULL DEFAULT 0,
+	is_used bool NOT NULL DEFAULT false,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT mfa_challenge_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public.account_deletion (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	status text NOT NULL,
+	reason text NULL,
+	scheduled_at timestamptz NOT NULL,
+	canceled_at timestamptz NULL,
+	completed_at timestamptz NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT account_deletion_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public.account_deletion_log (
+	id text NOT NULL,
+	deletion_id text NOT NULL,
+	step text NOT NULL,
+	affected_rows int8 NOT NULL DEFAULT 0,
+	error text NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT account_deletion_log_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public.deletion_certificate (
+	id text NOT NULL,
+	deletion_id text NOT NULL,
+	user_id text NOT NULL,
+	summary text NOT NULL,
+	digest text NOT NULL,
+	issued_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT deletion_certificate_pkey PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public.data_export (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	status text NOT NULL,
+	file_name text NULL,
+	error text NULL,
+	completed_at timestamptz NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT data_export_pkey PRIMARY KEY (id)
+);
+
+ALTER TABLE public.profile ADD COLUMN IF NOT EXISTS photo_key text NULL;
+
+CREATE TABLE IF NOT EXISTS public.email_outbox (
+	id text NOT NULL,
+	sender text NOT NULL,
+	recipient text NOT NULL,
+	subject text NOT NULL,
+	body text NOT NULL,
+	status text NOT NULL,
+	attempts int4 NOT NULL DEFAULT 0,
+	next_attempt_at timestamptz NOT NULL DEFAULT now(),
+	last_error text NULL,
+	sent_at timestamptz NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT email_outbox_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS email_outbox_due_idx ON public.email_outbox (status, next_attempt_at);
+
+ALTER TABLE public.email_outbox ADD COLUMN IF NOT EXISTS text_body text NULL;
+
+ALTER TABLE public.profile ADD COLUMN IF NOT EXISTS locale text NULL;
+
+ALTER TABLE public.profile ADD COLUMN IF NOT EXISTS verified_phone text NULL;
+
+-- phones used to be stored without the country code
+UPDATE public.profile SET phone = '+' || ltrim(country_code, '+') || ltrim(phone, '0') WHERE phone NOT LIKE '+%' AND phone <> '';
+UPDATE public.profile SET ec_phone = '+' || coalesce(nullif(ltrim(ec_country_code, '+'), ''), '62') || ltrim(ec_phone, '0') WHERE ec_phone NOT LIKE '+%' AND ec_phone <> '';
+
+CREATE TABLE IF NOT EXISTS public.phone_verification (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	phone text NOT NULL,
+	code_hash text NOT NULL,
+	attempts int4 NOT NULL DEFAULT 0,
+	expires_at timestamptz NOT NULL,
+	verified_at timestamptz NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT phone_verification_pkey PRIMARY KEY (id)
+);
+
+-- age is computed from dob on read, the column is only kept for profiles without a dob
+ALTER TABLE public.profile ADD COLUMN IF NOT EXISTS region_code text NULL;
+
+-- dependents have no account until their profile is handed over
+ALTER TABLE public.profile ALTER COLUMN user_id SET DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS public.profile_guardian (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	user_id text NOT NULL,
+	relationship text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT profile_guardian_pkey PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS profile_guardian_link_idx ON public.profile_guardian (profile_id, user_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS profile_guardian_user_idx ON public.profile_guardian (user_id);
+
+CREATE TABLE IF NOT EXISTS public.profile_handover (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	user_id text NOT NULL,
+	token text NOT NULL,
+	expires_at timestamptz NOT NULL,
+	claimed_by text NULL,
+	claimed_at timestamptz NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT profile_handover_pkey PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS profile_handover_token_idx ON public.profile_handover (token);
+
+CREATE TABLE IF NOT EXISTS public.profile_allergy (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	substance text NOT NULL,
+	category text NOT NULL,
+	code text NULL,
+	reaction text NULL,
+	severity text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT profile_allergy_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS profile_allergy_profile_idx ON public.profile_allergy (profile_id);
+
+CREATE TABLE IF NOT EXISTS public.profile_condition (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	name text NOT NULL,
+	code text NULL,
+	status text NOT NULL,
+	onset_at timestamptz NULL,
+	note text NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT profile_condition_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS profile_condition_profile_idx ON public.profile_condition (profile_id);
+
+CREATE TABLE IF NOT EXISTS public.profile_medication (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	name text NOT NULL,
+	code text NULL,
+	dosage text NULL,
+	frequency text NULL,
+	started_at timestamptz NULL,
+	ended_at timestamptz NULL,
+	note text NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT profile_medication_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS profile_medication_profile_idx ON public.profile_medication (profile_id);
+
+-- the free text allergies become one record each, their severity was never asked
+INSERT INTO public.profile_allergy (id, profile_id, substance, category, severity)
+SELECT md5(p.id || '|' || trim(a.substance)), p.id, trim(a.substance), 'other', 'unknown'
+FROM public.profile p, unnest(string_to_array(p.allergies, ',')) AS a(substance)
+WHERE p.allergies IS NOT NULL AND trim(a.substance) <> ''
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS public.emergency_contact (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	priority int4 NOT NULL,
+	relation text NOT NULL,
+	name text NOT NULL,
+	country_code text NOT NULL,
+	phone text NOT NULL,
+	consent text NOT NULL DEFAULT 'none',
+	consent_token text NULL,
+	consent_sent_at timestamptz NULL,
+	consent_at timestamptz NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT emergency_contact_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS emergency_contact_profile_idx ON public.emergency_contact (profile_id, priority);
+CREATE UNIQUE INDEX IF NOT EXISTS emergency_contact_consent_idx ON public.emergency_contact (consent_token);
+
+-- the single emergency contact of the profile becomes the first of the list,
+-- EmergencyContactService.NormalizePhones rewrites the copied numbers in E.164 on startup
+INSERT INTO public.emergency_contact (id, profile_id, priority, relation, name, country_code, phone)
+SELECT md5(p.id || '|emergency_contact'), p.id, 1, coalesce(nullif(p.ec_relation, ''), 'Other'), p.ec_name, coalesce(nullif(ltrim(p.ec_country_code, '+'), ''), '62'), p.ec_phone
+FROM public.profile p
+WHERE coalesce(p.ec_name, '') <> '' AND coalesce(p.ec_phone, '') <> ''
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE public."user" ADD COLUMN IF NOT EXISTS disabled_at timestamptz NULL;
+
+CREATE TABLE IF NOT EXISTS public.admin_audit_log (
+	id text NOT NULL,
+	actor_id text NOT NULL,
+	"action" text NOT NULL,
+	target_user_id text NULL,
+	detail text NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT admin_audit_log_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS admin_audit_log_target_idx ON public.admin_audit_log (target_user_id, created_at);
+CREATE INDEX IF NOT EXISTS admin_audit_log_actor_idx ON public.admin_audit_log (actor_id, created_at);
+
+-- accounts created before sign-in methods were tracked are linked on their next firebase sign in
+CREATE TABLE IF NOT EXISTS public.user_identities (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	provider text NOT NULL,
+	subject text NOT NULL,
+	firebase_uid text NOT NULL,
+	email text NULL,
+	email_verified bool NOT NULL DEFAULT false,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT user_identities_pkey PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_identities_subject_idx ON public.user_identities (provider, subject) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS user_identities_user_idx ON public.user_identities (user_id);
+CREATE INDEX IF NOT EXISTS user_identities_email_idx ON public.user_identities (email) WHERE email_verified;
+
+-- goals created before formulas could be chosen were calculated with harris-benedict
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS formula text NOT NULL DEFAULT 'harris_benedict';
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS body_fat float8 NULL;
+ALTER TABLE public.clinic ADD COLUMN IF NOT EXISTS energy_formula text NOT NULL DEFAULT '';
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS macro_preset text NOT NULL DEFAULT 'balanced';
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS protein_target float8 NOT NULL DEFAULT 0;
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS carbohydrate_target float8 NOT NULL DEFAULT 0;
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS fat_target float8 NOT NULL DEFAULT 0;
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS fibre_target float8 NOT NULL DEFAULT 0;
+
+-- catalog foods have an empty profile_id and their catalog code as id
+CREATE TABLE IF NOT EXISTS public.food (
+	id text NOT NULL,
+	profile_id text NOT NULL DEFAULT '',
+	"source" text NOT NULL,
+	"name" text NOT NULL,
+	category text NOT NULL DEFAULT '',
+	serving_size float8 NOT NULL DEFAULT 0,
+	serving_unit text NOT NULL DEFAULT '',
+	energy float8 NOT NULL DEFAULT 0,
+	protein float8 NOT NULL DEFAULT 0,
+	carbohydrate float8 NOT NULL DEFAULT 0,
+	fat float8 NOT NULL DEFAULT 0,
+	fibre float8 NOT NULL DEFAULT 0,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	updated_at timestamptz NULL,
+	deleted_at timestamptz NULL,
+	CONSTRAINT food_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS food_profile_idx ON public.food (profile_id);
+
+CREATE TABLE IF NOT EXISTS public.food_diary (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	food_id text NOT NULL,
+	food_name text NOT NULL,
+	"date" date NOT NULL,
+	meal text NOT NULL,
+	amount float8 NOT NULL,
+	unit text NOT NULL,
+	grams float8 NOT NULL DEFAULT 0,
+	energy float8 NOT NULL DEFAULT 0,
+	protein float8 NOT NULL DEFAULT 0,
+	carbohydrate float8 NOT NULL DEFAULT 0,
+	fat float8 NOT NULL DEFAULT 0,
+	fibre float8 NOT NULL DEFAULT 0,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	updated_at timestamptz NULL,
+	deleted_at timestamptz NULL,
+	CONSTRAINT food_diary_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS food_diary_profile_date_idx ON public.food_diary (profile_id, "date");
+
+CREATE TABLE IF NOT EXISTS public.exercise_log (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	activity text NOT NULL,
+	"date" date NOT NULL,
+	duration int4 NOT NULL,
+	intensity text NOT NULL DEFAULT '',
+	distance float8 NOT NULL DEFAULT 0,
+	met float8 NOT NULL,
+	weight float8 NOT NULL,
+	calories float8 NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	updated_at timestamptz NULL,
+	deleted_at timestamptz NULL,
+	CONSTRAINT exercise_log_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS exercise_log_profile_date_idx ON public.exercise_log (profile_id, "date");
+
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS auto_calibrate bool NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS public.calorie_adjustment (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	weight_goal_id text NOT NULL,
+	"source" text NOT NULL,
+	confidence text NOT NULL,
+	days int4 NOT NULL,
+	intake float8 NOT NULL,
+	intake_source text NOT NULL,
+	weight_change float8 NOT NULL,
+	previous_calories_to_maintain float8 NOT NULL,
+	calories_to_maintain float8 NOT NULL,
+	previous_daily_calories_budget float8 NOT NULL,
+	daily_calories_budget float8 NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	updated_at timestamptz NULL,
+	deleted_at timestamptz NULL,
+	CONSTRAINT calorie_adjustment_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS calorie_adjustment_profile_idx ON public.calorie_adjustment (profile_id, created_at);
+
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'active';
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS achieved_at timestamptz NULL;
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS ended_at timestamptz NULL;
+ALTER TABLE public.weight_goal ADD COLUMN IF NOT EXISTS end_weight float8 NOT NULL DEFAULT 0;
+UPDATE public.weight_goal SET status = 'maintaining', achieved_at = coalesce(updated_at, created_at) WHERE flag = 'maintain' AND status = 'active';
+-- goals used to be added without ending the previous one, all but the latest in progress are superseded
+UPDATE public.weight_goal g SET status = 'superseded', ended_at = coalesce(g.updated_at, g.created_at)
+WHERE g.status IN ('active', 'achieved', 'maintaining') AND g.deleted_at IS NULL AND EXISTS (
+	SELECT 1 FROM public.weight_goal n
+	WHERE n.profile_id = g.profile_id AND n.status IN ('active', 'achieved', 'maintaining') AND n.deleted_at IS NULL
+		AND (n.created_at, n.id) > (g.created_at, g.id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS weight_goal_current_idx ON public.weight_goal (profile_id) WHERE status IN ('active', 'achieved', 'maintaining') AND deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS public.body_measurement (
+	id text NOT NULL,
+	profile_id text NOT NULL,
+	"date" date NOT NULL,
+	waist float8 NULL,
+	hip float8 NULL,
+	neck float8 NULL,
+	chest float8 NULL,
+	body_fat float8 NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	updated_at timestamptz NULL,
+	deleted_at timestamptz NULL,
+	CONSTRAINT body_measurement_pkey PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS body_measurement_profile_date_idx ON public.body_measurement (profile_id, "date") WHERE deleted_at IS NULL;
+
+-- weights and heights are stored in kg and cm, units is only how they are shown
+ALTER TABLE public.profile ADD COLUMN IF NOT EXISTS units text NULL;
+
+-- staff read the records of patients with an appointment at the clinics they are assigned to
+CREATE TABLE IF NOT EXISTS public.clinic_staff (
+	id text NOT NULL,
+	user_id text NOT NULL,
+	clinic_id text NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now(),
+	deleted_at timestamptz NULL,
+	CONSTRAINT clinic_staff_pkey PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS clinic_staff_user_clinic_idx ON public.clinic_staff (user_id, clinic_id) WHERE deleted_at IS NULL;
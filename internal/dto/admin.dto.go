@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type FilterSearchUsers struct {
+	Query string
+	Page  int
+	Limit int
+}
+
+type FilterAuditLogs struct {
+	ActorID      string
+	TargetUserID string
+	Page         int
+	Limit        int
+}
+
+type RequestAccountStatus struct {
+	Status string `json:"status" validate:"required,oneof=active disabled"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type RequestAssignRole struct {
+	Role   string `json:"role" validate:"required,oneof=user staff admin"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type RequestClinicStaff struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type RequestAdminPasswordReset struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ResponseAdminUser struct {
+	ID            string     `json:"id"`
+	Email         string     `json:"email"`
+	Provider      string     `json:"provider"`
+	Role          string     `json:"role"`
+	Status        string     `json:"status"`
+	EmailVerified bool       `json:"email_verified"`
+	ProfileID     string     `json:"profile_id,omitempty"`
+	MedicalID     string     `json:"medical_id,omitempty"`
+	Name          string     `json:"name,omitempty"`
+	Phone         string     `json:"phone,omitempty"`
+	DisabledAt    *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type ResponseAuthProvider struct {
+	ProviderID string `json:"provider_id"`
+	UID        string `json:"uid"`
+	Email      string `json:"email,omitempty"`
+}
+
+type ResponseAdminUserDetail struct {
+	ResponseAdminUser
+	Providers   []ResponseAuthProvider `json:"providers"`
+	LastLoginAt *time.Time             `json:"last_login_at,omitempty"`
+}
+
+// ResponseSupportView is what the user sees of their own account, without
+// their medical records.
+type ResponseSupportView struct {
+	Profile    any                 `json:"profile,omitempty"`
+	Dependents []ResponseDependent `json:"dependents"`
+}
+
+type ResponseAuditLog struct {
+	ID           string          `json:"id"`
+	ActorID      string          `json:"actor_id"`
+	Action       string          `json:"action"`
+	TargetUserID string          `json:"target_user_id,omitempty"`
+	Detail       json.RawMessage `json:"detail,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
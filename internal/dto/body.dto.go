@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+// PutBodyMeasurementRequest records the measurements of a day, today when no
+// date is given. Measurements left out keep what was recorded that day.
+type PutBodyMeasurementRequest struct {
+	Date    string   `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Waist   *float64 `json:"waist,omitempty" validate:"omitempty,gt=0,lt=300"`
+	Hip     *float64 `json:"hip,omitempty" validate:"omitempty,gt=0,lt=300"`
+	Neck    *float64 `json:"neck,omitempty" validate:"omitempty,gt=0,lt=100"`
+	Chest   *float64 `json:"chest,omitempty" validate:"omitempty,gt=0,lt=300"`
+	BodyFat *float64 `json:"body_fat,omitempty" validate:"omitempty,gt=0,lt=100"`
+}
+
+type BodyMeasurementResponse struct {
+	ID      string   `json:"id"`
+	Date    string   `json:"date"`
+	Waist   *float64 `json:"waist,omitempty"`
+	Hip     *float64 `json:"hip,omitempty"`
+	Neck    *float64 `json:"neck,omitempty"`
+	Chest   *float64 `json:"chest,omitempty"`
+	BodyFat *float64 `json:"body_fat,omitempty"`
+}
+
+type FilterGetBodyMeasurements struct {
+	DateFrom string
+	DateTo   string
+	Page     int
+	Limit    int
+}
+
+// BodyComposition are the metrics of a day, each only when the measurements
+// it needs are known. The body fat is the measured one, else the US Navy
+// estimate from the circumferences.
+type BodyComposition struct {
+	Date              string   `json:"date"`
+	Weight            float64  `json:"weight,omitempty"`
+	Waist             *float64 `json:"waist,omitempty"`
+	Hip               *float64 `json:"hip,omitempty"`
+	Neck              *float64 `json:"neck,omitempty"`
+	Chest             *float64 `json:"chest,omitempty"`
+	BMI               float64  `json:"bmi,omitempty"`
+	BMIClass          string   `json:"bmi_class,omitempty"`
+	WaistToHeight     float64  `json:"waist_to_height,omitempty"`
+	WaistToHeightRisk bool     `json:"waist_to_height_risk,omitempty"`
+	WaistToHip        float64  `json:"waist_to_hip,omitempty"`
+	WaistToHipRisk    bool     `json:"waist_to_hip_risk,omitempty"`
+	BodyFat           float64  `json:"body_fat,omitempty"`
+	BodyFatSource     string   `json:"body_fat_source,omitempty"` // measured | navy
+}
+
+// BodyCompositionResponse has a point for every day with a weighing or a
+// measurement. Current takes the latest of each measurement, however old.
+type BodyCompositionResponse struct {
+	Height  float64           `json:"height,omitempty"`
+	Current *BodyComposition  `json:"current,omitempty"`
+	Series  []BodyComposition `json:"series"`
+}
+
+func (r PutBodyMeasurementRequest) Validate() error {
+	if r.Waist == nil && r.Hip == nil && r.Neck == nil && r.Chest == nil && r.BodyFat == nil {
+		return errors.New("at least one measurement is required")
+	}
+
+	if r.Date == "" {
+		return nil
+	}
+
+	date, _ := time.Parse("2006-01-02", r.Date)
+	if date.After(time.Now()) {
+		return errors.New("measurement date should not be in the future")
+	}
+
+	return nil
+}
@@ -1,49 +1,52 @@
 package dto
 
 import (
-	"errors"
-	"regexp"
+	"monorepo/pkg/energy"
+	"monorepo/pkg/phone"
 	"time"
 )
 
 type RequestCreateClinic struct {
-	Name    string `json:"name" validate:"required"`
-	Address string `json:"address" validate:"required"`
-	Phone   string `json:"phone" validate:"required"`
-	Logo    string `json:"logo" validate:"required"`
+	Name          string `json:"name" validate:"required"`
+	Address       string `json:"address" validate:"required"`
+	Phone         string `json:"phone" validate:"required"`
+	EnergyFormula string `json:"energy_formula,omitempty"`
 }
 
 type ResponseCreateClinic struct {
-	ID      string `json:"id,omitempty"`
-	Name    string `json:"name,omitempty"`
-	Address string `json:"address,omitempty"`
-	Phone   string `json:"phone,omitempty"`
-	Logo    string `json:"logo,omitempty"`
+	ID            string `json:"id,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Address       string `json:"address,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	Logo          string `json:"logo,omitempty"`
+	EnergyFormula string `json:"energy_formula,omitempty"`
 }
 
 type RequestUpdateClinic struct {
-	Name    string `json:"name" validate:"required"`
-	Address string `json:"address" validate:"required"`
-	Phone   string `json:"phone" validate:"required"`
-	Logo    string `json:"logo" validate:"required"`
+	Name          string `json:"name" validate:"required"`
+	Address       string `json:"address" validate:"required"`
+	Phone         string `json:"phone" validate:"required"`
+	EnergyFormula string `json:"energy_formula,omitempty"`
 }
 
 type ResponseUpdateClinic struct {
-	ID      string `json:"id,omitempty"`
-	Name    string `json:"name,omitempty"`
-	Address string `json:"address,omitempty"`
-	Phone   string `json:"phone,omitempty"`
-	Logo    string `json:"logo,omitempty"`
+	ID            string `json:"id,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Address       string `json:"address,omitempty"`
+	Phone         string `json:"phone,omitempty"`
+	Logo          string `json:"logo,omitempty"`
+	EnergyFormula string `json:"energy_formula,omitempty"`
 }
 
 type ResponseGetClinic struct {
-	ID        string     `json:"id,omitempty"`
-	Name      string     `json:"name,omitempty"`
-	Address   string     `json:"address,omitempty"`
-	Phone     string     `json:"phone,omitempty"`
-	Logo      string     `json:"logo,omitempty"`
-	CreatedAt time.Time  `json:"created_at,omitempty"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID            string     `json:"id,omitempty"`
+	Name          string     `json:"name,omitempty"`
+	Address       string     `json:"address,omitempty"`
+	Phone         string     `json:"phone,omitempty"`
+	Logo          string     `json:"logo,omitempty"`
+	EnergyFormula string     `json:"energy_formula,omitempty"`
+	CreatedAt     time.Time  `json:"created_at,omitempty"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
 }
 
 type FilterGetClinic struct {
@@ -52,33 +55,21 @@ type FilterGetClinic struct {
 }
 
 func (r RequestCreateClinic) Validate() error {
-	// validate phone
-	regex, err := regexp.Compile(`^[1-9]{9,12}$`)
-	if err != nil {
+	if _, err := energy.Get(r.EnergyFormula); err != nil {
 		return err
 	}
 
-	phoneMatch := regex.MatchString(r.Phone)
-	if !phoneMatch {
-		err = errors.New("phone has an invalid format")
-		return err
-	}
-
-	return nil
+	// validate phone, numbers without a country code are indonesian
+	_, err := phone.Parse("", r.Phone)
+	return err
 }
 
 func (r RequestUpdateClinic) Validate() error {
-	// validate phone
-	regex, err := regexp.Compile(`^[1-9]{9,12}$`)
-	if err != nil {
-		return err
-	}
-
-	phoneMatch := regex.MatchString(r.Phone)
-	if !phoneMatch {
-		err = errors.New("phone has an invalid format")
+	if _, err := energy.Get(r.EnergyFormula); err != nil {
 		return err
 	}
 
-	return nil
+	// validate phone, numbers without a country code are indonesian
+	_, err := phone.Parse("", r.Phone)
+	return err
 }
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"monorepo/pkg/nik"
+	"monorepo/pkg/phone"
+	"time"
+)
+
+type RequestCreateDependent struct {
+	Name         string    `json:"name" validate:"required"`
+	Relationship string    `json:"relationship" validate:"required,oneof=child parent spouse sibling grandparent other"`
+	NIK          string    `json:"nik,omitempty"`
+	DOB          time.Time `json:"dob,omitempty"`
+	Sex          string    `json:"sex,omitempty" validate:"omitempty,oneof=Male Female"`
+	CountryCode  string    `json:"country_code,omitempty"`
+	Phone        string    `json:"phone,omitempty"`
+}
+
+type ResponseDependent struct {
+	ID           string     `json:"id"`
+	MedicalID    string     `json:"medical_id"`
+	Name         string     `json:"name"`
+	Relationship string     `json:"relationship"`
+	DOB          *time.Time `json:"dob,omitempty"`
+	Sex          string     `json:"sex,omitempty"`
+	Age          string     `json:"age,omitempty"`
+	Handover     bool       `json:"handover"`
+}
+
+type ResponseProfileHandover struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type RequestClaimProfile struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (r RequestCreateDependent) Validate() error {
+	if r.NIK != "" {
+		_, err := nik.Decode(r.NIK, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	// the guardian's number is used when the dependent has none
+	if r.Phone != "" {
+		_, err := phone.Parse(r.CountryCode, r.Phone)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
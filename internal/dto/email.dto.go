@@ -1,15 +1,34 @@
 package dto
 
 type Email struct {
-	From          string    `json:"from"`
-	To            string    `json:"to"`
-	Subject       string    `json:"subject"`
-	TemplateEmail string    `json:"template_email"`
-	Body          EmailBody `json:"body"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Template string    `json:"template"`
+	Locale   string    `json:"locale"`
+	Body     EmailBody `json:"body"`
 }
 
 type EmailBody struct {
 	UserName         string `json:"user_name,omitempty"`
 	ResetPasswordUrl string `json:"reset_password_url,omitempty"`
+	ChangeEmailUrl   string `json:"change_email_url,omitempty"`
+	VerifyEmailUrl   string `json:"verify_email_url,omitempty"`
+	NewEmail         string `json:"new_email,omitempty"`
 	CsMail           string `json:"cs_mail,omitempty"`
+	ClinicName       string `json:"clinic_name,omitempty"`
+	AppointmentAt    string `json:"appointment_at,omitempty"`
+	GoalWeight       string `json:"goal_weight,omitempty"`
+}
+
+type ResponseEmailTemplates struct {
+	Templates []string `json:"templates"`
+	Locales   []string `json:"locales"`
+}
+
+type ResponseEmailPreview struct {
+	Template string `json:"template"`
+	Locale   string `json:"locale"`
+	Subject  string `json:"subject"`
+	Html     string `json:"html"`
+	Text     string `json:"text"`
 }
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"monorepo/pkg/phone"
+	"time"
+)
+
+type RequestEmergencyContact struct {
+	Relation    string `json:"relation" validate:"required,oneof=Wife Husband Mother Father Sister Brother Aunt Uncle Grandmother Grandfather Cousin Friend Spouse Child Other"`
+	Name        string `json:"name" validate:"required"`
+	CountryCode string `json:"country_code,omitempty"`
+	Phone       string `json:"phone" validate:"required"`
+}
+
+type RequestOrderEmergencyContacts struct {
+	IDs []string `json:"ids" validate:"required,min=1,dive,required"`
+}
+
+type RequestContactConsent struct {
+	Token  string `json:"token" validate:"required"`
+	Accept *bool  `json:"accept" validate:"required"`
+}
+
+type ResponseEmergencyContact struct {
+	ID        string     `json:"id"`
+	Priority  int        `json:"priority"`
+	Relation  string     `json:"relation"`
+	Name      string     `json:"name"`
+	Phone     string     `json:"phone"`
+	Consent   string     `json:"consent"`
+	ConsentAt *time.Time `json:"consent_at,omitempty"`
+}
+
+func (r RequestEmergencyContact) Validate() error {
+	_, err := phone.Parse(r.CountryCode, r.Phone)
+	return err
+}
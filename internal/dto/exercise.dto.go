@@ -0,0 +1,50 @@
+package dto
+
+type CreateExerciseLogRequest struct {
+	Activity  string  `json:"activity" validate:"required"`
+	Date      string  `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Duration  int     `json:"duration" validate:"required,gt=0,lte=1440"`
+	Intensity string  `json:"intensity,omitempty" validate:"omitempty,oneof=light moderate vigorous"`
+	Distance  float64 `json:"distance,omitempty" validate:"omitempty,gt=0"`
+}
+
+type ExerciseLogResponse struct {
+	ID        string  `json:"id"`
+	Activity  string  `json:"activity"`
+	Date      string  `json:"date"`
+	Duration  int     `json:"duration"`
+	Intensity string  `json:"intensity,omitempty"`
+	Distance  float64 `json:"distance,omitempty"`
+	MET       float64 `json:"met"`
+	Weight    float64 `json:"weight"`
+	Calories  float64 `json:"calories"`
+}
+
+type ExerciseActivityResponse struct {
+	Name       string             `json:"name"`
+	Category   string             `json:"category"`
+	Intensity  map[string]float64 `json:"intensity"`
+	SpeedBased bool               `json:"speed_based"`
+}
+
+type ExerciseDay struct {
+	Date     string  `json:"date"`
+	Sessions int     `json:"sessions"`
+	Duration int     `json:"duration"`
+	Calories float64 `json:"calories"`
+}
+
+// ExerciseWeekResponse totals a monday to sunday week. The activity factor is
+// what the week's workouts add to a sedentary day, the suggested level is set
+// when it no longer matches the activity level of the goal.
+type ExerciseWeekResponse struct {
+	From                   string        `json:"from"`
+	To                     string        `json:"to"`
+	Sessions               int           `json:"sessions"`
+	Duration               int           `json:"duration"`
+	Calories               float64       `json:"calories"`
+	Days                   []ExerciseDay `json:"days"`
+	ActivityLevel          string        `json:"activity_level,omitempty"`
+	ActivityFactor         float64       `json:"activity_factor,omitempty"`
+	SuggestedActivityLevel string        `json:"suggested_activity_level,omitempty"`
+}
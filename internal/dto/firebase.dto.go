@@ -13,6 +13,20 @@ type FirebaseUser struct {
 }
 
 type FirebaseClaims struct {
-	Role   string `json:"x-hasura-default-role,omitempty"`
-	UserID string `json:"x-hasura-user-id,omitempty"`
+	Role          string `json:"x-hasura-default-role,omitempty"`
+	UserID        string `json:"x-hasura-user-id,omitempty"`
+	SessionID     string `json:"x-hasura-session-id,omitempty"`
+	EmailVerified string `json:"x-hasura-email-verified,omitempty"`
+	MFARequired   string `json:"x-hasura-mfa-required,omitempty"`
+}
+
+// IsEmailVerified reports whether the token was issued to a user with a verified email.
+func (c FirebaseClaims) IsEmailVerified() bool {
+	return c.EmailVerified == "true"
+}
+
+// IsMFAEnrollmentRequired reports whether the user must enroll two-factor authentication
+// before using the API.
+func (c FirebaseClaims) IsMFAEnrollmentRequired() bool {
+	return c.MFARequired == "true"
 }
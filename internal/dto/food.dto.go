@@ -0,0 +1,90 @@
+package dto
+
+// Nutrients are energy in kcal and macronutrients in grams.
+type Nutrients struct {
+	Energy       float64 `json:"energy"`
+	Protein      float64 `json:"protein"`
+	Carbohydrate float64 `json:"carbohydrate"`
+	Fat          float64 `json:"fat"`
+	Fibre        float64 `json:"fibre"`
+}
+
+type FilterSearchFoods struct {
+	Query    string
+	Category string
+	Page     int
+	Limit    int
+}
+
+type CreateFoodRequest struct {
+	Name         string  `json:"name" validate:"required,max=200"`
+	Category     string  `json:"category,omitempty" validate:"max=100"`
+	ServingSize  float64 `json:"serving_size,omitempty" validate:"gte=0"`
+	ServingUnit  string  `json:"serving_unit,omitempty" validate:"max=50"`
+	Energy       float64 `json:"energy" validate:"gte=0,lte=900"`
+	Protein      float64 `json:"protein" validate:"gte=0,lte=100"`
+	Carbohydrate float64 `json:"carbohydrate" validate:"gte=0,lte=100"`
+	Fat          float64 `json:"fat" validate:"gte=0,lte=100"`
+	Fibre        float64 `json:"fibre" validate:"gte=0,lte=100"`
+}
+
+type FoodResponse struct {
+	ID          string    `json:"id"`
+	Source      string    `json:"source"`
+	Name        string    `json:"name"`
+	Category    string    `json:"category,omitempty"`
+	ServingSize float64   `json:"serving_size,omitempty"`
+	ServingUnit string    `json:"serving_unit,omitempty"`
+	Per100g     Nutrients `json:"per_100g"`
+}
+
+type FoodImportResponse struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+type CreateFoodDiaryRequest struct {
+	FoodID string  `json:"food_id" validate:"required"`
+	Date   string  `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Meal   string  `json:"meal" validate:"required,oneof=breakfast lunch dinner snack"`
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+	Unit   string  `json:"unit" validate:"required,oneof=g ml serving"`
+}
+
+type UpdateFoodDiaryRequest struct {
+	Meal   string  `json:"meal,omitempty" validate:"omitempty,oneof=breakfast lunch dinner snack"`
+	Amount float64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Unit   string  `json:"unit,omitempty" validate:"omitempty,oneof=g ml serving"`
+}
+
+type FoodDiaryResponse struct {
+	ID        string    `json:"id"`
+	FoodID    string    `json:"food_id"`
+	FoodName  string    `json:"food_name"`
+	Date      string    `json:"date"`
+	Meal      string    `json:"meal"`
+	Amount    float64   `json:"amount"`
+	Unit      string    `json:"unit"`
+	Grams     float64   `json:"grams"`
+	Nutrients Nutrients `json:"nutrients"`
+}
+
+type FoodDiaryMeal struct {
+	Meal      string    `json:"meal"`
+	Entries   int       `json:"entries"`
+	Nutrients Nutrients `json:"nutrients"`
+}
+
+// FoodDiarySummaryResponse compares a day against the active weight goal.
+// Budget, target and remaining are left out when there is no goal. Earned are
+// the calories burned with workouts, they add to the remaining calories, which
+// turn negative once the day is over budget.
+type FoodDiarySummaryResponse struct {
+	Date                string          `json:"date"`
+	DailyCaloriesBudget float64         `json:"daily_calories_budget,omitempty"`
+	Consumed            Nutrients       `json:"consumed"`
+	Earned              float64         `json:"earned"`
+	Target              *MacroTargets   `json:"target,omitempty"`
+	Remaining           *Nutrients      `json:"remaining,omitempty"`
+	Meals               []FoodDiaryMeal `json:"meals"`
+}
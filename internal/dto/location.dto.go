@@ -1,8 +1,7 @@
 package dto
 
 import (
-	"errors"
-	"regexp"
+	"monorepo/pkg/phone"
 	"time"
 )
 
@@ -62,33 +61,13 @@ type ResponseGetLocation struct {
 }
 
 func (r RequestCreateLocation) Validate() error {
-	// validate phone
-	regex, err := regexp.Compile(`^[1-9]{9,12}$`)
-	if err != nil {
-		return err
-	}
-
-	phoneMatch := regex.MatchString(r.Phone)
-	if !phoneMatch {
-		err = errors.New("phone has an invalid format")
-		return err
-	}
-
-	return nil
+	// validate phone, numbers without a country code are indonesian
+	_, err := phone.Parse("", r.Phone)
+	return err
 }
 
 func (r RequestUpdateLocation) Validate() error {
-	// validate phone
-	regex, err := regexp.Compile(`^[1-9]{9,12}$`)
-	if err != nil {
-		return err
-	}
-
-	phoneMatch := regex.MatchString(r.Phone)
-	if !phoneMatch {
-		err = errors.New("phone has an invalid format")
-		return err
-	}
-
-	return nil
+	// validate phone, numbers without a country code are indonesian
+	_, err := phone.Parse("", r.Phone)
+	return err
 }
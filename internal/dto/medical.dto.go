@@ -0,0 +1,123 @@
+package dto
+
+import (
+	"fmt"
+	"monorepo/internal/constants"
+	"monorepo/pkg/medcode"
+	"time"
+)
+
+type RequestAllergy struct {
+	Substance string `json:"substance" validate:"required"`
+	Category  string `json:"category" validate:"required,oneof=food drug environment other"`
+	Code      string `json:"code,omitempty"`
+	Reaction  string `json:"reaction,omitempty"`
+	Severity  string `json:"severity" validate:"required,oneof=mild moderate severe life_threatening unknown"`
+}
+
+type RequestCondition struct {
+	Name    string     `json:"name" validate:"required"`
+	Code    string     `json:"code,omitempty"`
+	Status  string     `json:"status" validate:"required,oneof=active remission resolved"`
+	OnsetAt *time.Time `json:"onset_at,omitempty"`
+	Note    string     `json:"note,omitempty"`
+}
+
+type RequestMedication struct {
+	Name      string     `json:"name" validate:"required"`
+	Code      string     `json:"code,omitempty"`
+	Dosage    string     `json:"dosage,omitempty"`
+	Frequency string     `json:"frequency,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Note      string     `json:"note,omitempty"`
+}
+
+type ResponseAllergy struct {
+	ID        string `json:"id"`
+	Substance string `json:"substance"`
+	Category  string `json:"category"`
+	Code      string `json:"code,omitempty"`
+	Reaction  string `json:"reaction,omitempty"`
+	Severity  string `json:"severity"`
+}
+
+type ResponseCondition struct {
+	ID      string     `json:"id"`
+	Name    string     `json:"name"`
+	Code    string     `json:"code,omitempty"`
+	Status  string     `json:"status"`
+	OnsetAt *time.Time `json:"onset_at,omitempty"`
+	Note    string     `json:"note,omitempty"`
+}
+
+type ResponseMedication struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Code      string     `json:"code,omitempty"`
+	Dosage    string     `json:"dosage,omitempty"`
+	Frequency string     `json:"frequency,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Note      string     `json:"note,omitempty"`
+}
+
+// ResponseMedicalSummary is what a clinic needs at hand during an
+// appointment: active conditions and current medications only.
+type ResponseMedicalSummary struct {
+	ProfileID         string                     `json:"profile_id"`
+	MedicalID         string                     `json:"medical_id"`
+	Name              string                     `json:"name"`
+	Age               string                     `json:"age,omitempty"`
+	Sex               string                     `json:"sex,omitempty"`
+	BloodType         string                     `json:"blood_type,omitempty"`
+	Weight            float64                    `json:"weight,omitempty"`
+	Height            float64                    `json:"height,omitempty"`
+	Allergies         []ResponseAllergy          `json:"allergies"`
+	Conditions        []ResponseCondition        `json:"conditions"`
+	Medications       []ResponseMedication       `json:"medications"`
+	EmergencyContacts []ResponseEmergencyContact `json:"emergency_contacts"`
+}
+
+// Validate checks the code of a drug allergy, which is the ATC code of the
+// medicine. Other allergens have no coding and keep the code as given.
+func (r RequestAllergy) Validate() error {
+	if r.Code != "" && r.Category == constants.AllergyDrug {
+		_, err := medcode.ATC(r.Code)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r RequestCondition) Validate() error {
+	if r.Code != "" {
+		_, err := medcode.ICD10(r.Code)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.OnsetAt != nil && r.OnsetAt.After(time.Now()) {
+		return fmt.Errorf("onset_at is in the future")
+	}
+
+	return nil
+}
+
+func (r RequestMedication) Validate() error {
+	if r.Code != "" {
+		_, err := medcode.ATC(r.Code)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.StartedAt != nil && r.EndedAt != nil && r.EndedAt.Before(*r.StartedAt) {
+		return fmt.Errorf("ended_at is before started_at")
+	}
+
+	return nil
+}
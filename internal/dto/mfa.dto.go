@@ -0,0 +1,31 @@
+package dto
+
+type RequestMFACode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type RequestMFALogin struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type ResponseMFAEnroll struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type ResponseMFARecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type ResponseMFAStatus struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type ResponseMFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
@@ -1,8 +1,9 @@
 package dto
 
 import (
-	"errors"
-	"regexp"
+	"monorepo/pkg/nik"
+	"monorepo/pkg/phone"
+	"monorepo/pkg/units"
 	"time"
 )
 
@@ -27,68 +28,141 @@ type ResponseCreateProfile struct {
 }
 
 type ResponseGetProfile struct {
-	ID            string    `json:"id,omitempty"`
-	UserID        string    `json:"user_id,omitempty"`
-	Role          string    `json:"role,omitempty"`
-	MedicalID     string    `json:"medical_id,omitempty"`
-	Name          string    `json:"name,omitempty"`
-	CountryCode   string    `json:"country_code,omitempty"`
-	Phone         string    `json:"phone,omitempty"`
-	NIK           string    `json:"nik,omitempty"`
-	Age           string    `json:"age,omitempty"`
-	DOB           time.Time `json:"dob,omitempty"`
-	Sex           string    `json:"sex,omitempty"`
-	BloodType     string    `json:"blood_type,omitempty"`
-	Weight        float64   `json:"weight,omitempty"`
-	Height        float64   `json:"height,omitempty"`
-	ActivityLevel string    `json:"activity_level,omitempty"`
-	Allergies     string    `json:"allergies,omitempty"`
-	ECRelation    string    `json:"ec_relation,omitempty"`
-	ECName        string    `json:"ec_name,omitempty"`
-	ECCountryCode string    `json:"ec_country_code,omitempty"`
-	ECPhone       string    `json:"ec_phone,omitempty"`
-	PhotoUrl      string    `json:"photo_url,omitempty"`
+	ID            string            `json:"id,omitempty"`
+	UserID        string            `json:"user_id,omitempty"`
+	GuardianID    string            `json:"guardian_id,omitempty"`
+	Relationship  string            `json:"relationship,omitempty"`
+	Role          string            `json:"role,omitempty"`
+	MedicalID     string            `json:"medical_id,omitempty"`
+	Name          string            `json:"name,omitempty"`
+	CountryCode   string            `json:"country_code,omitempty"`
+	Phone         string            `json:"phone,omitempty"`
+	NIK           string            `json:"nik,omitempty"`
+	Age           string            `json:"age,omitempty"`
+	DOB           time.Time         `json:"dob,omitempty"`
+	Sex           string            `json:"sex,omitempty"`
+	RegionCode    string            `json:"region_code,omitempty"`
+	Province      string            `json:"province,omitempty"`
+	BloodType     string            `json:"blood_type,omitempty"`
+	Weight        float64           `json:"weight,omitempty"`
+	Height        float64           `json:"height,omitempty"`
+	ActivityLevel string            `json:"activity_level,omitempty"`
+	Locale        string            `json:"locale,omitempty"`
+	Units         string            `json:"units,omitempty"`
+	PhoneVerified bool              `json:"phone_verified"`
+	PhotoUrl      string            `json:"photo_url,omitempty"`
+	Photos        map[string]string `json:"photos,omitempty"`
+}
+
+// AccountID is the account a profile is managed through: its holder, or the
+// guardian acting for a dependent.
+func (r ResponseGetProfile) AccountID() string {
+	if r.GuardianID != "" {
+		return r.GuardianID
+	}
+
+	return r.UserID
+}
+
+// FromMetric shows the weight and height, kept in kg and cm, in the units the
+// profile prefers.
+func (r *ResponseGetProfile) FromMetric() {
+	r.Weight = units.FromKg(r.Weight, units.WeightUnit(r.Units))
+	r.Height = units.FromCm(r.Height, units.HeightUnit(r.Units))
+}
+
+type ResponseProfilePhoto struct {
+	PhotoUrl string            `json:"photo_url"`
+	Photos   map[string]string `json:"photos"`
+}
+
+type RequestVerifyPhone struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type ResponsePhoneOTP struct {
+	Phone     string    `json:"phone"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type RequestUpdateProfile struct {
-	Age           string    `json:"age,omitempty"`
+	CountryCode   string    `json:"country_code,omitempty"`
+	Phone         string    `json:"phone,omitempty"`
+	NIK           string    `json:"nik,omitempty"`
 	DOB           time.Time `json:"dob,omitempty"`
 	Sex           string    `json:"sex,omitempty" validate:"omitempty,oneof=Male Female"`
-	BloodType     string    `json:"blood_type,omitempty"`
+	BloodType     string    `json:"blood_type,omitempty" validate:"omitempty,oneof=A B AB O A+ A- B+ B- AB+ AB- O+ O-"`
 	Weight        float64   `json:"weight,omitempty"`
 	Height        float64   `json:"height,omitempty"`
 	ActivityLevel string    `json:"activity_level,omitempty"`
-	Allergies     string    `json:"allergies,omitempty"`
-	ECRelation    string    `json:"ec_relation,omitempty" validate:"omitempty,oneof=Wife Husband Mother Father Sister Brother Aunt Uncle Grandmother Grandfather Cousin Friend Spouse Child Other"`
-	ECName        string    `json:"ec_name,omitempty"`
-	ECCountryCode string    `json:"ec_country_code,omitempty"`
-	ECPhone       string    `json:"ec_phone,omitempty"`
+	Locale        string    `json:"locale,omitempty" validate:"omitempty,oneof=id en"`
+	Units         string    `json:"units,omitempty" validate:"omitempty,oneof=metric imperial"`
+	WeightUnit    string    `json:"weight_unit,omitempty" validate:"omitempty,oneof=kg lb"`
+	HeightUnit    string    `json:"height_unit,omitempty" validate:"omitempty,oneof=cm in ft"`
 	PhotoUrl      string    `json:"photo_url,omitempty"`
 }
 
 func (r RequestCreateProfile) Validate() error {
 	// validate phone
-	regex, err := regexp.Compile(`^[1-9]{9,12}$`)
+	_, err := phone.Parse(r.CountryCode, r.Phone)
 	if err != nil {
 		return err
 	}
 
-	phoneMatch := regex.MatchString(r.Phone)
-	if !phoneMatch {
-		err = errors.New("phone has an invalid format")
+	// validate nik (optional)
+	if r.NIK != "" {
+		_, err = nik.Decode(r.NIK, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ToMetric converts the weight and height to kg and cm. Without an explicit
+// unit they are in the units of the request, else the ones the profile
+// prefers.
+func (r *RequestUpdateProfile) ToMetric(preferred string) error {
+	system := preferred
+	if r.Units != "" {
+		system = r.Units
+	}
+
+	weightUnit := r.WeightUnit
+	if weightUnit == "" {
+		weightUnit = units.WeightUnit(system)
+	}
+
+	heightUnit := r.HeightUnit
+	if heightUnit == "" {
+		heightUnit = units.HeightUnit(system)
+	}
+
+	var err error
+	if r.Weight, err = units.ToKg(r.Weight, weightUnit); err != nil {
+		return err
+	}
+
+	if r.Height, err = units.ToCm(r.Height, heightUnit); err != nil {
 		return err
 	}
 
-	// validate nik (optional)
+	r.WeightUnit, r.HeightUnit = units.Kilogram, units.Centimetre
+	return nil
+}
+
+func (r RequestUpdateProfile) Validate() error {
 	if r.NIK != "" {
-		regex, err = regexp.Compile(`^\d{6}([04][1-9]|[1256][0-9]|[37][01])(0[1-9]|1[0-2])\d{2}\d{4}$`)
+		_, err := nik.Decode(r.NIK, time.Now())
 		if err != nil {
 			return err
 		}
+	}
 
-		nikMatch := regex.MatchString(r.NIK)
-		if !nikMatch {
-			err = errors.New("nik has an invalid format")
+	if r.Phone != "" {
+		_, err := phone.Parse(r.CountryCode, r.Phone)
+		if err != nil {
 			return err
 		}
 	}
@@ -1,10 +1,13 @@
 package dto
 
+import "time"
+
 type RequestRegisterUser struct {
 	Provider       string `json:"provider" validate:"required,oneof=email google.com facebook.com apple.com"`
 	Email          string `json:"email" validate:"required,email"`
 	Password       string `json:"password" validate:"required_if=Provider email,min=12,max=128"`
 	RepeatPassword string `json:"repeat_password" validate:"required,eqfield=Password"`
+	EmailVerified  bool   `json:"-"`
 }
 
 type RequestForgotPassword struct {
@@ -16,3 +19,59 @@ type RequestUpdatePassword struct {
 	ResetToken string `json:"reset_token" validate:"required"`
 	Password   string `json:"password" validate:"required_if=Provider email,min=12,max=128"`
 }
+
+type RequestChangePassword struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=12,max=128,nefield=CurrentPassword"`
+	RepeatPassword  string `json:"repeat_password" validate:"required,eqfield=NewPassword"`
+}
+
+type RequestChangeEmail struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RequestConfirmEmailChange struct {
+	UserID      string `json:"user_id" validate:"required"`
+	ChangeToken string `json:"change_token" validate:"required"`
+}
+
+type RequestUpdateUser struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type RequestVerifyEmail struct {
+	UserID      string `json:"user_id" validate:"required"`
+	VerifyToken string `json:"verify_token" validate:"required"`
+}
+
+type RequestAccountDeletion struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type ResponseAccountDeletion struct {
+	ID          string    `json:"id"`
+	Status      string    `json:"status"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ResponseDataExport struct {
+	ID                string     `json:"id"`
+	Status            string     `json:"status"`
+	DownloadUrl       *string    `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+}
+
+type RequestLinkIdentity struct {
+	IDToken string `json:"id_token" validate:"required"`
+}
+
+type ResponseIdentity struct {
+	ID        string    `json:"id"`
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
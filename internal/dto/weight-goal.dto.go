@@ -2,71 +2,192 @@ package dto
 
 import (
 	"errors"
+	"monorepo/pkg/energy"
+	"monorepo/pkg/units"
 	"time"
 )
 
 type CreateWeightGoalRequest struct {
-	StartingWeight float64 `json:"starting_weight,omitempty" validate:"required"`
-	TargetWeight   float64 `json:"target_weight,omitempty" validate:"required"`
-	ActivityLevel  string  `json:"activity_level,omitempty" validate:"required"`
-	Pace           string  `json:"pace,omitempty" validate:"required"`
+	StartingWeight float64  `json:"starting_weight,omitempty" validate:"required"`
+	TargetWeight   float64  `json:"target_weight,omitempty" validate:"required"`
+	ActivityLevel  string   `json:"activity_level,omitempty" validate:"required"`
+	Pace           string   `json:"pace,omitempty" validate:"required"`
+	Formula        string   `json:"formula,omitempty"`
+	BodyFat        *float64 `json:"body_fat,omitempty" validate:"omitempty,gt=0,lt=100"`
+	ClinicID       string   `json:"clinic_id,omitempty"`
+	MacroPreset    string   `json:"macro_preset,omitempty"`
+	AutoCalibrate  *bool    `json:"auto_calibrate,omitempty"`
+	Unit           string   `json:"unit,omitempty" validate:"omitempty,oneof=kg lb"`
 }
 
 type CreateWeightGoalResponse struct {
-	StartingWeight      float64 `json:"starting_weight,omitempty"`
-	StartingDate        string  `json:"starting_date,omitempty"`
-	TargetWeight        float64 `json:"target_weight,omitempty"`
-	TargetDate          string  `json:"target_date,omitempty"`
-	ActivityLevel       string  `json:"activity_level,omitempty"`
-	DailyCaloriesBudget float64 `json:"daily_calories_budget,omitempty"`
-	CaloriesToMaintain  float64 `json:"calories_to_maintain,omitempty"`
-	Flag                string  `json:"flag,omitempty"`
-	Pace                string  `json:"pace,omitempty"`
+	StartingWeight      float64       `json:"starting_weight,omitempty"`
+	StartingDate        string        `json:"starting_date,omitempty"`
+	TargetWeight        float64       `json:"target_weight,omitempty"`
+	TargetDate          string        `json:"target_date,omitempty"`
+	ActivityLevel       string        `json:"activity_level,omitempty"`
+	DailyCaloriesBudget float64       `json:"daily_calories_budget,omitempty"`
+	CaloriesToMaintain  float64       `json:"calories_to_maintain,omitempty"`
+	Flag                string        `json:"flag,omitempty"`
+	Pace                string        `json:"pace,omitempty"`
+	Formula             string        `json:"formula,omitempty"`
+	BodyFat             *float64      `json:"body_fat,omitempty"`
+	MacroPreset         string        `json:"macro_preset,omitempty"`
+	Macros              *MacroTargets `json:"macros,omitempty"`
+	AutoCalibrate       bool          `json:"auto_calibrate"`
+	Status              string        `json:"status,omitempty"`
+	AchievedAt          string        `json:"achieved_at,omitempty"`
+	WeightUnit          string        `json:"weight_unit,omitempty"`
 }
 
 type GetWeightGoalResponse struct {
-	StartingWeight      float64 `json:"starting_weight,omitempty"`
-	StartingDate        string  `json:"starting_date,omitempty"`
-	TargetWeight        float64 `json:"target_weight,omitempty"`
-	TargetDate          string  `json:"target_date,omitempty"`
-	ActivityLevel       string  `json:"activity_level,omitempty"`
-	DailyCaloriesBudget float64 `json:"daily_calories_budget,omitempty"`
-	CaloriesToMaintain  float64 `json:"calories_to_maintain,omitempty"`
-	Flag                string  `json:"flag,omitempty"`
-	Pace                string  `json:"pace,omitempty"`
+	StartingWeight      float64       `json:"starting_weight,omitempty"`
+	StartingDate        string        `json:"starting_date,omitempty"`
+	TargetWeight        float64       `json:"target_weight,omitempty"`
+	TargetDate          string        `json:"target_date,omitempty"`
+	ActivityLevel       string        `json:"activity_level,omitempty"`
+	DailyCaloriesBudget float64       `json:"daily_calories_budget,omitempty"`
+	CaloriesToMaintain  float64       `json:"calories_to_maintain,omitempty"`
+	Flag                string        `json:"flag,omitempty"`
+	Pace                string        `json:"pace,omitempty"`
+	Formula             string        `json:"formula,omitempty"`
+	BodyFat             *float64      `json:"body_fat,omitempty"`
+	MacroPreset         string        `json:"macro_preset,omitempty"`
+	Macros              *MacroTargets `json:"macros,omitempty"`
+	AutoCalibrate       bool          `json:"auto_calibrate"`
+	Status              string        `json:"status,omitempty"`
+	AchievedAt          string        `json:"achieved_at,omitempty"`
+	WeightUnit          string        `json:"weight_unit,omitempty"`
 }
 
 type UpdateWeightGoalRequest struct {
-	CurrentWeight  float64 `json:"current_weight,omitempty"`
-	StartingWeight float64 `json:"starting_weight,omitempty"`
-	StartingDate   string  `json:"starting_date,omitempty"`
-	TargetWeight   float64 `json:"target_weight,omitempty"`
-	ActivityLevel  string  `json:"activity_level,omitempty"`
-	Pace           string  `json:"pace,omitempty"`
+	CurrentWeight  float64  `json:"current_weight,omitempty"`
+	StartingWeight float64  `json:"starting_weight,omitempty"`
+	StartingDate   string   `json:"starting_date,omitempty"`
+	TargetWeight   float64  `json:"target_weight,omitempty"`
+	ActivityLevel  string   `json:"activity_level,omitempty"`
+	Pace           string   `json:"pace,omitempty"`
+	Formula        string   `json:"formula,omitempty"`
+	BodyFat        *float64 `json:"body_fat,omitempty" validate:"omitempty,gt=0,lt=100"`
+	ClinicID       string   `json:"clinic_id,omitempty"`
+	MacroPreset    string   `json:"macro_preset,omitempty"`
+	AutoCalibrate  *bool    `json:"auto_calibrate,omitempty"`
+	Unit           string   `json:"unit,omitempty" validate:"omitempty,oneof=kg lb"`
 }
 
 type WeightGoalPace struct {
-	Pace                string  `json:"pace,omitempty"`
-	DailyCaloriesBudget float64 `json:"daily_calories_budget,omitempty"`
-	TargetDate          string  `json:"target_date,omitempty"`
+	Pace                string        `json:"pace,omitempty"`
+	DailyCaloriesBudget float64       `json:"daily_calories_budget,omitempty"`
+	TargetDate          string        `json:"target_date,omitempty"`
+	Macros              *MacroTargets `json:"macros,omitempty"`
 }
+
+// MacroTargets are daily gram targets derived from the daily calories budget.
+type MacroTargets struct {
+	Protein      float64 `json:"protein"`
+	Carbohydrate float64 `json:"carbohydrate"`
+	Fat          float64 `json:"fat"`
+	Fibre        float64 `json:"fibre"`
+}
+
+// WeightGoalFormula is the outcome of one energy expenditure formula, the
+// simulation lists every formula that can be applied side by side.
+type WeightGoalFormula struct {
+	Formula            string           `json:"formula"`
+	BMR                float64          `json:"bmr"`
+	CaloriesToMaintain float64          `json:"calories_to_maintain"`
+	Pacing             []WeightGoalPace `json:"pacing"`
+}
+
 type SimulationWeightGoalRequest struct {
-	StartingWeight float64 `json:"starting_weight,omitempty" validate:"required"`
-	TargetWeight   float64 `json:"target_weight,omitempty" validate:"required"`
-	ActivityLevel  string  `json:"activity_level,omitempty" validate:"required"`
+	StartingWeight float64  `json:"starting_weight,omitempty" validate:"required"`
+	TargetWeight   float64  `json:"target_weight,omitempty" validate:"required"`
+	ActivityLevel  string   `json:"activity_level,omitempty" validate:"required"`
+	Formula        string   `json:"formula,omitempty"`
+	BodyFat        *float64 `json:"body_fat,omitempty" validate:"omitempty,gt=0,lt=100"`
+	ClinicID       string   `json:"clinic_id,omitempty"`
+	MacroPreset    string   `json:"macro_preset,omitempty"`
+	Unit           string   `json:"unit,omitempty" validate:"omitempty,oneof=kg lb"`
 }
 
 type SimulationWeightGoalResponse struct {
-	StartingWeight     float64          `json:"starting_weight,omitempty"`
-	StartingDate       string           `json:"starting_date,omitempty"`
-	TargetWeight       float64          `json:"target_weight,omitempty"`
-	ActivityLevel      string           `json:"activity_level,omitempty"`
-	CaloriesToMaintain float64          `json:"calories_to_maintain,omitempty"`
-	Flag               string           `json:"flag,omitempty"`
-	Pacing             []WeightGoalPace `json:"pacing,omitempty"`
+	StartingWeight     float64             `json:"starting_weight,omitempty"`
+	StartingDate       string              `json:"starting_date,omitempty"`
+	TargetWeight       float64             `json:"target_weight,omitempty"`
+	ActivityLevel      string              `json:"activity_level,omitempty"`
+	CaloriesToMaintain float64             `json:"calories_to_maintain,omitempty"`
+	Flag               string              `json:"flag,omitempty"`
+	Formula            string              `json:"formula,omitempty"`
+	MacroPreset        string              `json:"macro_preset,omitempty"`
+	Pacing             []WeightGoalPace    `json:"pacing,omitempty"`
+	Formulas           []WeightGoalFormula `json:"formulas,omitempty"`
+	WeightUnit         string              `json:"weight_unit,omitempty"`
+}
+
+// WeightGoalSummary is the outcome of a past goal. Achieved tells whether the
+// target weight was reached before the goal ended, however it ended.
+type WeightGoalSummary struct {
+	ID             string  `json:"id"`
+	Status         string  `json:"status"`
+	Flag           string  `json:"flag"`
+	StartingWeight float64 `json:"starting_weight"`
+	StartingDate   string  `json:"starting_date"`
+	TargetWeight   float64 `json:"target_weight"`
+	TargetDate     string  `json:"target_date"`
+	EndWeight      float64 `json:"end_weight,omitempty"`
+	EndedAt        string  `json:"ended_at"`
+	WeightChange   float64 `json:"weight_change"`
+	Days           int     `json:"days"`
+	Achieved       bool    `json:"achieved"`
+	AchievedAt     string  `json:"achieved_at,omitempty"`
+	WeightUnit     string  `json:"weight_unit,omitempty"`
+}
+
+// MaintenanceEstimateResponse estimates the calories to maintain from the
+// energy balance of the last weeks. The intake is the mean of the days food
+// was logged, else the goal's budget is taken as eaten. Exercise are the mean
+// daily calories of logged workouts, they are left out of the estimate since
+// the food diary adds them back as earned.
+type MaintenanceEstimateResponse struct {
+	From                         string  `json:"from"`
+	To                           string  `json:"to"`
+	Days                         int     `json:"days"`
+	Weighings                    int     `json:"weighings"`
+	IntakeDays                   int     `json:"intake_days"`
+	Intake                       float64 `json:"intake"`
+	IntakeSource                 string  `json:"intake_source"`
+	Exercise                     float64 `json:"exercise"`
+	WeightChange                 float64 `json:"weight_change"`
+	Confidence                   string  `json:"confidence"`
+	CaloriesToMaintain           float64 `json:"calories_to_maintain"`
+	CurrentCaloriesToMaintain    float64 `json:"current_calories_to_maintain"`
+	CurrentDailyCaloriesBudget   float64 `json:"current_daily_calories_budget"`
+	SuggestedDailyCaloriesBudget float64 `json:"suggested_daily_calories_budget"`
+	AutoCalibrate                bool    `json:"auto_calibrate"`
+	WeightUnit                   string  `json:"weight_unit,omitempty"`
+}
+
+type CalorieAdjustmentResponse struct {
+	ID                          string  `json:"id"`
+	Date                        string  `json:"date"`
+	Source                      string  `json:"source"`
+	Confidence                  string  `json:"confidence"`
+	Days                        int     `json:"days"`
+	Intake                      float64 `json:"intake"`
+	IntakeSource                string  `json:"intake_source"`
+	WeightChange                float64 `json:"weight_change"`
+	PreviousCaloriesToMaintain  float64 `json:"previous_calories_to_maintain"`
+	CaloriesToMaintain          float64 `json:"calories_to_maintain"`
+	PreviousDailyCaloriesBudget float64 `json:"previous_daily_calories_budget"`
+	DailyCaloriesBudget         float64 `json:"daily_calories_budget"`
+	WeightUnit                  string  `json:"weight_unit,omitempty"`
 }
 
 func (r CreateWeightGoalRequest) Validate() error {
+	if _, err := energy.Get(r.Formula); err != nil {
+		return err
+	}
+
 	if r.TargetWeight == r.StartingWeight {
 		return errors.New("target weight must be different from starting weight")
 	}
@@ -79,6 +200,10 @@ func (r CreateWeightGoalRequest) Validate() error {
 }
 
 func (r UpdateWeightGoalRequest) Validate() error {
+	if _, err := energy.Get(r.Formula); err != nil {
+		return err
+	}
+
 	now := time.Now()
 	startTime, _ := time.Parse("2006-01-02", r.StartingDate)
 
@@ -93,6 +218,10 @@ func (r UpdateWeightGoalRequest) Validate() error {
 }
 
 func (r SimulationWeightGoalRequest) Validate() error {
+	if _, err := energy.Get(r.Formula); err != nil {
+		return err
+	}
+
 	if r.TargetWeight == r.StartingWeight {
 		return errors.New("target weight must be different from starting weight")
 	}
@@ -103,3 +232,72 @@ func (r SimulationWeightGoalRequest) Validate() error {
 
 	return nil
 }
+
+// ToMetric converts the weights to kg, see weightsToKg.
+func (r *CreateWeightGoalRequest) ToMetric(preferred string) error {
+	return weightsToKg(&r.Unit, preferred, &r.StartingWeight, &r.TargetWeight)
+}
+
+func (r *UpdateWeightGoalRequest) ToMetric(preferred string) error {
+	return weightsToKg(&r.Unit, preferred, &r.CurrentWeight, &r.StartingWeight, &r.TargetWeight)
+}
+
+func (r *SimulationWeightGoalRequest) ToMetric(preferred string) error {
+	return weightsToKg(&r.Unit, preferred, &r.StartingWeight, &r.TargetWeight)
+}
+
+// FromMetric shows the weights, kept in kg, in the units of system.
+func (r *CreateWeightGoalResponse) FromMetric(system string) {
+	r.WeightUnit = weightsFromKg(system, &r.StartingWeight, &r.TargetWeight)
+}
+
+func (r *GetWeightGoalResponse) FromMetric(system string) {
+	r.WeightUnit = weightsFromKg(system, &r.StartingWeight, &r.TargetWeight)
+}
+
+func (r *SimulationWeightGoalResponse) FromMetric(system string) {
+	r.WeightUnit = weightsFromKg(system, &r.StartingWeight, &r.TargetWeight)
+}
+
+func (r *WeightGoalSummary) FromMetric(system string) {
+	r.WeightUnit = weightsFromKg(system, &r.StartingWeight, &r.TargetWeight, &r.EndWeight, &r.WeightChange)
+}
+
+func (r *MaintenanceEstimateResponse) FromMetric(system string) {
+	r.WeightUnit = weightsFromKg(system, &r.WeightChange)
+}
+
+func (r *CalorieAdjustmentResponse) FromMetric(system string) {
+	r.WeightUnit = weightsFromKg(system, &r.WeightChange)
+}
+
+// weightsToKg converts weights in unit, else in the units of the preferred
+// system, to kg. Unit is then kg so converting again changes nothing.
+func weightsToKg(unit *string, preferred string, weights ...*float64) error {
+	from := *unit
+	if from == "" {
+		from = units.WeightUnit(preferred)
+	}
+
+	for _, w := range weights {
+		kg, err := units.ToKg(*w, from)
+		if err != nil {
+			return err
+		}
+		*w = kg
+	}
+
+	*unit = units.Kilogram
+	return nil
+}
+
+// weightsFromKg converts weights in kg, or differences of them, to the units
+// of system and returns the unit.
+func weightsFromKg(system string, weights ...*float64) string {
+	unit := units.WeightUnit(system)
+	for _, w := range weights {
+		*w = units.FromKg(*w, unit)
+	}
+
+	return unit
+}
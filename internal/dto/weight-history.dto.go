@@ -2,17 +2,60 @@ package dto
 
 import (
 	"errors"
+	"monorepo/pkg/units"
 	"time"
 )
 
 type CreateWeightHistoryRequest struct {
 	Weight float64 `json:"weight" validate:"required"`
 	Date   string  `json:"date"`
+	Unit   string  `json:"unit,omitempty" validate:"omitempty,oneof=kg lb"`
 }
 
 type WeightHistoryResponse struct {
-	Weight float64 `json:"weight"`
+	Weight     float64 `json:"weight"`
+	Date       string  `json:"date"`
+	WeightUnit string  `json:"weight_unit,omitempty"`
+}
+
+// WeightImportRequest are the form fields of an import. Format is detected
+// when not given, Unit is the one of files that do not tell, the unit of the
+// profile when not given.
+type WeightImportRequest struct {
+	Format  string `validate:"omitempty,oneof=csv apple_health google_fit fitbit"`
+	Unit    string `validate:"omitempty,oneof=kg lb"`
+	Preview bool
+}
+
+// WeightImportDay is the weighing imported for a day, the latest of the day
+// in the file. Row is where it is in the file.
+type WeightImportDay struct {
+	Row    int     `json:"row"`
 	Date   string  `json:"date"`
+	Weight float64 `json:"weight"`
+	Status string  `json:"status"` // new | existing
+}
+
+type WeightImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// WeightImportResponse reports an import, or what it would do for a preview.
+// Rows are the weighings of the file, days already in the history are kept
+// as they are and only the new ones are imported.
+type WeightImportResponse struct {
+	Format     string              `json:"format"`
+	Preview    bool                `json:"preview"`
+	Rows       int                 `json:"rows"`
+	Invalid    int                 `json:"invalid"`
+	Days       int                 `json:"days"`
+	New        int                 `json:"new"`
+	Existing   int                 `json:"existing"`
+	Imported   int                 `json:"imported"`
+	Entries    []WeightImportDay   `json:"entries"`
+	Errors     []WeightImportError `json:"errors"`
+	WeightUnit string              `json:"weight_unit,omitempty"`
 }
 
 type FilterGetWeightHistory struct {
@@ -35,3 +78,51 @@ func (r CreateWeightHistoryRequest) Validate() error {
 
 	return nil
 }
+
+type WeightTrendPoint struct {
+	Date   string  `json:"date"`
+	Weight float64 `json:"weight"`
+	Trend  float64 `json:"trend"`
+}
+
+// WeightTrendResponse smooths the weighings of the last days. Weekly rates are
+// in kg, negative when losing. The goal fields are left out without a gain or
+// loss goal, the projected date also when the trend moves away from the target.
+type WeightTrendResponse struct {
+	Points            []WeightTrendPoint `json:"points"`
+	Trend             float64            `json:"trend"`
+	WeeklyRate        float64            `json:"weekly_rate"`
+	TargetWeight      float64            `json:"target_weight,omitempty"`
+	TargetDate        string             `json:"target_date,omitempty"`
+	ProjectedDate     string             `json:"projected_date,omitempty"`
+	PlannedWeight     float64            `json:"planned_weight,omitempty"`
+	PlannedWeeklyRate float64            `json:"planned_weekly_rate,omitempty"`
+	Pace              string             `json:"pace,omitempty"` // ahead | behind
+	WeightUnit        string             `json:"weight_unit,omitempty"`
+}
+
+// ToMetric converts the weight to kg, see weightsToKg.
+func (r *CreateWeightHistoryRequest) ToMetric(preferred string) error {
+	return weightsToKg(&r.Unit, preferred, &r.Weight)
+}
+
+// FromMetric shows the weight, kept in kg, in the units of system.
+func (r *WeightHistoryResponse) FromMetric(system string) {
+	r.WeightUnit = weightsFromKg(system, &r.Weight)
+}
+
+// FromMetric shows the weights and weekly rates in the units of system.
+func (r *WeightTrendResponse) FromMetric(system string) {
+	r.WeightUnit = weightsFromKg(system, &r.Trend, &r.WeeklyRate, &r.TargetWeight, &r.PlannedWeight, &r.PlannedWeeklyRate)
+	for i := range r.Points {
+		weightsFromKg(system, &r.Points[i].Weight, &r.Points[i].Trend)
+	}
+}
+
+// FromMetric shows the weights of the days in the units of system.
+func (r *WeightImportResponse) FromMetric(system string) {
+	r.WeightUnit = units.WeightUnit(system)
+	for i := range r.Entries {
+		weightsFromKg(system, &r.Entries[i].Weight)
+	}
+}
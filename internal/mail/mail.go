@@ -0,0 +1,41 @@
+// Package mail delivers rendered emails through a pluggable transport.
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monorepo/internal/config"
+)
+
+const (
+	TransportSMTP   = "smtp"
+	TransportMemory = "memory"
+)
+
+var ErrUnknownTransport = errors.New("unknown mail transport")
+
+type Message struct {
+	From    string
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type Transport interface {
+	// Send delivers a single message, every call is independent of the others.
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewTransport creates the transport selected by MAIL_TRANSPORT, SMTP when unset.
+func NewTransport(env *config.Environment) (Transport, error) {
+	switch env.MailTransport {
+	case "", TransportSMTP:
+		return NewSMTP(env.SMTPHost, env.SMTPPort, env.SMTPAuthEmail, env.SMTPAuthPassword), nil
+	case TransportMemory:
+		return NewMemory(), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownTransport, env.MailTransport)
+}
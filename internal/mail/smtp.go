@@ -0,0 +1,32 @@
+package mail
+
+import (
+	"context"
+
+	"gopkg.in/gomail.v2"
+)
+
+// SMTP sends through an smtp server. A local stand-in such as mailhog works
+// by pointing SMTP_HOST at it.
+type SMTP struct {
+	dialer *gomail.Dialer
+}
+
+func NewSMTP(host string, port int, username, password string) *SMTP {
+	return &SMTP{dialer: gomail.NewDialer(host, port, username, password)}
+}
+
+func (s *SMTP) Send(ctx context.Context, msg *Message) error {
+	// a fresh message per send, a shared one leaks headers between concurrent requests
+	m := gomail.NewMessage()
+	m.SetHeader("From", msg.From)
+	m.SetHeader("To", msg.To)
+	m.SetHeader("Subject", msg.Subject)
+	m.SetBody("text/html", msg.HTML)
+	if msg.Text != "" {
+		m.SetBody("text/plain", msg.Text)
+		m.AddAlternative("text/html", msg.HTML)
+	}
+
+	return s.dialer.DialAndSend(m)
+}
@@ -0,0 +1,125 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSMTP accepts every message and records the raw data of each.
+func fakeSMTP(t *testing.T) (host string, port int, received func() []string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var mu sync.Mutex
+	var data []string
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				fmt.Fprint(conn, "220 localhost\r\n")
+
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+
+					switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+					case strings.HasPrefix(cmd, "DATA"):
+						fmt.Fprint(conn, "354 go ahead\r\n")
+						var body strings.Builder
+						for {
+							l, err := r.ReadString('\n')
+							if err != nil || l == ".\r\n" {
+								break
+							}
+							body.WriteString(l)
+						}
+						mu.Lock()
+						data = append(data, body.String())
+						mu.Unlock()
+						fmt.Fprint(conn, "250 ok\r\n")
+					case strings.HasPrefix(cmd, "QUIT"):
+						fmt.Fprint(conn, "221 bye\r\n")
+						return
+					default:
+						fmt.Fprint(conn, "250 ok\r\n")
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), data...)
+	}
+}
+
+func TestSMTPConcurrentSends(t *testing.T) {
+	host, port, received := fakeSMTP(t)
+	transport := NewSMTP(host, port, "", "")
+
+	const n = 8
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := transport.Send(context.Background(), &Message{
+				From:    "noreply@example.com",
+				To:      "user" + strconv.Itoa(i) + "@example.com",
+				Subject: "Subject " + strconv.Itoa(i),
+				HTML:    "<p>hi</p>",
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	messages := received()
+	if len(messages) != n {
+		t.Fatalf("received %d messages, want %d", len(messages), n)
+	}
+
+	for _, raw := range messages {
+		if strings.Contains(raw, "\r\nCc:") {
+			t.Errorf("message has a cc header:\n%s", raw)
+		}
+
+		// every message keeps its own recipient and subject
+		var to, subject string
+		for _, line := range strings.Split(raw, "\r\n") {
+			if v, ok := strings.CutPrefix(line, "To: "); ok {
+				to = v
+			}
+			if v, ok := strings.CutPrefix(line, "Subject: "); ok {
+				subject = v
+			}
+		}
+		i := strings.TrimSuffix(strings.TrimPrefix(to, "user"), "@example.com")
+		if subject != "Subject "+i {
+			t.Errorf("to %q got subject %q", to, subject)
+		}
+	}
+}
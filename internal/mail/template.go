@@ -0,0 +1,127 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+const (
+	LocaleID      = "id"
+	LocaleEN      = "en"
+	DefaultLocale = LocaleID
+)
+
+const (
+	TemplateResetPassword           = "reset-password"
+	TemplateVerifyEmail             = "verify-email"
+	TemplateChangeEmail             = "change-email"
+	TemplateAppointmentConfirmation = "appointment-confirmation"
+	TemplateGoalAchieved            = "goal-achieved"
+)
+
+var (
+	Locales   = []string{LocaleID, LocaleEN}
+	Templates = []string{
+		TemplateResetPassword,
+		TemplateVerifyEmail,
+		TemplateChangeEmail,
+		TemplateAppointmentConfirmation,
+		TemplateGoalAchieved,
+	}
+)
+
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// Rendered is a template ready to be sent, Text is the plain-text alternative of HTML.
+type Rendered struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Registry holds every template in every locale, parsed once from the embedded files.
+type Registry struct {
+	templates map[string]*template.Template
+}
+
+func NewRegistry() (*Registry, error) {
+	registry := &Registry{templates: map[string]*template.Template{}}
+	for _, locale := range Locales {
+		for _, name := range Templates {
+			t, err := template.New("layout.html").
+				Funcs(templateFuncs(locale)).
+				ParseFS(templateFS,
+					"templates/layout.html",
+					fmt.Sprintf("templates/%s/common.html", locale),
+					fmt.Sprintf("templates/%s/%s.html", locale, name),
+				)
+			if err != nil {
+				return nil, fmt.Errorf("parse email template %s/%s; %w", locale, name, err)
+			}
+
+			registry.templates[locale+"/"+name] = t
+		}
+	}
+
+	return registry, nil
+}
+
+func templateFuncs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"lang": func() string { return locale },
+		"button": func(url, label string) map[string]string {
+			return map[string]string{"URL": url, "Label": label}
+		},
+	}
+}
+
+// NormalizeLocale maps a profile locale such as "en-US" to a supported locale,
+// DefaultLocale when it is empty or unsupported.
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(locale)
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+
+	for _, l := range Locales {
+		if l == locale {
+			return l
+		}
+	}
+
+	return DefaultLocale
+}
+
+// Render executes the named template in the given locale.
+func (registry *Registry) Render(name, locale string, data any) (*Rendered, error) {
+	t, ok := registry.templates[NormalizeLocale(locale)+"/"+name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+
+	subject := new(bytes.Buffer)
+	err := t.ExecuteTemplate(subject, "subject", data)
+	if err != nil {
+		return nil, err
+	}
+
+	body := new(bytes.Buffer)
+	err = t.Execute(body, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rendered{
+		Subject: html.UnescapeString(strings.TrimSpace(subject.String())),
+		HTML:    body.String(),
+		Text:    HTMLToText(body.String()),
+	}, nil
+}
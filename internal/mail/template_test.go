@@ -0,0 +1,80 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+)
+
+type templateData struct {
+	UserName         string
+	ResetPasswordUrl string
+	VerifyEmailUrl   string
+	ChangeEmailUrl   string
+	NewEmail         string
+	CsMail           string
+	ClinicName       string
+	AppointmentAt    string
+	GoalWeight       string
+}
+
+func TestRegistryRendersEveryTemplate(t *testing.T) {
+	registry, err := NewRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := templateData{
+		UserName:         `<b>Budi</b>`,
+		ResetPasswordUrl: "https://example.com/reset?uid=1&reset-token=abc",
+		VerifyEmailUrl:   "https://example.com/verify",
+		ChangeEmailUrl:   "https://example.com/change",
+		NewEmail:         "new@example.com",
+		CsMail:           "cs@example.com",
+		ClinicName:       "Clinic",
+		AppointmentAt:    "1 Jan 2025 10:00",
+		GoalWeight:       "60",
+	}
+
+	for _, locale := range Locales {
+		for _, name := range Templates {
+			res, err := registry.Render(name, locale, data)
+			if err != nil {
+				t.Fatalf("%s/%s: %s", locale, name, err)
+			}
+
+			if res.Subject == "" || res.Text == "" {
+				t.Errorf("%s/%s: empty subject or text", locale, name)
+			}
+
+			if strings.Contains(res.HTML, "<b>Budi</b>") {
+				t.Errorf("%s/%s: user input is not escaped", locale, name)
+			}
+
+			if !strings.Contains(res.HTML, `lang="`+locale+`"`) {
+				t.Errorf("%s/%s: missing lang attribute", locale, name)
+			}
+
+			if strings.Contains(res.Text, "<p") {
+				t.Errorf("%s/%s: text part contains markup:\n%s", locale, name, res.Text)
+			}
+		}
+	}
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	cases := map[string]string{"": DefaultLocale, "en": LocaleEN, "en-US": LocaleEN, "ID_id": LocaleID, "fr": DefaultLocale}
+	for in, want := range cases {
+		if got := NormalizeLocale(in); got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	got := HTMLToText(`<html><head><style>p{}</style></head><body><span class="preheader">hidden</span>` +
+		`<p>Hello,&nbsp;Budi</p><p>Click <a href="https://x.test/a?b=1&amp;c=2">here</a>.<br>Or <a href="https://x.test">https://x.test</a></p></body></html>`)
+	want := "Hello, Budi\n\nClick here (https://x.test/a?b=1&c=2).\nOr https://x.test"
+	if got != want {
+		t.Errorf("HTMLToText() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,112 @@
+package mail
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	textSpaces     = regexp.MustCompile(`[ \t\r\n]+`)
+	textBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// textSkipped elements carry nothing a reader of the plain-text part needs.
+var textSkipped = map[string]bool{"head": true, "style": true, "script": true, "title": true}
+
+// textBreaks elements end a line, paragraphs also leave a blank line.
+var textBreaks = map[string]string{"p": "\n\n", "div": "\n", "tr": "\n", "table": "\n", "li": "\n", "h1": "\n\n", "h2": "\n\n", "h3": "\n\n"}
+
+// HTMLToText builds the plain-text alternative of an email. Links keep their
+// target next to the label so they stay usable.
+func HTMLToText(s string) string {
+	var out strings.Builder
+	var skip []string
+	var hrefs []string
+	var label strings.Builder
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		token := z.Token()
+		if len(skip) > 0 {
+			switch tt {
+			case html.StartTagToken:
+				if token.Data == skip[len(skip)-1] {
+					skip = append(skip, token.Data)
+				}
+			case html.EndTagToken:
+				if token.Data == skip[len(skip)-1] {
+					skip = skip[:len(skip)-1]
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.StartTagToken:
+			if textSkipped[token.Data] || hasClass(token, "preheader") {
+				skip = append(skip, token.Data)
+				continue
+			}
+
+			if token.Data == "a" {
+				hrefs = append(hrefs, attr(token, "href"))
+				label.Reset()
+			}
+
+			if token.Data == "br" {
+				out.WriteString("\n")
+			}
+		case html.SelfClosingTagToken:
+			if token.Data == "br" {
+				out.WriteString("\n")
+			}
+		case html.EndTagToken:
+			if token.Data == "a" && len(hrefs) > 0 {
+				href := strings.TrimPrefix(hrefs[len(hrefs)-1], "mailto:")
+				hrefs = hrefs[:len(hrefs)-1]
+				if href != "" && strings.TrimSpace(label.String()) != href {
+					out.WriteString(" (" + href + ")")
+				}
+			}
+			out.WriteString(textBreaks[token.Data])
+		case html.TextToken:
+			text := textSpaces.ReplaceAllString(token.Data, " ")
+			out.WriteString(text)
+			if len(hrefs) > 0 {
+				label.WriteString(text)
+			}
+		}
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(textSpaces.ReplaceAllString(line, " "))
+	}
+
+	return strings.TrimSpace(textBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(token html.Token, class string) bool {
+	for _, c := range strings.Fields(attr(token, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
@@ -204,8 +204,11 @@ func (repo *Repository[Model, ID]) Raw(ctx context.Context, statement string, ar
 	tx := repo.db.MustBegin().Tx
 	defer tx.Rollback()
 
-	res, err := tx.ExecContext(ctx, statement)
-	tx.Commit()
+	res, err := tx.ExecContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrExecutingStatement, err)
+	}
 
-	return res, err
+	tx.Commit()
+	return res, nil
 }
@@ -6,11 +6,37 @@ type tables struct {
 	Message       string
 	UserMessage   string
 	ResetPassword string
+	UserSession   string
+	EmailChange   string
+	EmailVerify   string
+	MFA           string
+	RecoveryCode  string
+	MFAChallenge  string
+	Deletion      string
+	DeletionLog   string
+	DeletionCert  string
+	DataExport    string
+	EmailOutbox   string
+	PhoneVerify   string
+	Guardian      string
+	Handover      string
+	Allergy       string
+	Condition     string
+	Medication    string
+	Contact       string
+	AdminAudit    string
+	Identity      string
 	Clinic        string
 	Location      string
+	ClinicStaff   string
 	Event         string
 	WeightGoal    string
 	WeightHistory string
+	Food          string
+	FoodDiary     string
+	ExerciseLog   string
+	CalorieAdjust string
+	BodyMeasure   string
 }
 
 type views struct {
@@ -24,11 +50,37 @@ var (
 		Message:       "message",
 		UserMessage:   "user_message",
 		ResetPassword: "reset_password",
+		UserSession:   "user_session",
+		EmailChange:   "email_change",
+		EmailVerify:   "email_verification",
+		MFA:           "user_mfa",
+		RecoveryCode:  "user_recovery_code",
+		MFAChallenge:  "mfa_challenge",
+		Deletion:      "account_deletion",
+		DeletionLog:   "account_deletion_log",
+		DeletionCert:  "deletion_certificate",
+		DataExport:    "data_export",
+		EmailOutbox:   "email_outbox",
+		PhoneVerify:   "phone_verification",
+		Guardian:      "profile_guardian",
+		Handover:      "profile_handover",
+		Allergy:       "profile_allergy",
+		Condition:     "profile_condition",
+		Medication:    "profile_medication",
+		Contact:       "emergency_contact",
+		AdminAudit:    "admin_audit_log",
+		Identity:      "user_identities",
 		Clinic:        "clinic",
 		Location:      "location",
+		ClinicStaff:   "clinic_staff",
 		Event:         "event",
 		WeightGoal:    "weight_goal",
 		WeightHistory: "weight_history",
+		Food:          "food",
+		FoodDiary:     "food_diary",
+		ExerciseLog:   "exercise_log",
+		CalorieAdjust: "calorie_adjustment",
+		BodyMeasure:   "body_measurement",
 	}
 	Views = views{
 		UserMessage: "view_user_message",
@@ -0,0 +1,33 @@
+// Package role restricts routes to the roles of the caller. The role is the
+// default role claim of the bearer token, see dto.FirebaseClaims.
+package role
+
+import (
+	"encoding/json"
+	"monorepo/internal/dto"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/oauth"
+)
+
+// claim holds the role in the token claims.
+const claim = "x-hasura-default-role"
+
+// Authorizer only lets users with one of the roles through. It must run after
+// the oauth authorizer, which puts the claims in the request context.
+func Authorizer(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, _ := r.Context().Value(oauth.ClaimsContext).(map[string]string)
+			if !slices.Contains(roles, claims[claim]) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(dto.Object[any]{Error: "insufficient role"})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
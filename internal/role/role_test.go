@@ -0,0 +1,36 @@
+package role
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/oauth"
+)
+
+func TestAuthorizer(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims any
+		want   int
+	}{
+		{"Allowed role", map[string]string{claim: "admin"}, http.StatusOK},
+		{"Other role", map[string]string{claim: "user"}, http.StatusForbidden},
+		{"No claims", nil, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Authorizer("staff", "admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(context.WithValue(r.Context(), oauth.ClaimsContext, tt.claims))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("Authorizer() status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package sms
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Message struct {
+	To   string
+	Body string
+}
+
+// Fake logs messages instead of sending them and keeps them in memory, for
+// tests and local development.
+type Fake struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (f *Fake) Send(ctx context.Context, to, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	logrus.Infof("sms to %s: %s", to, body)
+	f.messages = append(f.messages, Message{To: to, Body: body})
+	return nil
+}
+
+// Messages returns a copy of every message sent so far.
+func (f *Fake) Messages() []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Message(nil), f.messages...)
+}
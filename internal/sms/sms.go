@@ -0,0 +1,33 @@
+// Package sms sends text messages through a pluggable provider.
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monorepo/internal/config"
+)
+
+const (
+	ProviderTwilio = "twilio"
+	ProviderFake   = "fake"
+)
+
+var ErrUnknownProvider = errors.New("unknown sms provider")
+
+type Sender interface {
+	// Send delivers body to an E.164 phone number.
+	Send(ctx context.Context, to, body string) error
+}
+
+// NewSender creates the provider selected by SMS_PROVIDER, Twilio when unset.
+func NewSender(env *config.Environment) (Sender, error) {
+	switch env.SMSProvider {
+	case "", ProviderTwilio:
+		return NewTwilio(env.TwilioAccountSID, env.TwilioAuthToken, env.SMSFrom), nil
+	case ProviderFake:
+		return NewFake(), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, env.SMSProvider)
+}
@@ -0,0 +1,57 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const twilioBaseURL = "https://api.twilio.com/2010-04-01"
+
+type Twilio struct {
+	accountSID string
+	authToken  string
+	from       string
+	baseURL    string
+	client     *http.Client
+}
+
+func NewTwilio(accountSID, authToken, from string) *Twilio {
+	return &Twilio{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		baseURL:    twilioBaseURL,
+		client:     &http.Client{Timeout: time.Second * 15},
+	}
+}
+
+func (t *Twilio) Send(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "From": {t.from}, "Body": {body}}
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", t.baseURL, t.accountSID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("twilio: %s; %s", res.Status, msg)
+	}
+
+	return nil
+}
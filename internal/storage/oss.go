@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+type OSS struct {
+	endpoint string
+	bucket   *oss.Bucket
+}
+
+func NewOSS(endpoint, bucketName, keyID, keySecret string) (*OSS, error) {
+	client, err := oss.New(endpoint, keyID, keySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := client.Bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OSS{endpoint: endpoint, bucket: bucket}, nil
+}
+
+func (s *OSS) Put(ctx context.Context, key string, content io.Reader, contentType string, private bool) error {
+	acl := oss.ACLPublicRead
+	if private {
+		acl = oss.ACLPrivate
+	}
+
+	return s.bucket.PutObject(key, content, oss.ContentType(contentType), oss.ObjectACL(acl))
+}
+
+func (s *OSS) Delete(ctx context.Context, key string) error {
+	return s.bucket.DeleteObject(key)
+}
+
+func (s *OSS) URL(key string) string {
+	return fmt.Sprintf("https://%s.%s/%s", s.bucket.BucketName, s.endpoint, key)
+}
+
+func (s *OSS) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return s.bucket.SignURL(key, oss.HTTPGet, int64(ttl/time.Second))
+}
@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3AmzDateLayout   = "20060102T150405Z"
+	s3ScopeDateLayout = "20060102"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// S3 talks to any S3-compatible endpoint using path-style addressing and signature v4.
+type S3 struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	keyID     string
+	keySecret string
+	client    *http.Client
+}
+
+func NewS3(endpoint, region, bucket, keyID, keySecret string) (*S3, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3{
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		keyID:     keyID,
+		keySecret: keySecret,
+		client:    &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket + "/" + key
+	return &u
+}
+
+func (s *S3) Put(ctx context.Context, key string, content io.Reader, contentType string, private bool) error {
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	acl := "public-read"
+	if private {
+		acl = "private"
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("x-amz-acl", acl)
+
+	sum := sha256.Sum256(body)
+	return s.do(req, hex.EncodeToString(sum[:]))
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(nil)
+	return s.do(req, hex.EncodeToString(sum[:]))
+}
+
+func (s *S3) URL(key string) string {
+	return s.objectURL(key).String()
+}
+
+func (s *S3) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return s.presign(http.MethodGet, s.objectURL(key), time.Now(), ttl), nil
+}
+
+func (s *S3) do(req *http.Request, payloadHash string) error {
+	s.sign(req, payloadHash, time.Now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound && req.Method == http.MethodDelete {
+		return nil
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("s3 %s %s: %s; %s", req.Method, req.URL.Path, res.Status, msg)
+	}
+
+	return nil
+}
+
+// sign adds the signature v4 authorization header to req.
+func (s *S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("x-amz-date", now.Format(s3AmzDateLayout))
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.signature(now, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.keyID, scope, signedHeaders, signature))
+}
+
+// presign returns u with a query string signature valid for ttl.
+func (s *S3) presign(method string, u *url.URL, now time.Time, ttl time.Duration) string {
+	now = now.UTC()
+	scope := s.scope(now)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.keyID+"/"+scope)
+	query.Set("X-Amz-Date", now.Format(s3AmzDateLayout))
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(ttl/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		s3EscapePath(u.Path),
+		s3CanonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	signed := *u
+	signed.RawQuery = s3CanonicalQuery(query) + "&X-Amz-Signature=" + s.signature(now, scope, canonicalRequest)
+	return signed.String()
+}
+
+func (s *S3) scope(now time.Time) string {
+	return now.Format(s3ScopeDateLayout) + "/" + s.region + "/s3/aws4_request"
+}
+
+func (s *S3) signature(now time.Time, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, now.Format(s3AmzDateLayout), scope, hex.EncodeToString(hash[:])}, "\n")
+
+	key := s3HMAC([]byte("AWS4"+s.keySecret), now.Format(s3ScopeDateLayout))
+	key = s3HMAC(key, s.region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+
+	return hex.EncodeToString(s3HMAC(key, stringToSign))
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape encodes everything except the unreserved characters of RFC 3986.
+func s3Escape(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"monorepo/internal/config"
+	"net/http"
+	"time"
+)
+
+const (
+	DriverOSS   = "oss"
+	DriverS3    = "s3"
+	DriverLocal = "local"
+)
+
+var (
+	ErrUnknownDriver = errors.New("unknown storage driver")
+	ErrNotFound      = errors.New("object not found")
+)
+
+type Storage interface {
+	// Put stores content under key. Private objects are only reachable through SignedURL.
+	Put(ctx context.Context, key string, content io.Reader, contentType string, private bool) error
+
+	// Delete removes the object, deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the permanent link of a public object.
+	URL(key string) string
+
+	// SignedURL returns a link to the object that expires after ttl.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Handler is implemented by backends that serve their objects through the service itself.
+type Handler interface {
+	Handler() http.Handler
+}
+
+// NewStorage creates the backend selected by STORAGE_DRIVER, OSS when unset.
+func NewStorage(env *config.Environment) (Storage, error) {
+	switch env.StorageDriver {
+	case "", DriverOSS:
+		return NewOSS(env.OSSEndpoint, env.OSSBucketName, env.OSSAccessKeyID, env.OSSAccessKeySecret)
+	case DriverS3:
+		return NewS3(env.S3Endpoint, env.S3Region, env.S3BucketName, env.S3AccessKeyID, env.S3SecretAccessKey)
+	case DriverLocal:
+		return NewLocal(env.StorageDir, env.StorageBaseURL, env.JWTSecret)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, env.StorageDriver)
+}
+
+// ContentKey builds a key derived from the content so identical uploads share
+// one object and different uploads never overwrite each other.
+func ContentKey(prefix string, content []byte) string {
+	sum := sha256.Sum256(content)
+	return prefix + "/" + hex.EncodeToString(sum[:])
+}
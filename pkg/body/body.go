@@ -0,0 +1,114 @@
+// Package body derives body composition metrics from anthropometric
+// measurements. Lengths are in cm and weights in kg.
+package body
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+)
+
+// BMI classes with the WHO cut-offs for Asian populations, which put the
+// health risk at a lower BMI than the international ones.
+const (
+	Underweight = "underweight"
+	Normal      = "normal"
+	Overweight  = "overweight"
+	Obese1      = "obese_1"
+	Obese2      = "obese_2"
+)
+
+var (
+	ErrUnknownSex         = errors.New("sex has to be male or female to estimate body fat")
+	ErrMissingMeasurement = errors.New("measurement is missing")
+)
+
+// BMI is the weight over the squared height in m, rounded to one decimal.
+func BMI(weight, height float64) (float64, error) {
+	if weight <= 0 || height <= 0 {
+		return 0, ErrMissingMeasurement
+	}
+
+	m := height / 100
+	return math.Round(weight/(m*m)*10) / 10, nil
+}
+
+// BMIClass classifies a BMI with the WHO Asian cut-offs.
+func BMIClass(bmi float64) string {
+	switch {
+	case bmi < 18.5:
+		return Underweight
+	case bmi < 23:
+		return Normal
+	case bmi < 25:
+		return Overweight
+	case bmi < 30:
+		return Obese1
+	default:
+		return Obese2
+	}
+}
+
+// WaistToHeight is rounded to two decimals. From 0.5 the abdominal fat puts
+// the cardiometabolic health at risk, for either sex.
+func WaistToHeight(waist, height float64) (ratio float64, risk bool, err error) {
+	if waist <= 0 || height <= 0 {
+		return 0, false, ErrMissingMeasurement
+	}
+
+	ratio = math.Round(waist/height*100) / 100
+	return ratio, ratio >= 0.5, nil
+}
+
+// WaistToHip is rounded to two decimals. The WHO puts the risk from 0.90 for
+// men and 0.85 for women, the risk is not known for another sex.
+func WaistToHip(sex string, waist, hip float64) (ratio float64, risk bool, err error) {
+	if waist <= 0 || hip <= 0 {
+		return 0, false, ErrMissingMeasurement
+	}
+
+	ratio = math.Round(waist/hip*100) / 100
+	switch normalize(sex) {
+	case SexMale:
+		risk = ratio >= 0.9
+	case SexFemale:
+		risk = ratio >= 0.85
+	}
+
+	return ratio, risk, nil
+}
+
+// NavyBodyFat estimates the body fat percentage with the US Navy
+// circumference method, rounded to one decimal. Women need the hip as well.
+func NavyBodyFat(sex string, height, waist, neck, hip float64) (float64, error) {
+	if height <= 0 || waist <= 0 || neck <= 0 {
+		return 0, ErrMissingMeasurement
+	}
+
+	var density float64
+	switch normalize(sex) {
+	case SexMale:
+		if waist <= neck {
+			return 0, ErrMissingMeasurement
+		}
+		density = 1.0324 - 0.19077*math.Log10(waist-neck) + 0.15456*math.Log10(height)
+	case SexFemale:
+		if hip <= 0 || waist+hip <= neck {
+			return 0, ErrMissingMeasurement
+		}
+		density = 1.29579 - 0.35004*math.Log10(waist+hip-neck) + 0.22100*math.Log10(height)
+	default:
+		return 0, ErrUnknownSex
+	}
+
+	return math.Round((495/density-450)*10) / 10, nil
+}
+
+func normalize(sex string) string {
+	return strings.ToLower(strings.TrimSpace(sex))
+}
@@ -0,0 +1,77 @@
+package body
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBMI(t *testing.T) {
+	cases := []struct {
+		weight, height float64
+		want           float64
+		class          string
+	}{
+		{50, 170, 17.3, Underweight},
+		{60, 165, 22, Normal},
+		{68, 170, 23.5, Overweight},
+		{80, 170, 27.7, Obese1},
+		{95, 165, 34.9, Obese2},
+	}
+
+	for _, c := range cases {
+		got, err := BMI(c.weight, c.height)
+		if err != nil || got != c.want || BMIClass(got) != c.class {
+			t.Errorf("BMI(%v, %v) = %v %s, %v; want %v %s", c.weight, c.height, got, BMIClass(got), err, c.want, c.class)
+		}
+	}
+
+	if _, err := BMI(70, 0); !errors.Is(err, ErrMissingMeasurement) {
+		t.Errorf("BMI() without height error = %v, want %v", err, ErrMissingMeasurement)
+	}
+}
+
+func TestRatios(t *testing.T) {
+	if ratio, risk, _ := WaistToHeight(88, 170); ratio != 0.52 || !risk {
+		t.Errorf("WaistToHeight(88, 170) = %v, %v; want 0.52, true", ratio, risk)
+	}
+
+	cases := []struct {
+		sex        string
+		waist, hip float64
+		want       float64
+		risk       bool
+	}{
+		{"Male", 88, 100, 0.88, false},
+		{"female", 88, 100, 0.88, true},
+		{"", 95, 100, 0.95, false},
+	}
+
+	for _, c := range cases {
+		ratio, risk, err := WaistToHip(c.sex, c.waist, c.hip)
+		if err != nil || ratio != c.want || risk != c.risk {
+			t.Errorf("WaistToHip(%q, %v, %v) = %v, %v, %v; want %v, %v", c.sex, c.waist, c.hip, ratio, risk, err, c.want, c.risk)
+		}
+	}
+}
+
+func TestNavyBodyFat(t *testing.T) {
+	cases := []struct {
+		sex                      string
+		height, waist, neck, hip float64
+		want                     float64
+		err                      error
+	}{
+		{"male", 178, 90, 38, 0, 20.1, nil},
+		{"Female", 165, 75, 33, 98, 28.4, nil},
+		{"female", 165, 75, 33, 0, 0, ErrMissingMeasurement},
+		{"male", 178, 38, 40, 0, 0, ErrMissingMeasurement},
+		{"", 178, 90, 38, 0, 0, ErrUnknownSex},
+	}
+
+	for _, c := range cases {
+		got, err := NavyBodyFat(c.sex, c.height, c.waist, c.neck, c.hip)
+		if !errors.Is(err, c.err) || got != c.want {
+			t.Errorf("NavyBodyFat(%q, %v, %v, %v, %v) = %v, %v; want %v, %v", c.sex, c.height, c.waist, c.neck, c.hip, got, err, c.want, c.err)
+		}
+	}
+}
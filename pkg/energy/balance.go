@@ -0,0 +1,58 @@
+package energy
+
+import "errors"
+
+// KcalPerKg is the energy stored in a kg of body weight, mostly fat.
+const KcalPerKg = 7700.0
+
+const (
+	ConfidenceLow    = "low"
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high"
+)
+
+var ErrNotEnoughData = errors.New("not enough days of weight history to estimate energy expenditure")
+
+// MinBalanceDays is the shortest span an energy balance is estimated over,
+// water swings hide the change in body mass over fewer days.
+const MinBalanceDays = 14
+
+// Balance is what went in and what changed over a span of days. Intake is the
+// mean daily intake over the days it was logged, WeightChange the change of
+// the weight trend in kg.
+type Balance struct {
+	Days         int
+	Weighings    int
+	Intake       float64
+	IntakeDays   int
+	WeightChange float64
+}
+
+// Expenditure is the mean daily energy expenditure (TDEE) in kcal: what was
+// eaten less what was stored, or plus what was burned from the body.
+func (b Balance) Expenditure() (float64, error) {
+	if b.Days < MinBalanceDays || b.Weighings < 2 {
+		return 0, ErrNotEnoughData
+	}
+
+	return b.Intake - b.WeightChange*KcalPerKg/float64(b.Days), nil
+}
+
+// Confidence grades the estimate by how much of the span was logged. An
+// unlogged intake is a guess, so it never rates above low.
+func (b Balance) Confidence() string {
+	if b.Days <= 0 {
+		return ConfidenceLow
+	}
+
+	logged := float64(b.IntakeDays) / float64(b.Days)
+	weeks := float64(b.Days) / 7
+	switch {
+	case b.Days >= 21 && logged >= 0.8 && float64(b.Weighings) >= 3*weeks:
+		return ConfidenceHigh
+	case logged >= 0.5 && float64(b.Weighings) >= weeks:
+		return ConfidenceMedium
+	default:
+		return ConfidenceLow
+	}
+}
@@ -0,0 +1,34 @@
+package energy
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBalance(t *testing.T) {
+	cases := []struct {
+		balance    Balance
+		want       float64
+		err        error
+		confidence string
+	}{
+		{Balance{Days: 28, Weighings: 24, Intake: 1800, IntakeDays: 26, WeightChange: -2}, 2350, nil, ConfidenceHigh},
+		{Balance{Days: 28, Weighings: 6, Intake: 2600, IntakeDays: 20, WeightChange: 0.5}, 2462.5, nil, ConfidenceMedium},
+		{Balance{Days: 14, Weighings: 14, Intake: 2000, WeightChange: 0}, 2000, nil, ConfidenceLow},
+		{Balance{Days: 28, Weighings: 2, Intake: 2000, IntakeDays: 28, WeightChange: -1}, 2275, nil, ConfidenceLow},
+		{Balance{Days: 10, Weighings: 10, Intake: 2000, IntakeDays: 10}, 0, ErrNotEnoughData, ConfidenceMedium},
+		{Balance{Days: 28, Weighings: 1, Intake: 2000, IntakeDays: 28}, 0, ErrNotEnoughData, ConfidenceLow},
+	}
+
+	for _, c := range cases {
+		got, err := c.balance.Expenditure()
+		if !errors.Is(err, c.err) || math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("%+v Expenditure() = %v, %v; want %v, %v", c.balance, got, err, c.want, c.err)
+		}
+
+		if got := c.balance.Confidence(); got != c.confidence {
+			t.Errorf("%+v Confidence() = %v, want %v", c.balance, got, c.confidence)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Package energy estimates the basal metabolic rate (BMR) with the common
+// predictive formulas. Formulas are looked up by name from a registry so a
+// caller can store which one it used.
+package energy
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	HarrisBenedict        = "harris_benedict"
+	HarrisBenedictRevised = "harris_benedict_revised"
+	MifflinStJeor         = "mifflin_st_jeor"
+	KatchMcArdle          = "katch_mcardle"
+
+	// Default is used when no formula was chosen, it is the one goals were
+	// calculated with before formulas could be chosen.
+	Default = HarrisBenedict
+)
+
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+)
+
+var (
+	ErrUnknownFormula  = errors.New("unknown energy expenditure formula")
+	ErrUnknownSex      = errors.New("sex has to be male or female to estimate energy expenditure")
+	ErrBodyFatRequired = errors.New("body fat percentage is required by this formula")
+)
+
+// Subject holds the body measurements a formula works on. Weight is in kg,
+// Height in cm, BodyFat in percent and only known for some subjects.
+type Subject struct {
+	Sex     string
+	Weight  float64
+	Height  float64
+	Age     int
+	BodyFat *float64
+}
+
+// Formula estimates the BMR in kcal per day.
+type Formula interface {
+	Name() string
+	BMR(s Subject) (float64, error)
+}
+
+var (
+	registry = map[string]Formula{}
+	order    []string
+)
+
+func init() {
+	Register(sexFormula{name: HarrisBenedict, male: [4]float64{66.5, 13.75, 5.003, 6.75}, female: [4]float64{655.1, 9.563, 1.850, 4.676}})
+	Register(sexFormula{name: HarrisBenedictRevised, male: [4]float64{88.362, 13.397, 4.799, 5.677}, female: [4]float64{447.593, 9.247, 3.098, 4.330}})
+	Register(sexFormula{name: MifflinStJeor, male: [4]float64{5, 10, 6.25, 5}, female: [4]float64{-161, 10, 6.25, 5}})
+	Register(katchMcArdle{})
+}
+
+// Register adds a formula, replacing one registered under the same name.
+func Register(f Formula) {
+	if _, ok := registry[f.Name()]; !ok {
+		order = append(order, f.Name())
+	}
+	registry[f.Name()] = f
+}
+
+// Get returns the formula registered as name, Default when name is empty.
+func Get(name string) (Formula, error) {
+	if name == "" {
+		name = Default
+	}
+
+	f, ok := registry[name]
+	if !ok {
+		return nil, ErrUnknownFormula
+	}
+
+	return f, nil
+}
+
+// Formulas returns every registered formula in registration order.
+func Formulas() []Formula {
+	formulas := make([]Formula, 0, len(order))
+	for _, name := range order {
+		formulas = append(formulas, registry[name])
+	}
+
+	return formulas
+}
+
+// sexFormula is a linear equation on weight, height and age with separate
+// coefficients per sex: constant + w*weight + h*height - a*age.
+type sexFormula struct {
+	name   string
+	male   [4]float64
+	female [4]float64
+}
+
+func (f sexFormula) Name() string {
+	return f.name
+}
+
+func (f sexFormula) BMR(s Subject) (float64, error) {
+	var c [4]float64
+	switch strings.ToLower(strings.TrimSpace(s.Sex)) {
+	case SexMale:
+		c = f.male
+	case SexFemale:
+		c = f.female
+	default:
+		return 0, ErrUnknownSex
+	}
+
+	return c[0] + c[1]*s.Weight + c[2]*s.Height - c[3]*float64(s.Age), nil
+}
+
+// katchMcArdle works on the lean body mass, so it needs the body fat but not the sex.
+type katchMcArdle struct{}
+
+func (katchMcArdle) Name() string {
+	return KatchMcArdle
+}
+
+func (katchMcArdle) BMR(s Subject) (float64, error) {
+	if s.BodyFat == nil || *s.BodyFat <= 0 || *s.BodyFat >= 100 {
+		return 0, ErrBodyFatRequired
+	}
+
+	leanMass := s.Weight * (1 - *s.BodyFat/100)
+	return 370 + 21.6*leanMass, nil
+}
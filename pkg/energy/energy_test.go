@@ -0,0 +1,65 @@
+package energy
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBMR(t *testing.T) {
+	bodyFat := 25.0
+	male := Subject{Sex: "Male", Weight: 80, Height: 160, Age: 30, BodyFat: &bodyFat}
+	female := Subject{Sex: "female", Weight: 60, Height: 165, Age: 40}
+
+	cases := []struct {
+		formula string
+		subject Subject
+		want    float64
+		err     error
+	}{
+		{HarrisBenedict, male, 1764.48, nil},
+		{HarrisBenedict, female, 1347.09, nil},
+		{HarrisBenedictRevised, male, 1757.65, nil},
+		{HarrisBenedictRevised, female, 1340.38, nil},
+		{MifflinStJeor, male, 1655, nil},
+		{MifflinStJeor, female, 1270.25, nil},
+		{KatchMcArdle, male, 1666, nil},
+		{KatchMcArdle, female, 0, ErrBodyFatRequired},
+		{HarrisBenedict, Subject{Weight: 80, Height: 160, Age: 30}, 0, ErrUnknownSex},
+		{MifflinStJeor, Subject{Sex: "other", Weight: 80, Height: 160, Age: 30}, 0, ErrUnknownSex},
+		{"", male, 1764.48, nil},
+	}
+
+	for _, c := range cases {
+		f, err := Get(c.formula)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", c.formula, err)
+		}
+
+		got, err := f.BMR(c.subject)
+		if !errors.Is(err, c.err) || math.Round(got*100)/100 != c.want {
+			t.Errorf("%s BMR(%+v) = %v, %v; want %v, %v", f.Name(), c.subject, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	if _, err := Get("unknown"); !errors.Is(err, ErrUnknownFormula) {
+		t.Errorf("Get(unknown) error = %v, want %v", err, ErrUnknownFormula)
+	}
+
+	names := []string{}
+	for _, f := range Formulas() {
+		names = append(names, f.Name())
+	}
+
+	want := []string{HarrisBenedict, HarrisBenedictRevised, MifflinStJeor, KatchMcArdle}
+	if len(names) != len(want) {
+		t.Fatalf("Formulas() = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Formulas()[%d] = %s, want %s", i, names[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		maxSide       int
+		wantW, wantH  int
+	}{
+		{"landscape", 400, 200, 100, 100, 50},
+		{"portrait", 300, 600, 120, 60, 120},
+		{"smaller", 50, 40, 100, 50, 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Fit(image.NewRGBA(image.Rect(0, 0, tt.width, tt.height)), tt.maxSide).Bounds()
+			if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+				t.Errorf("Fit() = %dx%d, want %dx%d", got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 2x1 image, red on the left
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red := color.RGBA{R: 255, A: 255}
+	img.Set(0, 0, red)
+
+	// orientation 6 is rotated 90 degrees clockwise, red ends up on top
+	got := Orient(img, 6)
+	if got.Bounds().Dx() != 1 || got.Bounds().Dy() != 2 {
+		t.Fatalf("Orient() size = %v, want 1x2", got.Bounds())
+	}
+	if got.At(0, 0) != red {
+		t.Errorf("Orient() top pixel = %v, want %v", got.At(0, 0), red)
+	}
+}
+
+func TestExifOrientation(t *testing.T) {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // header, ifd0 at 8
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x06, 0x00, 0x00, // orientation = 6
+	}
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	size := len(segment) + 2
+	data := append([]byte{0xFF, 0xD8, 0xFF, 0xE1, byte(size >> 8), byte(size)}, segment...)
+	data = append(data, 0xFF, 0xDA)
+
+	if got := exifOrientation(data); got != 6 {
+		t.Errorf("exifOrientation() = %d, want 6", got)
+	}
+}
@@ -0,0 +1,156 @@
+// Package macro splits a daily calorie budget into protein, carbohydrate, fat
+// and fibre gram targets. Protein follows body weight, the calories left are
+// shared between carbohydrate and fat as the preset says.
+package macro
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+)
+
+const (
+	Balanced         = "balanced"
+	HighProtein      = "high_protein"
+	LowCarb          = "low_carb"
+	DiabeticFriendly = "diabetic_friendly"
+
+	Default = Balanced
+)
+
+const (
+	kcalPerGramProtein = 4
+	kcalPerGramCarb    = 4
+	kcalPerGramFat     = 9
+
+	// protein never takes more than this share of the budget, e.g. for heavy
+	// subjects on a steep deficit
+	maxProteinShare = 0.4
+)
+
+var (
+	ErrUnknownPreset = errors.New("unknown macronutrient preset")
+	ErrInvalidPreset = errors.New("macronutrient preset is invalid")
+)
+
+// Preset describes a split. CarbohydrateShare and FatShare divide the calories
+// left after protein and add up to 1.
+type Preset struct {
+	Name              string  `json:"name"`
+	ProteinPerKg      float64 `json:"protein_per_kg"`
+	CarbohydrateShare float64 `json:"carbohydrate_share"`
+	FatShare          float64 `json:"fat_share"`
+	FibrePer1000Kcal  float64 `json:"fibre_per_1000_kcal"`
+}
+
+// Targets are daily amounts in grams.
+type Targets struct {
+	Protein      float64
+	Carbohydrate float64
+	Fat          float64
+	Fibre        float64
+}
+
+var (
+	registry = map[string]Preset{}
+	order    []string
+)
+
+func init() {
+	for _, p := range []Preset{
+		{Name: Balanced, ProteinPerKg: 1.2, CarbohydrateShare: 0.6, FatShare: 0.4, FibrePer1000Kcal: 14},
+		{Name: HighProtein, ProteinPerKg: 2, CarbohydrateShare: 0.55, FatShare: 0.45, FibrePer1000Kcal: 14},
+		{Name: LowCarb, ProteinPerKg: 1.5, CarbohydrateShare: 0.25, FatShare: 0.75, FibrePer1000Kcal: 14},
+		{Name: DiabeticFriendly, ProteinPerKg: 1.3, CarbohydrateShare: 0.45, FatShare: 0.55, FibrePer1000Kcal: 20},
+	} {
+		Register(p)
+	}
+}
+
+// Register adds a preset, replacing one registered under the same name.
+func Register(p Preset) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+
+	if _, ok := registry[p.Name]; !ok {
+		order = append(order, p.Name)
+	}
+	registry[p.Name] = p
+
+	return nil
+}
+
+// Get returns the preset registered as name, Default when name is empty.
+func Get(name string) (Preset, error) {
+	if name == "" {
+		name = Default
+	}
+
+	p, ok := registry[name]
+	if !ok {
+		return Preset{}, ErrUnknownPreset
+	}
+
+	return p, nil
+}
+
+// Presets returns every registered preset in registration order.
+func Presets() []Preset {
+	presets := make([]Preset, 0, len(order))
+	for _, name := range order {
+		presets = append(presets, registry[name])
+	}
+
+	return presets
+}
+
+// ParsePresets reads a JSON list of presets, e.g. from configuration.
+func ParsePresets(s string) ([]Preset, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var presets []Preset
+	if err := json.Unmarshal([]byte(s), &presets); err != nil {
+		return nil, errors.Join(ErrInvalidPreset, err)
+	}
+
+	for _, p := range presets {
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return presets, nil
+}
+
+// Split derives the targets from the daily calorie budget and the body weight in kg.
+func (p Preset) Split(budget, weight float64) Targets {
+	if budget <= 0 {
+		return Targets{}
+	}
+
+	protein := min(p.ProteinPerKg*weight, budget*maxProteinShare/kcalPerGramProtein)
+	rest := budget - protein*kcalPerGramProtein
+
+	return Targets{
+		Protein:      math.Round(protein),
+		Carbohydrate: math.Round(rest * p.CarbohydrateShare / kcalPerGramCarb),
+		Fat:          math.Round(rest * p.FatShare / kcalPerGramFat),
+		Fibre:        math.Round(budget / 1000 * p.FibrePer1000Kcal),
+	}
+}
+
+func (p Preset) validate() error {
+	if p.Name == "" || p.ProteinPerKg <= 0 || p.CarbohydrateShare < 0 || p.FatShare < 0 || p.FibrePer1000Kcal < 0 {
+		return ErrInvalidPreset
+	}
+
+	if math.Abs(p.CarbohydrateShare+p.FatShare-1) > 0.001 {
+		return ErrInvalidPreset
+	}
+
+	return nil
+}
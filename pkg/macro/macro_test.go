@@ -0,0 +1,58 @@
+package macro
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		preset string
+		budget float64
+		weight float64
+		want   Targets
+	}{
+		{Balanced, 2000, 80, Targets{Protein: 96, Carbohydrate: 242, Fat: 72, Fibre: 28}},
+		{"", 2000, 80, Targets{Protein: 96, Carbohydrate: 242, Fat: 72, Fibre: 28}},
+		{HighProtein, 1500, 100, Targets{Protein: 150, Carbohydrate: 124, Fat: 45, Fibre: 21}},
+		{LowCarb, 1800, 70, Targets{Protein: 105, Carbohydrate: 86, Fat: 115, Fibre: 25}},
+		{DiabeticFriendly, 1600, 60, Targets{Protein: 78, Carbohydrate: 145, Fat: 79, Fibre: 32}},
+		{Balanced, 0, 80, Targets{}},
+	}
+
+	for _, c := range cases {
+		p, err := Get(c.preset)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", c.preset, err)
+		}
+
+		if got := p.Split(c.budget, c.weight); got != c.want {
+			t.Errorf("%s Split(%v, %v) = %+v, want %+v", p.Name, c.budget, c.weight, got, c.want)
+		}
+	}
+}
+
+func TestParsePresets(t *testing.T) {
+	presets, err := ParsePresets(`[{"name":"keto","protein_per_kg":1.6,"carbohydrate_share":0.1,"fat_share":0.9,"fibre_per_1000_kcal":10}]`)
+	if err != nil || len(presets) != 1 || presets[0].Name != "keto" {
+		t.Fatalf("ParsePresets() = %+v, %v", presets, err)
+	}
+
+	if presets, err := ParsePresets(""); err != nil || presets != nil {
+		t.Errorf("ParsePresets(empty) = %+v, %v; want nil, nil", presets, err)
+	}
+
+	for _, s := range []string{
+		`[{"name":"half","protein_per_kg":1.6,"carbohydrate_share":0.5,"fat_share":0.2}]`,
+		`[{"protein_per_kg":1.6,"carbohydrate_share":0.5,"fat_share":0.5}]`,
+		`{"name":"keto"}`,
+	} {
+		if _, err := ParsePresets(s); !errors.Is(err, ErrInvalidPreset) {
+			t.Errorf("ParsePresets(%s) error = %v, want %v", s, err, ErrInvalidPreset)
+		}
+	}
+
+	if _, err := Get("keto"); !errors.Is(err, ErrUnknownPreset) {
+		t.Errorf("Get(keto) error = %v, want %v", err, ErrUnknownPreset)
+	}
+}
@@ -0,0 +1,77 @@
+// Package medcode validates and normalizes the clinical codes used in
+// medical records: ICD-10 for diagnoses and ATC for medicines.
+package medcode
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidICD10 = errors.New("icd-10 code has an invalid format")
+	ErrInvalidATC   = errors.New("atc code has an invalid format")
+)
+
+// ICD10 normalizes a diagnosis code such as "e11.9" or "E119" to "E11.9".
+// A category ("E11") is accepted on its own; subcategories have up to four
+// characters after the dot.
+func ICD10(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.Replace(code, ".", "", 1)
+
+	if len(code) < 3 || len(code) > 7 {
+		return "", ErrInvalidICD10
+	}
+
+	if !isLetter(code[0]) || !isDigit(code[1]) || !(isDigit(code[2]) || isLetter(code[2])) {
+		return "", ErrInvalidICD10
+	}
+
+	for i := 3; i < len(code); i++ {
+		if !isDigit(code[i]) && !isLetter(code[i]) {
+			return "", ErrInvalidICD10
+		}
+	}
+
+	if len(code) == 3 {
+		return code, nil
+	}
+
+	return code[:3] + "." + code[3:], nil
+}
+
+// ATC normalizes an Anatomical Therapeutic Chemical code. Every level is
+// accepted: "C" (anatomical group), "C10", "C10A", "C10AA" and the chemical
+// substance "C10AA05".
+func ATC(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	// letter, two digits, letter, letter, two digits
+	pattern := "LDDLLDD"
+	switch len(code) {
+	case 1, 3, 4, 5, 7:
+	default:
+		return "", ErrInvalidATC
+	}
+
+	for i := 0; i < len(code); i++ {
+		ok := isDigit(code[i])
+		if pattern[i] == 'L' {
+			ok = isLetter(code[i])
+		}
+
+		if !ok {
+			return "", ErrInvalidATC
+		}
+	}
+
+	return code, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,62 @@
+package medcode
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestICD10(t *testing.T) {
+	cases := []struct {
+		code string
+		want string
+		err  error
+	}{
+		{"E11", "E11", nil},
+		{"e11.9", "E11.9", nil},
+		{"E119", "E11.9", nil},
+		{" J45.909 ", "J45.909", nil},
+		{"S72.001A", "S72.001A", nil},
+		{"U07.1", "U07.1", nil},
+		{"E1", "", ErrInvalidICD10},
+		{"11.9", "", ErrInvalidICD10},
+		{"EE1.9", "", ErrInvalidICD10},
+		{"E11.9-", "", ErrInvalidICD10},
+		{"E11.12345", "", ErrInvalidICD10},
+		{"", "", ErrInvalidICD10},
+	}
+
+	for _, c := range cases {
+		got, err := ICD10(c.code)
+		if !errors.Is(err, c.err) || got != c.want {
+			t.Errorf("ICD10(%q) = %q, %v; want %q, %v", c.code, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestATC(t *testing.T) {
+	cases := []struct {
+		code string
+		want string
+		err  error
+	}{
+		{"C", "C", nil},
+		{"c10", "C10", nil},
+		{"C10A", "C10A", nil},
+		{"C10AA", "C10AA", nil},
+		{"c10aa05", "C10AA05", nil},
+		{"N02BE01", "N02BE01", nil},
+		{"C1", "", ErrInvalidATC},
+		{"C10AA0", "", ErrInvalidATC},
+		{"10AA05", "", ErrInvalidATC},
+		{"C1AAA05", "", ErrInvalidATC},
+		{"C10A105", "", ErrInvalidATC},
+		{"", "", ErrInvalidATC},
+	}
+
+	for _, c := range cases {
+		got, err := ATC(c.code)
+		if !errors.Is(err, c.err) || got != c.want {
+			t.Errorf("ATC(%q) = %q, %v; want %q, %v", c.code, got, err, c.want, c.err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Package met estimates the energy spent on physical activities with
+// metabolic equivalents (MET) from the Compendium of Physical Activities.
+// One MET is roughly 1 kcal per kg of body weight per hour.
+package met
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	IntensityLight    = "light"
+	IntensityModerate = "moderate"
+	IntensityVigorous = "vigorous"
+)
+
+const (
+	CategoryCardio   = "cardio"
+	CategoryStrength = "strength"
+	CategorySport    = "sport"
+	CategoryMobility = "mobility"
+)
+
+var (
+	ErrUnknownActivity  = errors.New("unknown activity")
+	ErrUnknownIntensity = errors.New("unknown intensity")
+	ErrInvalidDuration  = errors.New("duration has to be greater than 0")
+)
+
+// band is the MET of speeds up to Speed km/h.
+type band struct {
+	Speed float64
+	MET   float64
+}
+
+// Activity has a MET per intensity. Cardio activities also have speed bands,
+// used instead of the intensity when the distance is known.
+type Activity struct {
+	Name      string
+	Category  string
+	Intensity map[string]float64
+	speeds    []band
+}
+
+var (
+	registry = map[string]Activity{}
+	order    []string
+)
+
+func init() {
+	for _, a := range []Activity{
+		{Name: "walking", Category: CategoryCardio, Intensity: intensity(2.8, 3.5, 5), speeds: []band{{3.2, 2}, {4, 2.8}, {5.6, 3.5}, {6.4, 4.3}, {7.2, 5}, {math.Inf(1), 7}}},
+		{Name: "running", Category: CategoryCardio, Intensity: intensity(7, 9.8, 11.5), speeds: []band{{7.2, 6}, {8.9, 8.3}, {10.5, 9.8}, {12.1, 11}, {13.7, 11.8}, {15.3, 12.8}, {math.Inf(1), 14.5}}},
+		{Name: "cycling", Category: CategoryCardio, Intensity: intensity(4, 6.8, 10), speeds: []band{{16, 4}, {19.3, 6.8}, {22.5, 8}, {25.7, 10}, {30.6, 12}, {math.Inf(1), 15.8}}},
+		{Name: "swimming", Category: CategoryCardio, Intensity: intensity(5.8, 8.3, 10)},
+		{Name: "hiking", Category: CategoryCardio, Intensity: intensity(5.3, 6, 7.8)},
+		{Name: "jump_rope", Category: CategoryCardio, Intensity: intensity(8.8, 11.8, 12.3)},
+		{Name: "dancing", Category: CategoryCardio, Intensity: intensity(4.5, 5.5, 7.8)},
+		{Name: "strength_training", Category: CategoryStrength, Intensity: intensity(3.5, 5, 6)},
+		{Name: "circuit_training", Category: CategoryStrength, Intensity: intensity(4.3, 8, 10)},
+		{Name: "football", Category: CategorySport, Intensity: intensity(7, 8, 10)},
+		{Name: "badminton", Category: CategorySport, Intensity: intensity(5.5, 7, 8.5)},
+		{Name: "basketball", Category: CategorySport, Intensity: intensity(6, 6.5, 8)},
+		{Name: "yoga", Category: CategoryMobility, Intensity: intensity(2.5, 3, 4)},
+		{Name: "stretching", Category: CategoryMobility, Intensity: intensity(2.3, 2.5, 3)},
+	} {
+		Register(a)
+	}
+}
+
+func intensity(light, moderate, vigorous float64) map[string]float64 {
+	return map[string]float64{IntensityLight: light, IntensityModerate: moderate, IntensityVigorous: vigorous}
+}
+
+// Register adds an activity, replacing one registered under the same name.
+func Register(a Activity) {
+	if _, ok := registry[a.Name]; !ok {
+		order = append(order, a.Name)
+	}
+	registry[a.Name] = a
+}
+
+func Get(name string) (Activity, error) {
+	a, ok := registry[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Activity{}, ErrUnknownActivity
+	}
+
+	return a, nil
+}
+
+// Activities returns every registered activity in registration order.
+func Activities() []Activity {
+	activities := make([]Activity, 0, len(order))
+	for _, name := range order {
+		activities = append(activities, registry[name])
+	}
+
+	return activities
+}
+
+// SpeedBased reports whether the distance is used to find the MET.
+func (a Activity) SpeedBased() bool {
+	return len(a.speeds) > 0
+}
+
+// MET returns the MET of the session. The speed decides when the distance in
+// km is known and the activity has speed bands, else the intensity does,
+// moderate when empty.
+func (a Activity) MET(intensity string, distance float64, duration time.Duration) (float64, error) {
+	if duration <= 0 {
+		return 0, ErrInvalidDuration
+	}
+
+	if distance > 0 && len(a.speeds) > 0 {
+		speed := distance / duration.Hours()
+		for _, b := range a.speeds {
+			if speed <= b.Speed {
+				return b.MET, nil
+			}
+		}
+	}
+
+	if intensity == "" {
+		intensity = IntensityModerate
+	}
+
+	met, ok := a.Intensity[strings.ToLower(intensity)]
+	if !ok {
+		return 0, ErrUnknownIntensity
+	}
+
+	return met, nil
+}
+
+// Calories burned in kcal at the MET for a body weight in kg, rounded to one decimal.
+func Calories(met, weight float64, duration time.Duration) float64 {
+	return math.Round(met*weight*duration.Hours()*10) / 10
+}
+
+// NetCalories are the Calories burned above the resting rate of 1 MET. A
+// budget or expenditure already holds the resting energy of the workout time,
+// adding or removing the gross calories would count it twice.
+func NetCalories(met, weight float64, duration time.Duration) float64 {
+	return Calories(max(met-1, 0), weight, duration)
+}
@@ -0,0 +1,84 @@
+package met
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMET(t *testing.T) {
+	cases := []struct {
+		activity  string
+		intensity string
+		distance  float64
+		duration  time.Duration
+		want      float64
+		err       error
+	}{
+		{"running", IntensityVigorous, 0, 30 * time.Minute, 11.5, nil},
+		{"Running", "", 0, 30 * time.Minute, 9.8, nil},
+		{"running", IntensityLight, 5, 30 * time.Minute, 9.8, nil},
+		{"running", "", 3, 30 * time.Minute, 6, nil},
+		{"walking", "", 3, time.Hour, 2, nil},
+		{"walking", "", 10, time.Hour, 7, nil},
+		{"cycling", "", 20, time.Hour, 8, nil},
+		{"yoga", "", 5, time.Hour, 3, nil},
+		{"swimming", "extreme", 0, time.Hour, 0, ErrUnknownIntensity},
+		{"running", "", 5, 0, 0, ErrInvalidDuration},
+	}
+
+	for _, c := range cases {
+		a, err := Get(c.activity)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", c.activity, err)
+		}
+
+		got, err := a.MET(c.intensity, c.distance, c.duration)
+		if !errors.Is(err, c.err) || got != c.want {
+			t.Errorf("%s MET(%q, %v, %v) = %v, %v; want %v, %v", a.Name, c.intensity, c.distance, c.duration, got, err, c.want, c.err)
+		}
+	}
+
+	if _, err := Get("chess"); !errors.Is(err, ErrUnknownActivity) {
+		t.Errorf("Get(chess) error = %v, want %v", err, ErrUnknownActivity)
+	}
+}
+
+func TestCalories(t *testing.T) {
+	cases := []struct {
+		met      float64
+		weight   float64
+		duration time.Duration
+		want     float64
+	}{
+		{9.8, 70, 30 * time.Minute, 343},
+		{3.5, 82.5, 45 * time.Minute, 216.6},
+		{6, 60, 0, 0},
+	}
+
+	for _, c := range cases {
+		if got := Calories(c.met, c.weight, c.duration); got != c.want {
+			t.Errorf("Calories(%v, %v, %v) = %v, want %v", c.met, c.weight, c.duration, got, c.want)
+		}
+	}
+}
+
+func TestNetCalories(t *testing.T) {
+	cases := []struct {
+		met      float64
+		weight   float64
+		duration time.Duration
+		want     float64
+	}{
+		{9.8, 70, 30 * time.Minute, 308},
+		{3.5, 82.5, 45 * time.Minute, 154.7},
+		{1, 70, time.Hour, 0},
+		{0.9, 70, time.Hour, 0},
+	}
+
+	for _, c := range cases {
+		if got := NetCalories(c.met, c.weight, c.duration); got != c.want {
+			t.Errorf("NetCalories(%v, %v, %v) = %v, want %v", c.met, c.weight, c.duration, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Package nik decodes the Indonesian population identity number (Nomor Induk
+// Kependudukan): PPKKCC DDMMYY SSSS, the district of registration, the date of
+// birth with 40 added to the day for women, and a serial.
+package nik
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	SexMale   = "Male"
+	SexFemale = "Female"
+)
+
+var ErrInvalid = errors.New("nik has an invalid format")
+
+// provinces are keyed by the first two digits of the NIK.
+var provinces = map[string]string{
+	"11": "Aceh",
+	"12": "Sumatera Utara",
+	"13": "Sumatera Barat",
+	"14": "Riau",
+	"15": "Jambi",
+	"16": "Sumatera Selatan",
+	"17": "Bengkulu",
+	"18": "Lampung",
+	"19": "Kepulauan Bangka Belitung",
+	"21": "Kepulauan Riau",
+	"31": "DKI Jakarta",
+	"32": "Jawa Barat",
+	"33": "Jawa Tengah",
+	"34": "DI Yogyakarta",
+	"35": "Jawa Timur",
+	"36": "Banten",
+	"51": "Bali",
+	"52": "Nusa Tenggara Barat",
+	"53": "Nusa Tenggara Timur",
+	"61": "Kalimantan Barat",
+	"62": "Kalimantan Tengah",
+	"63": "Kalimantan Selatan",
+	"64": "Kalimantan Timur",
+	"65": "Kalimantan Utara",
+	"71": "Sulawesi Utara",
+	"72": "Sulawesi Tengah",
+	"73": "Sulawesi Selatan",
+	"74": "Sulawesi Tenggara",
+	"75": "Gorontalo",
+	"76": "Sulawesi Barat",
+	"81": "Maluku",
+	"82": "Maluku Utara",
+	"91": "Papua",
+	"92": "Papua Barat",
+	"93": "Papua Selatan",
+	"94": "Papua Tengah",
+	"95": "Papua Pegunungan",
+	"96": "Papua Barat Daya",
+}
+
+type NIK struct {
+	// ProvinceCode, RegencyCode and DistrictCode are the 2, 4 and 6 digit region codes.
+	ProvinceCode string
+	RegencyCode  string
+	DistrictCode string
+	Province     string
+	DOB          time.Time
+	Sex          string
+	Serial       string
+}
+
+// Decode parses a 16 digit NIK. Two digit years are placed in the last hundred
+// years relative to now.
+func Decode(nik string, now time.Time) (*NIK, error) {
+	if len(nik) != 16 {
+		return nil, ErrInvalid
+	}
+
+	for _, c := range nik {
+		if c < '0' || c > '9' {
+			return nil, ErrInvalid
+		}
+	}
+
+	province, ok := provinces[nik[:2]]
+	if !ok || nik[2:4] == "00" || nik[4:6] == "00" || nik[12:] == "0000" {
+		return nil, ErrInvalid
+	}
+
+	day, month, year := atoi(nik[6:8]), atoi(nik[8:10]), atoi(nik[10:12])
+	sex := SexMale
+	if day > 40 {
+		day -= 40
+		sex = SexFemale
+	}
+
+	year += now.Year() / 100 * 100
+	if year > now.Year() {
+		year -= 100
+	}
+
+	dob := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+
+	// time.Date normalizes out of range values, e.g. 31 February becomes 3 March
+	if day < 1 || dob.Day() != day || int(dob.Month()) != month || dob.After(now) {
+		return nil, ErrInvalid
+	}
+
+	return &NIK{
+		ProvinceCode: nik[:2],
+		RegencyCode:  nik[:4],
+		DistrictCode: nik[:6],
+		Province:     province,
+		DOB:          dob,
+		Sex:          sex,
+		Serial:       nik[12:],
+	}, nil
+}
+
+// Province returns the province name of a region code, empty when unknown.
+func Province(regionCode string) string {
+	if len(regionCode) < 2 {
+		return ""
+	}
+
+	return provinces[regionCode[:2]]
+}
+
+// SameDate reports whether t falls on the decoded date of birth.
+func (n *NIK) SameDate(t time.Time) bool {
+	y, m, d := t.Date()
+	return y == n.DOB.Year() && m == n.DOB.Month() && d == n.DOB.Day()
+}
+
+func atoi(s string) int {
+	n := 0
+	for _, c := range s {
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
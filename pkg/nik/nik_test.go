@@ -0,0 +1,54 @@
+package nik
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		nik      string
+		dob      time.Time
+		sex      string
+		district string
+		err      error
+	}{
+		{"3174051708900001", time.Date(1990, 8, 17, 0, 0, 0, 0, time.UTC), SexMale, "317405", nil},
+		{"3273015702050002", time.Date(2005, 2, 17, 0, 0, 0, 0, time.UTC), SexFemale, "327301", nil},
+		{"3578107112230003", time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), SexFemale, "357810", nil},
+		{"3174052902000001", time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC), SexMale, "317405", nil},
+		{"3174053002000001", time.Time{}, "", "", ErrInvalid}, // 30 february
+		{"3174050101250001", time.Time{}, "", "", nil},         // 1925, not in the future
+		{"3174050000900001", time.Time{}, "", "", ErrInvalid},
+		{"3174051713900001", time.Time{}, "", "", ErrInvalid},
+		{"3174051708900000", time.Time{}, "", "", ErrInvalid},
+		{"9974051708900001", time.Time{}, "", "", ErrInvalid},
+		{"317405170890001", time.Time{}, "", "", ErrInvalid},
+		{"31740517089O0001", time.Time{}, "", "", ErrInvalid},
+	}
+
+	for _, c := range cases {
+		got, err := Decode(c.nik, now)
+		if !errors.Is(err, c.err) {
+			t.Errorf("Decode(%q) err = %v, want %v", c.nik, err, c.err)
+			continue
+		}
+
+		if err != nil || c.dob.IsZero() {
+			continue
+		}
+
+		if !got.DOB.Equal(c.dob) || got.Sex != c.sex || got.DistrictCode != c.district {
+			t.Errorf("Decode(%q) = %s %s %s, want %s %s %s", c.nik, got.DOB.Format(time.DateOnly), got.Sex, got.DistrictCode,
+				c.dob.Format(time.DateOnly), c.sex, c.district)
+		}
+	}
+
+	got, _ := Decode("3174050101250001", now)
+	if got.DOB.Year() != 1925 {
+		t.Errorf("Decode century = %d, want 1925", got.DOB.Year())
+	}
+}
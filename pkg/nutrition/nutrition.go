@@ -0,0 +1,193 @@
+// Package nutrition works out what a portion of food contains from the
+// nutrient values per 100 g, and reads food catalogs from CSV.
+package nutrition
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Portion units. Liquids are listed per 100 ml in the catalogs, so a
+// millilitre counts as a gram.
+const (
+	UnitGram       = "g"
+	UnitMilliliter = "ml"
+	UnitServing    = "serving"
+)
+
+var (
+	ErrUnknownUnit     = errors.New("unknown portion unit")
+	ErrNoServingSize   = errors.New("food has no serving size")
+	ErrInvalidCatalog  = errors.New("food catalog is invalid")
+	ErrInvalidQuantity = errors.New("portion amount has to be greater than 0")
+)
+
+// Nutrients holds energy in kcal and the macronutrients in grams.
+type Nutrients struct {
+	Energy       float64
+	Protein      float64
+	Carbohydrate float64
+	Fat          float64
+	Fibre        float64
+}
+
+func (n Nutrients) Add(o Nutrients) Nutrients {
+	return Nutrients{
+		Energy:       n.Energy + o.Energy,
+		Protein:      n.Protein + o.Protein,
+		Carbohydrate: n.Carbohydrate + o.Carbohydrate,
+		Fat:          n.Fat + o.Fat,
+		Fibre:        n.Fibre + o.Fibre,
+	}
+}
+
+func (n Nutrients) Scale(f float64) Nutrients {
+	return Nutrients{
+		Energy:       n.Energy * f,
+		Protein:      n.Protein * f,
+		Carbohydrate: n.Carbohydrate * f,
+		Fat:          n.Fat * f,
+		Fibre:        n.Fibre * f,
+	}
+}
+
+// Round rounds every value to one decimal.
+func (n Nutrients) Round() Nutrients {
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+
+	return Nutrients{
+		Energy:       round(n.Energy),
+		Protein:      round(n.Protein),
+		Carbohydrate: round(n.Carbohydrate),
+		Fat:          round(n.Fat),
+		Fibre:        round(n.Fibre),
+	}
+}
+
+// Food is a catalog entry. ServingSize is in grams, ServingUnit describes the
+// serving, e.g. "1 piring" or "1 potong".
+type Food struct {
+	Code        string
+	Name        string
+	Category    string
+	ServingSize float64
+	ServingUnit string
+	Per100g     Nutrients
+}
+
+// Grams converts an amount in unit to grams.
+func (f Food) Grams(amount float64, unit string) (float64, error) {
+	if amount <= 0 {
+		return 0, ErrInvalidQuantity
+	}
+
+	switch strings.ToLower(unit) {
+	case UnitGram, UnitMilliliter:
+		return amount, nil
+	case UnitServing:
+		if f.ServingSize <= 0 {
+			return 0, ErrNoServingSize
+		}
+		return amount * f.ServingSize, nil
+	default:
+		return 0, ErrUnknownUnit
+	}
+}
+
+// Portion returns the grams and the nutrients of an amount in unit.
+func (f Food) Portion(amount float64, unit string) (float64, Nutrients, error) {
+	grams, err := f.Grams(amount, unit)
+	if err != nil {
+		return 0, Nutrients{}, err
+	}
+
+	return grams, f.Per100g.Scale(grams / 100).Round(), nil
+}
+
+var catalogColumns = []string{"code", "name", "category", "serving_size", "serving_unit", "energy", "protein", "carbohydrate", "fat", "fibre"}
+
+// ParseCatalog reads a CSV catalog with a header row naming the columns code,
+// name, category, serving_size, serving_unit, energy, protein, carbohydrate,
+// fat and fibre in any order. Nutrients are per 100 g, category and serving
+// may be left empty.
+func ParseCatalog(r io.Reader) ([]Food, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Join(ErrInvalidCatalog, err)
+	}
+
+	// spreadsheet exports often start with a byte order mark
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range catalogColumns {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", ErrInvalidCatalog, name)
+		}
+	}
+
+	foods := []Food{}
+	lines := map[string]int{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, errors.Join(ErrInvalidCatalog, err)
+		}
+
+		col := func(name string) string { return strings.TrimSpace(record[index[name]]) }
+		num := func(name string) (float64, error) {
+			s := col(name)
+			if s == "" {
+				return 0, nil
+			}
+
+			v, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("%w: line %d: %s is not a positive number", ErrInvalidCatalog, line, name)
+			}
+			return v, nil
+		}
+
+		food := Food{Code: col("code"), Name: col("name"), Category: col("category"), ServingUnit: col("serving_unit")}
+		if food.Code == "" || food.Name == "" {
+			return nil, fmt.Errorf("%w: line %d: code and name are required", ErrInvalidCatalog, line)
+		}
+
+		// a code is the id of the food, it can be imported only once
+		if first, ok := lines[food.Code]; ok {
+			return nil, fmt.Errorf("%w: line %d: code %s is already on line %d", ErrInvalidCatalog, line, food.Code, first)
+		}
+		lines[food.Code] = line
+
+		for _, field := range []struct {
+			name string
+			dst  *float64
+		}{
+			{"serving_size", &food.ServingSize},
+			{"energy", &food.Per100g.Energy},
+			{"protein", &food.Per100g.Protein},
+			{"carbohydrate", &food.Per100g.Carbohydrate},
+			{"fat", &food.Per100g.Fat},
+			{"fibre", &food.Per100g.Fibre},
+		} {
+			if *field.dst, err = num(field.name); err != nil {
+				return nil, err
+			}
+		}
+
+		foods = append(foods, food)
+	}
+
+	return foods, nil
+}
@@ -0,0 +1,73 @@
+package nutrition
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPortion(t *testing.T) {
+	rice := Food{Code: "AP001", Name: "Nasi putih", ServingSize: 150, ServingUnit: "1 piring", Per100g: Nutrients{Energy: 180, Protein: 3, Carbohydrate: 39.8, Fat: 0.3, Fibre: 0.2}}
+
+	cases := []struct {
+		amount float64
+		unit   string
+		grams  float64
+		want   Nutrients
+		err    error
+	}{
+		{100, UnitGram, 100, Nutrients{Energy: 180, Protein: 3, Carbohydrate: 39.8, Fat: 0.3, Fibre: 0.2}, nil},
+		{1, UnitServing, 150, Nutrients{Energy: 270, Protein: 4.5, Carbohydrate: 59.7, Fat: 0.5, Fibre: 0.3}, nil},
+		{0.5, "Serving", 75, Nutrients{Energy: 135, Protein: 2.3, Carbohydrate: 29.9, Fat: 0.2, Fibre: 0.2}, nil},
+		{250, UnitMilliliter, 250, Nutrients{Energy: 450, Protein: 7.5, Carbohydrate: 99.5, Fat: 0.8, Fibre: 0.5}, nil},
+		{1, "cup", 0, Nutrients{}, ErrUnknownUnit},
+		{0, UnitGram, 0, Nutrients{}, ErrInvalidQuantity},
+	}
+
+	for _, c := range cases {
+		grams, got, err := rice.Portion(c.amount, c.unit)
+		if !errors.Is(err, c.err) || grams != c.grams || got != c.want {
+			t.Errorf("Portion(%v, %q) = %v, %+v, %v; want %v, %+v, %v", c.amount, c.unit, grams, got, err, c.grams, c.want, c.err)
+		}
+	}
+
+	if _, _, err := (Food{}).Portion(1, UnitServing); !errors.Is(err, ErrNoServingSize) {
+		t.Errorf("Portion without serving size error = %v, want %v", err, ErrNoServingSize)
+	}
+}
+
+func TestParseCatalog(t *testing.T) {
+	csv := "\ufeffname,code,category,serving_size,serving_unit,energy,protein,carbohydrate,fat,fibre\n" +
+		"Tempe goreng,AK009,Kacang,50,1 potong,350,20,7.8,28,1.4\n" +
+		"\"Teh manis\",MN001,Minuman,,,\"28,5\",0,7,0,\n"
+
+	foods, err := ParseCatalog(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ParseCatalog() error = %v", err)
+	}
+
+	want := []Food{
+		{Code: "AK009", Name: "Tempe goreng", Category: "Kacang", ServingSize: 50, ServingUnit: "1 potong", Per100g: Nutrients{Energy: 350, Protein: 20, Carbohydrate: 7.8, Fat: 28, Fibre: 1.4}},
+		{Code: "MN001", Name: "Teh manis", Category: "Minuman", Per100g: Nutrients{Energy: 28.5, Carbohydrate: 7}},
+	}
+	if len(foods) != len(want) {
+		t.Fatalf("ParseCatalog() = %+v, want %+v", foods, want)
+	}
+	for i := range want {
+		if foods[i] != want[i] {
+			t.Errorf("ParseCatalog()[%d] = %+v, want %+v", i, foods[i], want[i])
+		}
+	}
+
+	for _, s := range []string{
+		"code,name,energy\nA1,Apel,52\n",
+		"code,name,category,serving_size,serving_unit,energy,protein,carbohydrate,fat,fibre\n,Apel,,,,52,0.3,14,0.2,2.4\n",
+		"code,name,category,serving_size,serving_unit,energy,protein,carbohydrate,fat,fibre\nA1,Apel,,,,-52,0.3,14,0.2,2.4\n",
+		"code,name,category,serving_size,serving_unit,energy,protein,carbohydrate,fat,fibre\nA1,Apel,,,,52,0.3,14,0.2,2.4\nA1,Apel merah,,,,52,0.3,14,0.2,2.4\n",
+		"",
+	} {
+		if _, err := ParseCatalog(strings.NewReader(s)); !errors.Is(err, ErrInvalidCatalog) {
+			t.Errorf("ParseCatalog(%q) error = %v, want %v", s, err, ErrInvalidCatalog)
+		}
+	}
+}
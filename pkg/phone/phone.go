@@ -0,0 +1,173 @@
+// Package phone parses phone numbers written in local or international form
+// and normalizes them to E.164.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultCallingCode is assumed when a number has neither a country code nor a + prefix.
+const DefaultCallingCode = "62"
+
+var (
+	ErrInvalid            = errors.New("phone has an invalid format")
+	ErrInvalidCountryCode = errors.New("country code has an invalid format")
+)
+
+// callingCodes are the country and global service codes assigned by the ITU
+// in E.164. They are prefix-free, so a number starts with at most one.
+var callingCodes = codeSet(`
+	1 7
+	20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49
+	51 52 53 54 55 56 57 58 60 61 62 63 64 65 66 81 82 84 86
+	90 91 92 93 94 95 98
+	211 212 213 216 218 220 221 222 223 224 225 226 227 228 229
+	230 231 232 233 234 235 236 237 238 239 240 241 242 243 244 245 246 247 248 249
+	250 251 252 253 254 255 256 257 258 260 261 262 263 264 265 266 267 268 269
+	290 291 297 298 299
+	350 351 352 353 354 355 356 357 358 359 370 371 372 373 374 375 376 377 378 379
+	380 381 382 383 385 386 387 389 420 421 423
+	500 501 502 503 504 505 506 507 508 509 590 591 592 593 594 595 596 597 598 599
+	670 672 673 674 675 676 677 678 679 680 681 682 683 685 686 687 688 689 690 691 692
+	800 808 850 852 853 855 856 870 880 881 882 883 886 888
+	960 961 962 963 964 965 966 967 968 970 971 972 973 974 975 976 977 979
+	992 993 994 995 996 998
+`)
+
+func codeSet(codes string) map[string]bool {
+	set := map[string]bool{}
+	for _, code := range strings.Fields(codes) {
+		set[code] = true
+	}
+	return set
+}
+
+// region describes the national significant number of a country: its length
+// once the trunk prefix is removed.
+type region struct {
+	minLen int
+	maxLen int
+}
+
+// regions are keyed by calling code. Numbers of other countries only get the
+// generic E.164 length check.
+var regions = map[string]region{
+	"1":   {10, 10}, // US, CA
+	"44":  {9, 10},  // GB
+	"60":  {8, 10},  // MY
+	"61":  {9, 9},   // AU
+	"62":  {8, 12},  // ID
+	"63":  {8, 10},  // PH
+	"65":  {8, 8},   // SG
+	"66":  {8, 9},   // TH
+	"81":  {9, 10},  // JP
+	"82":  {8, 10},  // KR
+	"84":  {9, 10},  // VN
+	"86":  {10, 11}, // CN
+	"91":  {10, 10}, // IN
+	"852": {8, 8},   // HK
+	"886": {8, 9},   // TW
+	"966": {9, 9},   // SA
+	"971": {8, 9},   // AE
+}
+
+// Number is a parsed phone number.
+type Number struct {
+	CallingCode string
+	National    string
+}
+
+// E164 formats the number as +<calling code><national number>.
+func (n Number) E164() string {
+	return "+" + n.CallingCode + n.National
+}
+
+// Parse reads number, which may be international ("+62 812-3456-7890",
+// "0062812...") or national ("0812...", "812..."). National numbers use
+// callingCode, DefaultCallingCode when empty.
+func Parse(callingCode, number string) (*Number, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')', '/':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(number))
+
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		return parseInternational(digits[1:])
+	case strings.HasPrefix(digits, "00"):
+		return parseInternational(digits[2:])
+	}
+
+	callingCode = strings.TrimPrefix(strings.TrimSpace(callingCode), "+")
+	if callingCode == "" {
+		callingCode = DefaultCallingCode
+	}
+
+	if !callingCodes[callingCode] {
+		return nil, ErrInvalidCountryCode
+	}
+
+	// the trunk prefix is only dialed within the country
+	national := strings.TrimPrefix(digits, "0")
+
+	// numbers typed with the country code but without the +
+	if r, ok := regions[callingCode]; ok && len(national) > r.maxLen && strings.HasPrefix(national, callingCode) {
+		national = national[len(callingCode):]
+	}
+
+	return validate(callingCode, national)
+}
+
+// Normalize parses number and returns it in E.164.
+func Normalize(callingCode, number string) (string, error) {
+	n, err := Parse(callingCode, number)
+	if err != nil {
+		return "", err
+	}
+
+	return n.E164(), nil
+}
+
+func parseInternational(digits string) (*Number, error) {
+	if !isDigits(digits) || digits == "" || digits[0] == '0' {
+		return nil, ErrInvalid
+	}
+
+	// calling codes are prefix-free, the first match is the only one
+	for size := 1; size <= 3 && size < len(digits); size++ {
+		if callingCodes[digits[:size]] {
+			return validate(digits[:size], digits[size:])
+		}
+	}
+
+	return nil, ErrInvalidCountryCode
+}
+
+func validate(callingCode, national string) (*Number, error) {
+	if !isDigits(national) || national == "" || national[0] == '0' {
+		return nil, ErrInvalid
+	}
+
+	if r, ok := regions[callingCode]; ok {
+		if len(national) < r.minLen || len(national) > r.maxLen {
+			return nil, ErrInvalid
+		}
+	} else if len(national) < 4 || len(callingCode)+len(national) > 15 {
+		return nil, ErrInvalid
+	}
+
+	return &Number{CallingCode: callingCode, National: national}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		callingCode string
+		number      string
+		want        string
+		err         error
+	}{
+		{"62", "81234567890", "+6281234567890", nil},
+		{"62", "081234567890", "+6281234567890", nil},
+		{"62", "0812-3456-7890", "+6281234567890", nil},
+		{"+62", "6281234567890", "+6281234567890", nil},
+		{"", "081200450000", "+6281200450000", nil},
+		{"", "+62 812 3456 7890", "+6281234567890", nil},
+		{"", "0062812345678", "+62812345678", nil},
+		{"", "021 5010 0000", "+622150100000", nil},
+		{"65", "9123 4567", "+6591234567", nil},
+		{"", "+6591234567", "+6591234567", nil},
+		{"1", "(415) 555-0100", "+14155550100", nil},
+		{"", "+852 9123 4567", "+85291234567", nil},
+		{"", "+4930123456", "+4930123456", nil},
+		{"", "+33 6 12 34 56 78", "+33612345678", nil},
+		{"", "+353 85 123 4567", "+353851234567", nil},
+		{"", "+7 912 345 6789", "+79123456789", nil},
+		{"", "+44 7911 123456", "+447911123456", nil},
+		{"", "+2881234567", "", ErrInvalidCountryCode},
+		{"999", "81234567", "", ErrInvalidCountryCode},
+		{"65", "912345678", "", ErrInvalid},
+		{"62", "8123", "", ErrInvalid},
+		{"62", "0812abc4567", "", ErrInvalid},
+		{"62", "", "", ErrInvalid},
+		{"6a", "81234567890", "", ErrInvalidCountryCode},
+		{"", "+0812345678", "", ErrInvalid},
+	}
+
+	for _, c := range cases {
+		got, err := Normalize(c.callingCode, c.number)
+		if !errors.Is(err, c.err) || got != c.want {
+			t.Errorf("Normalize(%q, %q) = %q, %v; want %q, %v", c.callingCode, c.number, got, err, c.want, c.err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package trend smooths a noisy daily weight series with an exponential moving
+// average, the way The Hacker's Diet does, and extrapolates it. Day to day
+// weight swings with water and food, the trend shows the change in body mass.
+package trend
+
+import (
+	"math"
+	"time"
+)
+
+// Alpha is the share of the gap between a weighing and the trend the trend
+// moves by in one day.
+const Alpha = 0.1
+
+const day = 24 * time.Hour
+
+// Horizon is the furthest a trend is projected, a rate reaching the target
+// later than that is as good as flat.
+const Horizon = 5 * 365
+
+type Point struct {
+	Date   time.Time
+	Weight float64
+}
+
+type Smoothed struct {
+	Date   time.Time
+	Weight float64
+	Trend  float64
+}
+
+// Smooth returns the trend at every point, points have to be sorted by date.
+// A gap of several days moves the trend as far as that many daily steps would,
+// so a missed week does not make the next weighing count like one day.
+func Smooth(points []Point, alpha float64) []Smoothed {
+	smoothed := make([]Smoothed, 0, len(points))
+	for i, p := range points {
+		if i == 0 {
+			smoothed = append(smoothed, Smoothed{Date: p.Date, Weight: p.Weight, Trend: p.Weight})
+			continue
+		}
+
+		prev := smoothed[i-1]
+		days := max(math.Round(p.Date.Sub(prev.Date).Hours()/24), 1)
+		weight := 1 - math.Pow(1-alpha, days)
+
+		smoothed = append(smoothed, Smoothed{
+			Date:   p.Date,
+			Weight: p.Weight,
+			Trend:  prev.Trend + weight*(p.Weight-prev.Trend),
+		})
+	}
+
+	return smoothed
+}
+
+// WeeklyRate is the least squares slope of the trend over the last window
+// before the latest point, in kg per week. It is 0 with fewer than two points.
+func WeeklyRate(smoothed []Smoothed, window time.Duration) float64 {
+	if len(smoothed) < 2 {
+		return 0
+	}
+
+	last := smoothed[len(smoothed)-1].Date
+	var n, sumX, sumY, sumXY, sumXX float64
+	for _, s := range smoothed {
+		if last.Sub(s.Date) > window {
+			continue
+		}
+
+		x := s.Date.Sub(last).Hours() / 24
+		n++
+		sumX += x
+		sumY += s.Trend
+		sumXY += x * s.Trend
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if n < 2 || denominator == 0 {
+		return 0
+	}
+
+	return (n*sumXY - sumX*sumY) / denominator * 7
+}
+
+// Project returns the day the trend reaches target at the weekly rate, false
+// when the trend is not heading there or gets there beyond the horizon.
+func Project(from time.Time, current, target, weeklyRate float64) (time.Time, bool) {
+	if current == target {
+		return from, true
+	}
+
+	if weeklyRate == 0 || (target-current)*weeklyRate < 0 {
+		return time.Time{}, false
+	}
+
+	days := math.Ceil((target - current) / (weeklyRate / 7))
+	if days > Horizon {
+		return time.Time{}, false
+	}
+
+	return from.Add(time.Duration(days) * day), true
+}
@@ -0,0 +1,74 @@
+package trend
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+func points(weights ...float64) []Point {
+	p := make([]Point, 0, len(weights))
+	for i, w := range weights {
+		p = append(p, Point{Date: start.AddDate(0, 0, i), Weight: w})
+	}
+
+	return p
+}
+
+func TestSmooth(t *testing.T) {
+	got := Smooth(points(80, 81, 79), Alpha)
+	want := []float64{80, 80.1, 79.99}
+	for i, s := range got {
+		if math.Abs(s.Trend-want[i]) > 1e-9 {
+			t.Errorf("Smooth()[%d].Trend = %v, want %v", i, s.Trend, want[i])
+		}
+	}
+
+	// three days apart moves the trend as far as three daily weighings would
+	gap := Smooth([]Point{{start, 80}, {start.AddDate(0, 0, 3), 70}}, Alpha)
+	if w := 80 - 10*(1-0.9*0.9*0.9); math.Abs(gap[1].Trend-w) > 1e-9 {
+		t.Errorf("Smooth() across a gap = %v, want %v", gap[1].Trend, w)
+	}
+}
+
+func TestWeeklyRate(t *testing.T) {
+	// a steady 0.1 kg a day, the trend lags but keeps the slope once settled
+	weights := make([]float64, 90)
+	for i := range weights {
+		weights[i] = 90 - 0.1*float64(i)
+	}
+
+	if got := WeeklyRate(Smooth(points(weights...), Alpha), 28*24*time.Hour); math.Abs(got+0.7) > 0.01 {
+		t.Errorf("WeeklyRate() = %v, want -0.7", got)
+	}
+
+	if got := WeeklyRate(Smooth(points(80), Alpha), 28*24*time.Hour); got != 0 {
+		t.Errorf("WeeklyRate() of one point = %v, want 0", got)
+	}
+}
+
+func TestProject(t *testing.T) {
+	cases := []struct {
+		current, target, rate float64
+		want                  time.Time
+		ok                    bool
+	}{
+		{80, 75, -0.5, start.AddDate(0, 0, 70), true},
+		{60, 62, 0.25, start.AddDate(0, 0, 56), true},
+		{80, 75, 0.5, time.Time{}, false},
+		{80, 75, 0, time.Time{}, false},
+		{75, 75, 0, start, true},
+		{80, 75, -0.02, start.AddDate(0, 0, 1750), true},
+		{80, 75, -0.01, time.Time{}, false},
+		{80, 75, -1e-300, time.Time{}, false},
+	}
+
+	for _, c := range cases {
+		got, ok := Project(start, c.current, c.target, c.rate)
+		if !got.Equal(c.want) || ok != c.ok {
+			t.Errorf("Project(%v, %v, %v) = %v, %v; want %v, %v", c.current, c.target, c.rate, got, ok, c.want, c.ok)
+		}
+	}
+}
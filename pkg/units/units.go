@@ -0,0 +1,117 @@
+// Package units converts body weights and heights between the metric units
+// they are stored in and the imperial units a profile may prefer.
+package units
+
+import (
+	"errors"
+	"math"
+)
+
+// Systems a profile can prefer, metric when not set.
+const (
+	Metric   = "metric"
+	Imperial = "imperial"
+)
+
+const (
+	Kilogram   = "kg"
+	Pound      = "lb"
+	Centimetre = "cm"
+	Inch       = "in"
+	Foot       = "ft"
+)
+
+const (
+	kgPerPound = 0.45359237
+	cmPerInch  = 2.54
+	cmPerFoot  = 30.48
+)
+
+var (
+	ErrUnknownSystem = errors.New("unit system has to be metric or imperial")
+	ErrUnknownUnit   = errors.New("unknown unit")
+)
+
+// System returns the unit system, metric when empty.
+func System(system string) (string, error) {
+	switch system {
+	case "", Metric:
+		return Metric, nil
+	case Imperial:
+		return Imperial, nil
+	default:
+		return "", ErrUnknownSystem
+	}
+}
+
+// WeightUnit is the unit weights are shown in for a system.
+func WeightUnit(system string) string {
+	if system == Imperial {
+		return Pound
+	}
+
+	return Kilogram
+}
+
+// HeightUnit is the unit heights are shown in for a system.
+func HeightUnit(system string) string {
+	if system == Imperial {
+		return Inch
+	}
+
+	return Centimetre
+}
+
+// ToKg converts a weight in unit to kg, an empty unit is kg. Differences of
+// weights convert the same way.
+func ToKg(v float64, unit string) (float64, error) {
+	switch unit {
+	case "", Kilogram:
+		return v, nil
+	case Pound:
+		return v * kgPerPound, nil
+	default:
+		return 0, ErrUnknownUnit
+	}
+}
+
+// FromKg converts a weight in kg to unit, rounded to two decimals. An unknown
+// unit is taken as kg.
+func FromKg(kg float64, unit string) float64 {
+	if unit == Pound {
+		kg /= kgPerPound
+	}
+
+	return round(kg)
+}
+
+// ToCm converts a height in unit to cm, an empty unit is cm.
+func ToCm(v float64, unit string) (float64, error) {
+	switch unit {
+	case "", Centimetre:
+		return v, nil
+	case Inch:
+		return v * cmPerInch, nil
+	case Foot:
+		return v * cmPerFoot, nil
+	default:
+		return 0, ErrUnknownUnit
+	}
+}
+
+// FromCm converts a height in cm to unit, rounded to two decimals. An unknown
+// unit is taken as cm.
+func FromCm(cm float64, unit string) float64 {
+	switch unit {
+	case Inch:
+		cm /= cmPerInch
+	case Foot:
+		cm /= cmPerFoot
+	}
+
+	return round(cm)
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,92 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWeight(t *testing.T) {
+	cases := []struct {
+		v    float64
+		unit string
+		kg   float64
+	}{
+		{70, "", 70},
+		{70, Kilogram, 70},
+		{150, Pound, 68.04},
+		{1, Pound, 0.45},
+	}
+
+	for _, c := range cases {
+		got, err := ToKg(c.v, c.unit)
+		if err != nil || math.Round(got*100)/100 != c.kg {
+			t.Errorf("ToKg(%v, %q) = %v, %v; want %v", c.v, c.unit, got, err, c.kg)
+		}
+
+		if back := FromKg(got, c.unit); back != c.v {
+			t.Errorf("FromKg(%v, %q) = %v, want %v", got, c.unit, back, c.v)
+		}
+	}
+
+	if got := FromKg(70, Pound); got != 154.32 {
+		t.Errorf("FromKg(70, lb) = %v, want 154.32", got)
+	}
+
+	if _, err := ToKg(70, "st"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ToKg() with unknown unit error = %v, want %v", err, ErrUnknownUnit)
+	}
+}
+
+func TestHeight(t *testing.T) {
+	cases := []struct {
+		v    float64
+		unit string
+		cm   float64
+	}{
+		{170, "", 170},
+		{170, Centimetre, 170},
+		{70, Inch, 177.8},
+		{5.5, Foot, 167.64},
+	}
+
+	for _, c := range cases {
+		got, err := ToCm(c.v, c.unit)
+		if err != nil || math.Round(got*100)/100 != c.cm {
+			t.Errorf("ToCm(%v, %q) = %v, %v; want %v", c.v, c.unit, got, err, c.cm)
+		}
+
+		if back := FromCm(got, c.unit); back != c.v {
+			t.Errorf("FromCm(%v, %q) = %v, want %v", got, c.unit, back, c.v)
+		}
+	}
+
+	if got := FromCm(180, Inch); got != 70.87 {
+		t.Errorf("FromCm(180, in) = %v, want 70.87", got)
+	}
+
+	if _, err := ToCm(170, "m"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("ToCm() with unknown unit error = %v, want %v", err, ErrUnknownUnit)
+	}
+}
+
+func TestSystem(t *testing.T) {
+	cases := []struct {
+		system, want, weight, height string
+	}{
+		{"", Metric, Kilogram, Centimetre},
+		{Metric, Metric, Kilogram, Centimetre},
+		{Imperial, Imperial, Pound, Inch},
+	}
+
+	for _, c := range cases {
+		got, err := System(c.system)
+		if err != nil || got != c.want || WeightUnit(got) != c.weight || HeightUnit(got) != c.height {
+			t.Errorf("System(%q) = %v %s %s, %v; want %v %s %s", c.system, got, WeightUnit(got), HeightUnit(got), err, c.want, c.weight, c.height)
+		}
+	}
+
+	if _, err := System("si"); !errors.Is(err, ErrUnknownSystem) {
+		t.Errorf("System(si) error = %v, want %v", err, ErrUnknownSystem)
+	}
+}
@@ -0,0 +1,13 @@
+package utils
+
+import "time"
+
+// Age returns the number of full years between dob and now.
+func Age(dob, now time.Time) int {
+	years := now.Year() - dob.Year()
+	if now.Month() < dob.Month() || (now.Month() == dob.Month() && now.Day() < dob.Day()) {
+		years--
+	}
+
+	return max(years, 0)
+}
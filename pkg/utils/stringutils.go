@@ -1,6 +1,10 @@
 package utils
 
-import "math/rand"
+import (
+	crand "crypto/rand"
+	"math/big"
+	"math/rand"
+)
 
 const (
 	alphabeticalLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
@@ -22,3 +26,19 @@ func RandAlphanumericString(n int) string {
 	}
 	return string(b)
 }
+
+// RandSecureAlphanumericString is like RandAlphanumericString but reads from
+// crypto/rand, for values that act as credentials.
+func RandSecureAlphanumericString(n int) (string, error) {
+	max := big.NewInt(int64(len(alphanumericLetters)))
+
+	b := make([]byte, n)
+	for i := range b {
+		j, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = alphanumericLetters[j.Int64()]
+	}
+	return string(b), nil
+}
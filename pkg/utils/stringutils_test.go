@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRandSecureAlphanumericString(t *testing.T) {
+	seen := map[rune]bool{}
+	for i := 0; i < 100; i++ {
+		got, err := RandSecureAlphanumericString(32)
+		if err != nil {
+			t.Fatalf("RandSecureAlphanumericString() error = %v", err)
+		}
+		if len(got) != 32 {
+			t.Fatalf("RandSecureAlphanumericString() = %s, want 32 characters", got)
+		}
+
+		for _, r := range got {
+			if !strings.ContainsRune(alphanumericLetters, r) {
+				t.Fatalf("RandSecureAlphanumericString() = %s, want only alphanumeric characters", got)
+			}
+			seen[r] = true
+		}
+	}
+
+	// 3200 draws miss one of 62 letters with a chance of about 1e-20
+	if len(seen) != len(alphanumericLetters) {
+		t.Errorf("RandSecureAlphanumericString() drew %d distinct letters, want %d", len(seen), len(alphanumericLetters))
+	}
+}
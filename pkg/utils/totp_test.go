@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// Test vectors from RFC 6238 appendix B (SHA1, truncated to 6 digits).
+func TestTOTPCode(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret() error = %v", err)
+	}
+
+	now := time.Unix(1723420800, 0)
+	previous, _ := TOTPCode(secret, TOTPStep(now)-1)
+	stale, _ := TOTPCode(secret, TOTPStep(now)-3)
+
+	if step, ok := ValidateTOTP(secret, previous, now, 1); !ok || step != TOTPStep(now)-1 {
+		t.Errorf("ValidateTOTP() rejected code within skew")
+	}
+	if _, ok := ValidateTOTP(secret, stale, now, 1); ok {
+		t.Errorf("ValidateTOTP() accepted code outside skew")
+	}
+	if _, ok := ValidateTOTP(secret, "12345", now, 1); ok {
+		t.Errorf("ValidateTOTP() accepted short code")
+	}
+}
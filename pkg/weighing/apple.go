@@ -0,0 +1,60 @@
+package weighing
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"monorepo/pkg/units"
+	"strconv"
+)
+
+const appleBodyMass = "HKQuantityTypeIdentifierBodyMass"
+
+// parseAppleHealth streams the records of export.xml, which easily runs into
+// hundreds of megabytes, and reads the body mass ones.
+func parseAppleHealth(br *bufio.Reader, res *Result) error {
+	decoder := xml.NewDecoder(br)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		el, ok := token.(xml.StartElement)
+		if !ok || el.Name.Local != "Record" {
+			continue
+		}
+
+		attr := map[string]string{}
+		for _, a := range el.Attr {
+			attr[a.Name.Local] = a.Value
+		}
+		if attr["type"] != appleBodyMass {
+			continue
+		}
+
+		row, _ := decoder.InputPos()
+		t, err := parseTime(attr["startDate"], "2006-01-02 15:04:05 -0700")
+		if err != nil {
+			res.fail(row, err)
+			continue
+		}
+
+		weight, err := strconv.ParseFloat(attr["value"], 64)
+		if err != nil {
+			res.fail(row, fmt.Errorf("%w: %q", ErrInvalidWeight, attr["value"]))
+			continue
+		}
+
+		unit := attr["unit"]
+		if unit == "g" {
+			weight, unit = weight/1000, units.Kilogram
+		}
+
+		res.add(row, t, weight, unit)
+	}
+}
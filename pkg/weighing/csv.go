@@ -0,0 +1,136 @@
+package weighing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"monorepo/pkg/units"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvLayouts = []string{
+	time.DateOnly,
+	time.DateTime,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02",
+	"2006/01/02 15:04:05",
+}
+
+// parseCSV reads date and weight columns, in this order unless a header names
+// them. A unit column or a unit in the weight header, e.g. "weight (lb)",
+// overrides unit. Spreadsheets set to a decimal comma separate with semicolons.
+func parseCSV(br *bufio.Reader, unit string, res *Result) error {
+	head, _ := br.Peek(1024)
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if bytes.Count(head, []byte(";")) > bytes.Count(head, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	dateCol, weightCol, unitCol := 0, 1, -1
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		row, _ := reader.FieldPos(0)
+
+		// the first row is a header unless its second column is a weight
+		if first && len(record) > 1 && !isNumber(record[1]) {
+			dateCol, weightCol, unitCol, unit = csvColumns(record, unit)
+			if dateCol < 0 || weightCol < 0 {
+				return errors.New("missing date or weight column")
+			}
+			continue
+		}
+
+		col := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		t, err := parseTime(col(dateCol), csvLayouts...)
+		if err != nil {
+			res.fail(row, err)
+			continue
+		}
+
+		weight, err := strconv.ParseFloat(strings.Replace(col(weightCol), ",", ".", 1), 64)
+		if err != nil || weight <= 0 {
+			res.fail(row, fmt.Errorf("%w: %q", ErrInvalidWeight, col(weightCol)))
+			continue
+		}
+
+		rowUnit := unit
+		if u := strings.ToLower(col(unitCol)); u != "" {
+			rowUnit = csvUnit(u)
+			if rowUnit == "" {
+				rowUnit = u
+			}
+		}
+
+		res.add(row, t, weight, rowUnit)
+	}
+}
+
+// csvColumns finds the columns of a header, -1 when missing.
+func csvColumns(header []string, unit string) (dateCol, weightCol, unitCol int, weightUnit string) {
+	dateCol, weightCol, unitCol, weightUnit = -1, -1, -1, unit
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "unit":
+			unitCol = i
+		case dateCol < 0 && (strings.Contains(name, "date") || strings.Contains(name, "time")):
+			dateCol = i
+		case weightCol < 0 && (strings.Contains(name, "weight") || strings.Contains(name, "mass")):
+			weightCol = i
+			if u := csvUnit(name); u != "" {
+				weightUnit = u
+			}
+		}
+	}
+
+	return dateCol, weightCol, unitCol, weightUnit
+}
+
+// csvUnit reads the unit out of a unit column or a weight header, empty when
+// neither kg nor lb is named.
+func csvUnit(s string) string {
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r < 'a' || r > 'z' }) {
+		switch field {
+		case "kg", "kgs", "kilogram", "kilograms":
+			return units.Kilogram
+		case "lb", "lbs", "pound", "pounds":
+			return units.Pound
+		}
+	}
+
+	return ""
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(s), ",", ".", 1), 64)
+	return err == nil
+}
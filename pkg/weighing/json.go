@@ -0,0 +1,113 @@
+package weighing
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"monorepo/pkg/units"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nanos are the timestamps of Google Fit, a number in Takeout and a string in
+// the REST API.
+type nanos int64
+
+func (n *nanos) UnmarshalJSON(b []byte) error {
+	v, err := strconv.ParseInt(strings.Trim(string(b), `"`), 10, 64)
+	*n = nanos(v)
+	return err
+}
+
+type googleFitValue struct {
+	FpVal *float64 `json:"fpVal"`
+}
+
+type googleFitPoint struct {
+	DataTypeName string `json:"dataTypeName"`
+	EndTimeNanos nanos  `json:"endTimeNanos"`
+	FitValue     []struct {
+		Value googleFitValue `json:"value"`
+	} `json:"fitValue"`
+	Value []googleFitValue `json:"value"`
+}
+
+// parseGoogleFit reads the data points of a Takeout weight file or of a REST
+// dataset, always in kg.
+func parseGoogleFit(br *bufio.Reader, res *Result) error {
+	var file struct {
+		DataPoints []googleFitPoint `json:"Data Points"`
+		Point      []googleFitPoint `json:"point"`
+	}
+	if err := json.NewDecoder(br).Decode(&file); err != nil {
+		return err
+	}
+
+	for i, point := range append(file.DataPoints, file.Point...) {
+		row := i + 1
+		if point.DataTypeName != "" && !strings.HasPrefix(point.DataTypeName, "com.google.weight") {
+			continue
+		}
+
+		var weight *float64
+		if len(point.FitValue) > 0 {
+			weight = point.FitValue[0].Value.FpVal
+		} else if len(point.Value) > 0 {
+			weight = point.Value[0].FpVal
+		}
+		if weight == nil || point.EndTimeNanos <= 0 {
+			res.fail(row, fmt.Errorf("%w: point without a time or a weight", ErrInvalidWeight))
+			continue
+		}
+
+		res.add(row, time.Unix(0, int64(point.EndTimeNanos)).In(time.Local), *weight, units.Kilogram)
+	}
+
+	return nil
+}
+
+type fitbitLog struct {
+	Date   string  `json:"date"`
+	Time   string  `json:"time"`
+	Weight float64 `json:"weight"`
+}
+
+// parseFitbit reads the logs of a Takeout weight file, a list, or of the Web
+// API, nested in a weight list. Both are in the unit of the account.
+func parseFitbit(br *bufio.Reader, unit string, res *Result) error {
+	head, _ := br.Peek(64)
+
+	var logs []fitbitLog
+	if bytes.HasPrefix(trimHead(head), []byte("{")) {
+		var file struct {
+			Weight []fitbitLog `json:"weight"`
+		}
+		if err := json.NewDecoder(br).Decode(&file); err != nil {
+			return err
+		}
+		logs = file.Weight
+	} else if err := json.NewDecoder(br).Decode(&logs); err != nil {
+		return err
+	}
+
+	for i, log := range logs {
+		row := i + 1
+		t, err := parseTime(strings.TrimSpace(log.Date+" "+log.Time),
+			"01/02/06 15:04:05", "01/02/06", time.DateTime, time.DateOnly, "01/02/2006 15:04:05", "01/02/2006")
+		if err != nil {
+			res.fail(row, err)
+			continue
+		}
+
+		if log.Weight <= 0 {
+			res.fail(row, fmt.Errorf("%w: %v", ErrInvalidWeight, log.Weight))
+			continue
+		}
+
+		res.add(row, t, log.Weight, unit)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+// Package weighing reads the weighings other apps export: CSV, Apple Health
+// export.xml, Google Fit JSON from Takeout or the REST API, and Fitbit weight
+// JSON. Weights are converted to kg, files without units are in the unit
+// given. Times without an offset are taken in the local time zone.
+package weighing
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"monorepo/pkg/units"
+	"sort"
+	"time"
+)
+
+const (
+	FormatCSV         = "csv"
+	FormatAppleHealth = "apple_health"
+	FormatGoogleFit   = "google_fit"
+	FormatFitbit      = "fitbit"
+)
+
+// A body weight outside these bounds in kg is taken as a typo or a wrong unit.
+const (
+	MinWeight = 20
+	MaxWeight = 400
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown import format")
+	ErrInvalidFile   = errors.New("import file is invalid")
+	ErrInvalidDate   = errors.New("date is invalid")
+	ErrInvalidWeight = errors.New("weight is invalid")
+)
+
+// Weighing is a weight in kg. Row is where it is in the file: the line of CSV
+// and XML, the position in the list of JSON.
+type Weighing struct {
+	Row    int
+	Time   time.Time
+	Weight float64
+}
+
+// Day is the date the weighing was taken, where it was taken.
+func (w Weighing) Day() string {
+	return w.Time.Format(time.DateOnly)
+}
+
+// RowError is a row that could not be read, the rest of the file still is.
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+type Result struct {
+	Format    string
+	Weighings []Weighing
+	Errors    []RowError
+}
+
+// Parse reads the weighings of a file in format, detected from its first
+// bytes when empty. Unit is the one of files that do not tell.
+func Parse(r io.Reader, format, unit string) (*Result, error) {
+	br := bufio.NewReader(r)
+
+	// spreadsheets and text editors often start files with a byte order mark
+	if bom, _ := br.Peek(3); bytes.Equal(bom, []byte("\ufeff")) {
+		br.Discard(3)
+	}
+
+	if format == "" {
+		head, _ := br.Peek(512)
+		format = Detect(head)
+	}
+
+	res := &Result{Format: format}
+	var err error
+	switch format {
+	case FormatCSV:
+		err = parseCSV(br, unit, res)
+	case FormatAppleHealth:
+		err = parseAppleHealth(br, res)
+	case FormatGoogleFit:
+		err = parseGoogleFit(br, res)
+	case FormatFitbit:
+		err = parseFitbit(br, unit, res)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, errors.Join(ErrInvalidFile, err)
+	}
+
+	return res, nil
+}
+
+// Detect guesses the format from the first bytes of a file.
+func Detect(head []byte) string {
+	head = trimHead(head)
+	switch {
+	case bytes.HasPrefix(head, []byte("<")):
+		return FormatAppleHealth
+	case bytes.HasPrefix(head, []byte("[")):
+		return FormatFitbit
+	case bytes.HasPrefix(head, []byte("{")):
+		// the Fitbit API nests the logs in a weight list
+		if bytes.Contains(head, []byte(`"weight"`)) {
+			return FormatFitbit
+		}
+		return FormatGoogleFit
+	default:
+		return FormatCSV
+	}
+}
+
+// trimHead drops the blanks a file may start with.
+func trimHead(head []byte) []byte {
+	return bytes.TrimLeft(head, " \t\r\n")
+}
+
+// Daily keeps the latest weighing of each day, sorted by day.
+func Daily(weighings []Weighing) []Weighing {
+	latest := map[string]Weighing{}
+	for _, w := range weighings {
+		if prev, ok := latest[w.Day()]; !ok || !w.Time.Before(prev.Time) {
+			latest[w.Day()] = w
+		}
+	}
+
+	res := make([]Weighing, 0, len(latest))
+	for _, w := range latest {
+		res = append(res, w)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Day() < res[j].Day() })
+
+	return res
+}
+
+// add converts a weight in unit and adds it, or the row error.
+func (res *Result) add(row int, t time.Time, weight float64, unit string) {
+	if unit == "" {
+		unit = units.Kilogram
+	}
+
+	kg, err := units.ToKg(weight, unit)
+	if err == nil && (kg < MinWeight || kg > MaxWeight) {
+		err = fmt.Errorf("%w: %v %s is not a body weight", ErrInvalidWeight, weight, unit)
+	}
+	if err != nil {
+		res.fail(row, err)
+		return
+	}
+
+	res.Weighings = append(res.Weighings, Weighing{Row: row, Time: t, Weight: math.Round(kg*100) / 100})
+}
+
+func (res *Result) fail(row int, err error) {
+	res.Errors = append(res.Errors, RowError{Row: row, Err: err})
+}
+
+// parseTime reads t in the first layout that fits.
+func parseTime(t string, layouts ...string) (time.Time, error) {
+	for _, layout := range layouts {
+		if v, err := time.ParseInLocation(layout, t, time.Local); err == nil {
+			return v, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidDate, t)
+}
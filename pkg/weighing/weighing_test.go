@@ -0,0 +1,160 @@
+package weighing
+
+import (
+	"errors"
+	"fmt"
+	"monorepo/pkg/units"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const appleExport = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE HealthData [
+<!ELEMENT HealthData (ExportDate,Me,(Record|Workout)*)>
+]>
+<HealthData locale="en_US">
+ <Record type="HKQuantityTypeIdentifierHeight" unit="cm" startDate="2024-03-01 07:00:00 +0700" value="170"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Health" unit="kg" startDate="2024-03-01 07:00:00 +0700" value="80.5"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Health" unit="lb" startDate="2024-03-02 06:30:00 +0700" value="176.4"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Health" unit="kg" startDate="yesterday" value="80"/>
+</HealthData>
+`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		format     string
+		unit       string
+		wantFormat string
+		want       []string
+		wantErrors []int
+	}{
+		{
+			name:       "CSV with a header in lb and a decimal comma",
+			file:       "\ufeffDate;Weight (lb);Note\n2024-03-01;176,4;x\n2024-03-02;abc;\nnot a date;170;\n2024-03-03 07:00:00;900;\n",
+			unit:       units.Kilogram,
+			wantFormat: FormatCSV,
+			want:       []string{"2024-03-01 80.01"},
+			wantErrors: []int{3, 4, 5},
+		},
+		{
+			name:       "CSV without a header in the given unit",
+			file:       "2024-03-01,176.4\n\n2024/03/02,175\n",
+			unit:       units.Pound,
+			wantFormat: FormatCSV,
+			want:       []string{"2024-03-01 80.01", "2024-03-02 79.38"},
+		},
+		{
+			name:       "CSV with a unit column",
+			file:       "date,weight,unit\n2024-03-01,80.5,kg\n2024-03-02,180,lbs\n2024-03-03,12,st\n",
+			format:     FormatCSV,
+			wantFormat: FormatCSV,
+			want:       []string{"2024-03-01 80.5", "2024-03-02 81.65"},
+			wantErrors: []int{4},
+		},
+		{
+			name:       "Apple Health body mass records",
+			file:       appleExport,
+			wantFormat: FormatAppleHealth,
+			want:       []string{"2024-03-01 80.5", "2024-03-02 80.01"},
+			wantErrors: []int{9},
+		},
+		{
+			name:       "Google Fit Takeout",
+			file:       `{"Data Source":"derived:com.google.weight:merged","Data Points":[{"fitValue":[{"value":{"fpVal":80.5}}],"dataTypeName":"com.google.weight","endTimeNanos":1709294400000000000},{"fitValue":[],"dataTypeName":"com.google.weight","endTimeNanos":1709380800000000000}]}`,
+			wantFormat: FormatGoogleFit,
+			want:       []string{"2024-03-01 80.5"},
+			wantErrors: []int{2},
+		},
+		{
+			name:       "Google Fit REST dataset",
+			file:       `{"point":[{"startTimeNanos":"1709294400000000000","endTimeNanos":"1709294400000000000","dataTypeName":"com.google.weight","value":[{"fpVal":80.5}]}]}`,
+			format:     FormatGoogleFit,
+			unit:       units.Pound,
+			wantFormat: FormatGoogleFit,
+			want:       []string{"2024-03-01 80.5"},
+		},
+		{
+			name:       "Fitbit Takeout",
+			file:       `[{"logId":1,"weight":176.4,"bmi":25.3,"date":"03/01/24","time":"07:12:34","source":"Aria"},{"logId":2,"weight":0,"date":"03/02/24","time":"07:00:00"}]`,
+			unit:       units.Pound,
+			wantFormat: FormatFitbit,
+			want:       []string{"2024-03-01 80.01"},
+			wantErrors: []int{2},
+		},
+		{
+			name:       "Fitbit Web API",
+			file:       `{"weight":[{"bmi":25,"date":"2024-03-01","logId":1,"time":"07:12:34","weight":80.5,"source":"API"}]}`,
+			unit:       units.Kilogram,
+			wantFormat: FormatFitbit,
+			want:       []string{"2024-03-01 80.5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Parse(strings.NewReader(tt.file), tt.format, tt.unit)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got := []string{}
+			for _, w := range res.Weighings {
+				got = append(got, fmt.Sprintf("%s %v", w.Day(), w.Weight))
+			}
+
+			var gotErrors []int
+			for _, e := range res.Errors {
+				gotErrors = append(gotErrors, e.Row)
+			}
+
+			if res.Format != tt.wantFormat || !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(gotErrors, tt.wantErrors) {
+				t.Errorf("Parse() = %s %v, errors on %v; want %s %v, errors on %v", res.Format, got, res.Errors, tt.wantFormat, tt.want, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("a,b\n1,2\n"), FormatCSV, ""); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Parse() without date and weight columns error = %v, want %v", err, ErrInvalidFile)
+	}
+
+	if _, err := Parse(strings.NewReader(`{"point":`), FormatGoogleFit, ""); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Parse() truncated JSON error = %v, want %v", err, ErrInvalidFile)
+	}
+
+	if _, err := Parse(strings.NewReader(""), "samsung", ""); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Parse() unknown format error = %v, want %v", err, ErrUnknownFormat)
+	}
+
+	res, _ := Parse(strings.NewReader("2024-03-01,80,st\n"), FormatCSV, "st")
+	if len(res.Errors) != 1 || !errors.Is(res.Errors[0], units.ErrUnknownUnit) {
+		t.Errorf("Parse() in an unknown unit errors = %v, want %v", res.Errors, units.ErrUnknownUnit)
+	}
+}
+
+func TestDaily(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2024, time.March, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	got := Daily([]Weighing{
+		{Row: 1, Time: at(2, 7), Weight: 80},
+		{Row: 2, Time: at(1, 20), Weight: 81},
+		{Row: 3, Time: at(2, 21), Weight: 80.5},
+		{Row: 4, Time: at(1, 6), Weight: 80.8},
+	})
+
+	want := []Weighing{
+		{Row: 2, Time: at(1, 20), Weight: 81},
+		{Row: 3, Time: at(2, 21), Weight: 80.5},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Daily() = %v, want %v", got, want)
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"io"
+	"monorepo/internal/acting"
 	"monorepo/internal/config"
 	"monorepo/internal/constants"
 	"monorepo/internal/dto"
@@ -53,6 +54,7 @@ func (rest *REST) InitializeRoutes() {
 	rest.Router.Get("/", rest.Healthcheck)
 	rest.Router.Group(func(r chi.Router) {
 		r.Use(rest.oauthAuthorizer)
+		r.Use(acting.Middleware)
 		r.Post("/event", rest.CreateEvent)
 		r.Get("/events", rest.GetEvents)
 		r.Get("/appointments", rest.GetAppointments)
@@ -96,6 +98,17 @@ func (rest *REST) CreateEvent(w http.ResponseWriter, r *http.Request) {
 
 	var profile *dto.ResponseGetProfile
 	if req.Type == constants.Appointment {
+		claims := ctx.Value(oauth.ClaimsContext)
+		c, _ := json.Marshal(claims)
+		var fClaims dto.FirebaseClaims
+		json.Unmarshal(c, &fClaims)
+
+		if !fClaims.IsEmailVerified() {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(dto.Object[any]{Error: "Email address is not verified", Message: "Failed to Create Event"})
+			return
+		}
+
 		code, prof, err := rest.eventService.GetProfile(ctx)
 		if err != nil {
 			w.WriteHeader(code)
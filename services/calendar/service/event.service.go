@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"monorepo/internal/acting"
 	"monorepo/internal/dto"
 	"monorepo/pkg/common"
 	"monorepo/pkg/utils"
@@ -42,12 +43,12 @@ func (service *EventService) GetProfile(ctx context.Context) (int, *dto.Response
 
 	url := os.Getenv("BASE_URL_USER") + "/profile"
 
-	headers := []utils.Header{
+	headers := acting.Headers(ctx, []utils.Header{
 		{
 			Key:   "Authorization",
 			Value: "Bearer " + ctx.Value(oauth.AccessTokenContext).(string),
 		},
-	}
+	})
 
 	profile := dto.ResponseGetProfile{}
 
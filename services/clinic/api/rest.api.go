@@ -2,9 +2,11 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"monorepo/internal/config"
 	"monorepo/internal/dto"
+	"monorepo/pkg/imaging"
 	"monorepo/services/clinic/service"
 	"net/http"
 	"strconv"
@@ -55,6 +57,7 @@ func (rest *REST) InitializeRoutes() {
 		r.Get("/clinic", rest.GetAllClinic)
 		r.Get("/clinic/{id}", rest.GetClinic)
 		r.Patch("/clinic/{id}", rest.UpdateClinic)
+		r.Patch("/clinic/{id}/logo", rest.UploadLogo)
 		r.Delete("/clinic/{id}", rest.DeleteClinic)
 
 		r.Get("/clinic/{cid}/location", rest.GetAllLocation)
@@ -161,6 +164,34 @@ func (rest *REST) UpdateClinic(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(dto.Object[*dto.ResponseUpdateClinic]{Data: &data, Message: "OK"})
 }
 
+func (rest *REST) UploadLogo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	id := chi.URLParam(r, "id")
+	r.ParseMultipartForm(10 << 20)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := rest.clinicService.UploadLogo(ctx, id, file)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Upload Logo"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Upload Logo"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseUpdateClinic]{Data: data, Message: "OK"})
+}
+
 func (rest *REST) DeleteClinic(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
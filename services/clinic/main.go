@@ -5,6 +5,7 @@ import (
 	"monorepo/internal/config"
 	"monorepo/internal/db"
 	"monorepo/internal/repository"
+	"monorepo/internal/storage"
 	"monorepo/services/clinic/api"
 	"monorepo/services/clinic/models"
 	"monorepo/services/clinic/service"
@@ -34,12 +35,21 @@ func main() {
 	tbCLinic := repository.NewRepository[models.Clinic, string](pgdb, repository.Tables.Clinic)
 	tbLocation := repository.NewRepository[models.Location, string](pgdb, repository.Tables.Location)
 
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize storage: %v", err)
+	}
+
 	restAPI := api.NewREST(
-		service.NewClinicService(tbCLinic, tbLocation),
+		service.NewClinicService(tbCLinic, tbLocation, store),
 		cfg,
 	)
 
 	restAPI.InitializeRoutes()
+	if h, ok := store.(storage.Handler); ok {
+		restAPI.Router.Mount("/files", h.Handler())
+	}
+
 	servicePort := fmt.Sprintf(":%d", cfg.ServicePort)
 	logrus.Infof("Starting HTTP server in port: %d", cfg.ServicePort)
 	if err := http.ListenAndServe(servicePort, restAPI.Router); err != nil {
@@ -6,11 +6,12 @@ import (
 )
 
 type Clinic struct {
-	ID        string       `db:"id" goqu:"omitempty"`
-	Name      string       `db:"name" goqu:"omitempty"`
-	Address   string       `db:"address" goqu:"omitempty"`
-	Phone     string       `db:"phone" goqu:"omitempty"`
-	Logo      string       `db:"logo" goqu:"omitempty"`
-	CreatedAt time.Time    `db:"created_at" goqu:"omitempty"`
-	DeletedAt sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+	ID            string       `db:"id" goqu:"omitempty"`
+	Name          string       `db:"name" goqu:"omitempty"`
+	Address       string       `db:"address" goqu:"omitempty"`
+	Phone         string       `db:"phone" goqu:"omitempty"`
+	Logo          string       `db:"logo" goqu:"omitempty"`
+	EnergyFormula string       `db:"energy_formula" goqu:"omitempty"`
+	CreatedAt     time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt     sql.NullTime `db:"deleted_at" goqu:"omitempty"`
 }
@@ -1,11 +1,18 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"monorepo/internal/dto"
+	"monorepo/internal/storage"
 	"monorepo/pkg/common"
+	"monorepo/pkg/imaging"
+	"monorepo/pkg/phone"
+	"monorepo/pkg/utils"
 	"monorepo/services/clinic/models"
+	"strings"
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
@@ -14,12 +21,19 @@ import (
 	"github.com/oklog/ulid/v2"
 )
 
+const logoMaxBytes = 2 << 20
+
+// logoSize is the longest side of a stored clinic logo
+const logoSize = 512
+
 func NewClinicService(
 	tbCLinic common.Repository[models.Clinic, string],
 	tbLocation common.Repository[models.Location, string],
+	store storage.Storage,
 ) *CLinicService {
 	service := &CLinicService{}
 	service.validate = validator.New()
+	service.storage = store
 	service.tables.clinic = tbCLinic
 	service.tables.location = tbLocation
 
@@ -28,6 +42,7 @@ func NewClinicService(
 
 type CLinicService struct {
 	validate *validator.Validate
+	storage  storage.Storage
 	tables   struct {
 		clinic   common.Repository[models.Clinic, string]
 		location common.Repository[models.Location, string]
@@ -67,13 +82,18 @@ func (service *CLinicService) IsClinicExistsByID(ctx context.Context, id string)
 }
 
 func (service *CLinicService) CreateClinic(ctx context.Context, body *dto.RequestCreateClinic) (*dto.ResponseCreateClinic, error) {
+	phoneNumber, err := phone.Normalize("", body.Phone)
+	if err != nil {
+		return nil, err
+	}
+
 	newClinic := &models.Clinic{
-		ID:        ulid.Make().String(),
-		Name:      body.Name,
-		Address:   body.Address,
-		Phone:     body.Phone,
-		Logo:      body.Logo,
-		CreatedAt: time.Now(),
+		ID:            ulid.Make().String(),
+		Name:          body.Name,
+		Address:       body.Address,
+		Phone:         phoneNumber,
+		CreatedAt:     time.Now(),
+		EnergyFormula: body.EnergyFormula,
 	}
 
 	isExist, err := service.IsClinicExists(ctx, newClinic.Name)
@@ -91,11 +111,11 @@ func (service *CLinicService) CreateClinic(ctx context.Context, body *dto.Reques
 	}
 
 	res := dto.ResponseCreateClinic{
-		ID:      newClinic.ID,
-		Name:    newClinic.Name,
-		Address: newClinic.Address,
-		Phone:   newClinic.Phone,
-		Logo:    newClinic.Logo,
+		ID:            newClinic.ID,
+		Name:          newClinic.Name,
+		Address:       newClinic.Address,
+		Phone:         newClinic.Phone,
+		EnergyFormula: newClinic.EnergyFormula,
 	}
 
 	return &res, nil
@@ -118,8 +138,11 @@ func (service *CLinicService) UpdateClinic(ctx context.Context, id string, body
 
 	clinic.Name = body.Name
 	clinic.Address = body.Address
-	clinic.Phone = body.Phone
-	clinic.Logo = body.Logo
+	clinic.EnergyFormula = utils.Ternary(body.EnergyFormula != "", body.EnergyFormula, clinic.EnergyFormula)
+	clinic.Phone, err = phone.Normalize("", body.Phone)
+	if err != nil {
+		return nil, err
+	}
 
 	err = service.tables.clinic.Update(ctx, clinic.ID, clinic)
 	if err != nil {
@@ -127,11 +150,12 @@ func (service *CLinicService) UpdateClinic(ctx context.Context, id string, body
 	}
 
 	res := dto.ResponseUpdateClinic{
-		ID:      clinic.ID,
-		Name:    clinic.Name,
-		Address: clinic.Address,
-		Phone:   clinic.Phone,
-		Logo:    clinic.Logo,
+		ID:            clinic.ID,
+		Name:          clinic.Name,
+		Address:       clinic.Address,
+		Phone:         clinic.Phone,
+		Logo:          service.logoURL(clinic.Logo),
+		EnergyFormula: clinic.EnergyFormula,
 	}
 
 	return &res, nil
@@ -155,7 +179,7 @@ func (service *CLinicService) GetClinic(ctx context.Context, id string) (*dto.Re
 	clinic, err := service.tables.clinic.Get(ctx, id)
 	if err != nil {
 		if err == ErrNoResult {
-			return nil, fmt.Errorf("%w", "data not found")
+			return nil, fmt.Errorf("%w", ErrClinicNotFound)
 		}
 		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
 	}
@@ -165,7 +189,8 @@ func (service *CLinicService) GetClinic(ctx context.Context, id string) (*dto.Re
 	res.Name = clinic.Name
 	res.Address = clinic.Address
 	res.Phone = clinic.Phone
-	res.Logo = clinic.Logo
+	res.Logo = service.logoURL(clinic.Logo)
+	res.EnergyFormula = clinic.EnergyFormula
 	res.CreatedAt = clinic.CreatedAt
 
 	if clinic.DeletedAt.Valid {
@@ -194,7 +219,8 @@ func (service *CLinicService) GetAllClinic(ctx context.Context, filter dto.Filte
 		c.Name = clinic.Name
 		c.Address = clinic.Address
 		c.Phone = clinic.Phone
-		c.Logo = clinic.Logo
+		c.Logo = service.logoURL(clinic.Logo)
+		c.EnergyFormula = clinic.EnergyFormula
 		c.CreatedAt = clinic.CreatedAt
 
 		if clinic.DeletedAt.Valid {
@@ -206,3 +232,55 @@ func (service *CLinicService) GetAllClinic(ctx context.Context, filter dto.Filte
 
 	return res, nil
 }
+
+// UploadLogo validates the image, strips its metadata and stores it under a
+// content-addressed key. Logos are public.
+func (service *CLinicService) UploadLogo(ctx context.Context, id string, file io.Reader) (*dto.ResponseUpdateClinic, error) {
+	clinic, err := service.tables.clinic.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	data, err := imaging.ReadAll(file, logoMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := imaging.EncodePNG(imaging.Fit(img, logoSize))
+	if err != nil {
+		return nil, err
+	}
+
+	key := storage.ContentKey("clinic-logos", data) + ".png"
+	err = service.storage.Put(ctx, key, bytes.NewReader(content), "image/png", false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.tables.clinic.Update(ctx, clinic.ID, &models.Clinic{Logo: key})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return &dto.ResponseUpdateClinic{
+		ID:      clinic.ID,
+		Name:    clinic.Name,
+		Address: clinic.Address,
+		Phone:   clinic.Phone,
+		Logo:    service.logoURL(key),
+	}, nil
+}
+
+// logoURL resolves a stored logo key, logos saved before the upload endpoint are plain urls.
+func (service *CLinicService) logoURL(logo string) string {
+	if logo == "" || strings.HasPrefix(logo, "http://") || strings.HasPrefix(logo, "https://") {
+		return logo
+	}
+
+	return service.storage.URL(logo)
+}
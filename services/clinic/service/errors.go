@@ -12,5 +12,6 @@ var (
 	ErrClinicExist          = errors.New("data clinic is already exists")
 	ErrClinicNotFound       = errors.New("data clinic not found")
 	ErrLocationExist        = errors.New("data location is already exists")
+	ErrLocationNotFound     = errors.New("data location not found")
 	ErrNoResult             = repository.ErrNoResult
 )
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"monorepo/internal/dto"
 	"monorepo/pkg/common"
+	"monorepo/pkg/phone"
 	"monorepo/services/clinic/models"
 	"time"
 
@@ -36,12 +37,17 @@ func (service *CLinicService) IsLocationExists(ctx context.Context, clinicID, na
 }
 
 func (service *CLinicService) CreateLocation(ctx context.Context, body *dto.RequestCreateLocation) (*dto.ResponseCreateLocation, error) {
+	phoneNumber, err := phone.Normalize("", body.Phone)
+	if err != nil {
+		return nil, err
+	}
+
 	newLocation := &models.Location{
 		ID:          ulid.Make().String(),
 		ClinicID:    body.ClinicID,
 		Name:        body.Name,
 		Address:     body.Address,
-		Phone:       body.Phone,
+		Phone:       phoneNumber,
 		Capacity:    body.Capacity,
 		OpeningTime: body.OpeningTime,
 		ClosingTime: body.ClosingTime,
@@ -111,7 +117,10 @@ func (service *CLinicService) UpdateLocation(ctx context.Context, locID string,
 	location.ClinicID = body.ClinicID
 	location.Name = body.Name
 	location.Address = body.Address
-	location.Phone = body.Phone
+	location.Phone, err = phone.Normalize("", body.Phone)
+	if err != nil {
+		return nil, err
+	}
 	location.OpeningTime = body.OpeningTime
 	location.ClosingTime = body.ClosingTime
 	location.Capacity = body.Capacity
@@ -153,7 +162,7 @@ func (service *CLinicService) GetLocation(ctx context.Context, id string) (*dto.
 	location, err := service.tables.location.Get(ctx, id)
 	if err != nil {
 		if err == ErrNoResult {
-			return nil, fmt.Errorf("%w", "data not found")
+			return nil, fmt.Errorf("%w", ErrLocationNotFound)
 		}
 		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
 	}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"monorepo/internal/dto"
+	"monorepo/services/fitness/service"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+func (rest *REST) PutBodyMeasurement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	var req dto.PutBodyMeasurementRequest
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := rest.bodyService.PutBodyMeasurement(ctx, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to record body measurement"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.BodyMeasurementResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) GetBodyMeasurements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		page, _  = strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	)
+
+	if page == 0 {
+		page = 1
+	}
+
+	if limit == 0 {
+		limit = 50
+	}
+
+	data, err := rest.bodyService.ListBodyMeasurements(ctx, dto.FilterGetBodyMeasurements{
+		DateFrom: r.URL.Query().Get("from"),
+		DateTo:   r.URL.Query().Get("to"),
+		Page:     page,
+		Limit:    limit,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to get body measurements"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[[]dto.BodyMeasurementResponse]{Data: &data, Message: "OK"})
+}
+
+func (rest *REST) DeleteBodyMeasurement(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	err := rest.bodyService.DeleteBodyMeasurement(ctx, chi.URLParam(r, "id"))
+	if errors.Is(err, service.ErrBodyMeasurementNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to delete body measurement"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to delete body measurement"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "OK"})
+}
+
+func (rest *REST) GetBodyComposition(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := rest.bodyService.BodyComposition(ctx, r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to get body composition"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.BodyCompositionResponse]{Data: data, Message: "OK"})
+}
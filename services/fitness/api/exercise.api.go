@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"monorepo/internal/dto"
+	"monorepo/services/fitness/service"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+func (rest *REST) ListExerciseActivities(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	data := rest.exerciseService.ListActivities()
+	json.NewEncoder(w).Encode(dto.Object[[]dto.ExerciseActivityResponse]{Data: &data, Message: "OK"})
+}
+
+func (rest *REST) CreateExerciseLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	var req dto.CreateExerciseLogRequest
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := rest.exerciseService.LogExercise(ctx, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to log exercise"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ExerciseLogResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) GetExerciseLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := rest.exerciseService.ListExercise(ctx, r.URL.Query().Get("date"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to get exercise log"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[[]dto.ExerciseLogResponse]{Data: &data, Message: "OK"})
+}
+
+func (rest *REST) GetExerciseWeek(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := rest.exerciseService.ExerciseWeek(ctx, r.URL.Query().Get("date"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to get weekly exercise"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ExerciseWeekResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) DeleteExerciseLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	err := rest.exerciseService.DeleteExercise(ctx, chi.URLParam(r, "id"))
+	if errors.Is(err, service.ErrExerciseNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to delete exercise"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to delete exercise"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "OK"})
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"monorepo/internal/dto"
+	"monorepo/services/fitness/service"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+func (rest *REST) SearchFoods(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	var (
+		page, _  = strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	)
+
+	if page == 0 {
+		page = 1
+	}
+
+	data, err := rest.foodService.SearchFoods(ctx, dto.FilterSearchFoods{
+		Query:    r.URL.Query().Get("q"),
+		Category: r.URL.Query().Get("category"),
+		Page:     page,
+		Limit:    limit,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to search foods"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[[]dto.FoodResponse]{Data: &data, Message: "OK"})
+}
+
+func (rest *REST) CreateFood(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	var req dto.CreateFoodRequest
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := rest.foodService.CreateFood(ctx, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to create food"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.FoodResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) DeleteFood(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	err := rest.foodService.DeleteFood(ctx, chi.URLParam(r, "id"))
+	if errors.Is(err, service.ErrFoodNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to delete food"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to delete food"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "OK"})
+}
+
+func (rest *REST) ImportFoods(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	r.ParseMultipartForm(10 << 20)
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := rest.foodService.ImportCatalog(ctx, file)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to import foods"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.FoodImportResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) CreateFoodDiary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	var req dto.CreateFoodDiaryRequest
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := rest.foodService.LogFood(ctx, req)
+	if errors.Is(err, service.ErrFoodNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to log food"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to log food"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.FoodDiaryResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) GetFoodDiary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := rest.foodService.ListFoodDiary(ctx, r.URL.Query().Get("date"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to get food diary"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[[]dto.FoodDiaryResponse]{Data: &data, Message: "OK"})
+}
+
+func (rest *REST) GetFoodDiarySummary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := rest.foodService.FoodDiarySummary(ctx, r.URL.Query().Get("date"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to get food diary summary"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.FoodDiarySummaryResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) UpdateFoodDiary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	var req dto.UpdateFoodDiaryRequest
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := rest.foodService.UpdateFoodDiary(ctx, chi.URLParam(r, "id"), req)
+	if errors.Is(err, service.ErrFoodDiaryNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to update food diary"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to update food diary"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.FoodDiaryResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) DeleteFoodDiary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	err := rest.foodService.DeleteFoodDiary(ctx, chi.URLParam(r, "id"))
+	if errors.Is(err, service.ErrFoodDiaryNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to delete food diary"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to delete food diary"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "OK"})
+}
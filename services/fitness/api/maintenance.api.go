@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"monorepo/internal/dto"
+	"monorepo/services/fitness/service"
+	"net/http"
+	"strconv"
+)
+
+func (rest *REST) GetMaintenanceEstimate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := rest.weightGoalService.EstimateMaintenance(ctx)
+	if errors.Is(err, service.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to estimate calories to maintain"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to estimate calories to maintain"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.MaintenanceEstimateResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) ApplyMaintenanceEstimate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := rest.weightGoalService.ApplyMaintenance(ctx)
+	if errors.Is(err, service.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to recalibrate weight goal"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to recalibrate weight goal"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.CalorieAdjustmentResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) GetCalorieAdjustments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+
+	data, err := rest.weightGoalService.ListCalorieAdjustments(ctx, page, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to get calorie adjustments"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[[]dto.CalorieAdjustmentResponse]{Data: &data, Message: "OK"})
+}
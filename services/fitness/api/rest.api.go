@@ -2,9 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
+	"monorepo/internal/acting"
 	"monorepo/internal/config"
+	"monorepo/internal/constants"
 	"monorepo/internal/dto"
+	"monorepo/internal/role"
 	"monorepo/services/fitness/service"
 	"net/http"
 	"strconv"
@@ -22,12 +26,18 @@ type REST struct {
 
 	decoder           *schema.Decoder
 	weightGoalService *service.WeightGoalService
+	foodService       *service.FoodService
+	exerciseService   *service.ExerciseService
+	bodyService       *service.BodyService
 	env               *config.Environment
 	oauthAuthorizer   func(next http.Handler) http.Handler
 }
 
 func NewREST(
 	weightGoalService *service.WeightGoalService,
+	foodService *service.FoodService,
+	exerciseService *service.ExerciseService,
+	bodyService *service.BodyService,
 	env *config.Environment,
 ) *REST {
 	r := chi.NewRouter()
@@ -42,6 +52,9 @@ func NewREST(
 		Router:            r,
 		decoder:           schema.NewDecoder(),
 		weightGoalService: weightGoalService,
+		foodService:       foodService,
+		exerciseService:   exerciseService,
+		bodyService:       bodyService,
 		env:               env,
 		oauthAuthorizer:   oauth.Authorize(env.JWTSecret, nil),
 	}
@@ -51,12 +64,43 @@ func (rest *REST) InitializeRoutes() {
 	rest.Router.Get("/", rest.Healthcheck)
 	rest.Router.Group(func(r chi.Router) {
 		r.Use(rest.oauthAuthorizer)
+		r.Use(acting.Middleware)
 		r.Post("/weight-goal", rest.CreateWeightGoal)
 		r.Get("/weight-goal", rest.GetWeightGoal)
 		r.Patch("/weight-goal", rest.UpdateWeightGoal)
 		r.Post("/weight-goal/simulation", rest.WeightGoalSimulation)
+		r.Get("/weight-goal/maintenance", rest.GetMaintenanceEstimate)
+		r.Post("/weight-goal/maintenance/apply", rest.ApplyMaintenanceEstimate)
+		r.Get("/weight-goal/adjustments", rest.GetCalorieAdjustments)
+		r.Post("/weight-goal/maintain", rest.MaintainWeightGoal)
+		r.Post("/weight-goal/abandon", rest.AbandonWeightGoal)
+		r.Get("/weight-goal/history", rest.GetPastWeightGoals)
 		r.Put("/weight-history", rest.PutWeightHistory)
 		r.Get("/weight-history", rest.GetWeightHistories)
+		r.Get("/weight-history/trend", rest.GetWeightTrend)
+		r.Post("/weight-history/import", rest.ImportWeightHistory)
+		r.Get("/foods", rest.SearchFoods)
+		r.Post("/foods", rest.CreateFood)
+		r.Delete("/foods/{id}", rest.DeleteFood)
+		r.Post("/food-diary", rest.CreateFoodDiary)
+		r.Get("/food-diary", rest.GetFoodDiary)
+		r.Get("/food-diary/summary", rest.GetFoodDiarySummary)
+		r.Patch("/food-diary/{id}", rest.UpdateFoodDiary)
+		r.Delete("/food-diary/{id}", rest.DeleteFoodDiary)
+		r.Get("/exercise/activities", rest.ListExerciseActivities)
+		r.Post("/exercise-log", rest.CreateExerciseLog)
+		r.Get("/exercise-log", rest.GetExerciseLog)
+		r.Get("/exercise-log/weekly", rest.GetExerciseWeek)
+		r.Delete("/exercise-log/{id}", rest.DeleteExerciseLog)
+		r.Put("/body-measurements", rest.PutBodyMeasurement)
+		r.Get("/body-measurements", rest.GetBodyMeasurements)
+		r.Delete("/body-measurements/{id}", rest.DeleteBodyMeasurement)
+		r.Get("/body-composition", rest.GetBodyComposition)
+
+		r.Group(func(r chi.Router) {
+			r.Use(role.Authorizer(constants.RoleAdmin))
+			r.Post("/foods/import", rest.ImportFoods)
+		})
 	})
 }
 
@@ -259,6 +303,57 @@ func (rest *REST) PutWeightHistory(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(dto.Object[*dto.WeightHistoryResponse]{Data: &data, Message: "OK"})
 }
 
+const weightImportMaxSize = 10 << 20
+
+func (rest *REST) ImportWeightHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// years of daily weighings fit in well under the limit
+	r.Body = http.MaxBytesReader(w, r.Body, weightImportMaxSize)
+	err := r.ParseMultipartForm(weightImportMaxSize)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+	defer file.Close()
+
+	req := dto.WeightImportRequest{
+		Format:  r.FormValue("format"),
+		Unit:    r.FormValue("unit"),
+		Preview: r.FormValue("preview") == "true",
+	}
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := rest.weightGoalService.ImportWeightHistory(ctx, file, req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to import weight history"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.WeightImportResponse]{Data: data, Message: "OK"})
+}
+
 func (rest *REST) GetWeightHistories(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
@@ -309,3 +404,19 @@ func (rest *REST) GetWeightHistories(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(dto.Object[[]dto.WeightHistoryResponse]{Data: &data, Message: "OK"})
 }
+
+func (rest *REST) GetWeightTrend(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+
+	data, err := rest.weightGoalService.GetWeightTrend(ctx, days)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get Weight Trend"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.WeightTrendResponse]{Data: data, Message: "OK"})
+}
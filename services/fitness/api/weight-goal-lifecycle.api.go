@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"monorepo/internal/dto"
+	"monorepo/services/fitness/service"
+	"net/http"
+	"strconv"
+)
+
+func (rest *REST) MaintainWeightGoal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	data, err := rest.weightGoalService.MaintainWeightGoal(ctx)
+	if err != nil {
+		w.WriteHeader(goalStatusCode(err))
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to maintain weight goal"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.GetWeightGoalResponse]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) AbandonWeightGoal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := rest.weightGoalService.AbandonWeightGoal(ctx); err != nil {
+		w.WriteHeader(goalStatusCode(err))
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to abandon weight goal"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "OK"})
+}
+
+func (rest *REST) GetPastWeightGoals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page == 0 {
+		page = 1
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 20
+	}
+
+	data, err := rest.weightGoalService.ListPastWeightGoals(ctx, page, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to get past weight goals"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[[]dto.WeightGoalSummary]{Data: &data, Message: "OK"})
+}
+
+func goalStatusCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrWeightGoalTransition):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
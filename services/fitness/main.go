@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"monorepo/internal/config"
 	"monorepo/internal/db"
 	"monorepo/internal/repository"
+	"monorepo/pkg/macro"
+	clinicModels "monorepo/services/clinic/models"
 	"monorepo/services/fitness/api"
 	"monorepo/services/fitness/model"
 	"monorepo/services/fitness/service"
@@ -22,6 +25,14 @@ func main() {
 		logrus.Fatalf("Failed to parse environment variables: %v", err)
 	}
 
+	presets, err := macro.ParsePresets(cfg.MacroPresets)
+	if err != nil {
+		logrus.Fatalf("Failed to parse macronutrient presets: %v", err)
+	}
+	for _, preset := range presets {
+		macro.Register(preset)
+	}
+
 	pgdb := db.MustConnectPostgres(&db.PostgresConfig{
 		SSLMode: cfg.DbSslMode,
 		Name:    cfg.DbName,
@@ -33,11 +44,24 @@ func main() {
 
 	tbWeightGoal := repository.NewRepository[model.WeightGoal, string](pgdb, repository.Tables.WeightGoal)
 	tbWeightHistory := repository.NewRepository[model.WeightHistory, string](pgdb, repository.Tables.WeightHistory)
+	tbClinic := repository.NewRepository[clinicModels.Clinic, string](pgdb, repository.Tables.Clinic)
+	tbFood := repository.NewRepository[model.Food, string](pgdb, repository.Tables.Food)
+	tbFoodDiary := repository.NewRepository[model.FoodDiary, string](pgdb, repository.Tables.FoodDiary)
+	tbExerciseLog := repository.NewRepository[model.ExerciseLog, string](pgdb, repository.Tables.ExerciseLog)
+	tbCalorieAdjustment := repository.NewRepository[model.CalorieAdjustment, string](pgdb, repository.Tables.CalorieAdjust)
+	tbBodyMeasurement := repository.NewRepository[model.BodyMeasurement, string](pgdb, repository.Tables.BodyMeasure)
 
 	profileService := service.NewProfileService()
+	foodService := service.NewFoodService(tbFood, tbFoodDiary, tbWeightGoal, tbExerciseLog, profileService)
+	if err := foodService.SeedCatalog(context.Background()); err != nil {
+		logrus.Fatalf("Failed to seed food catalog: %v", err)
+	}
 
 	restAPI := api.NewREST(
-		service.NewWeightGoalService(tbWeightGoal, tbWeightHistory, profileService),
+		service.NewWeightGoalService(tbWeightGoal, tbWeightHistory, tbClinic, tbFoodDiary, tbExerciseLog, tbCalorieAdjustment, profileService),
+		foodService,
+		service.NewExerciseService(tbExerciseLog, tbWeightHistory, tbWeightGoal, profileService),
+		service.NewBodyService(tbBodyMeasurement, tbWeightHistory, profileService),
 		cfg,
 	)
 
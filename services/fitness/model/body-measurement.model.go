@@ -0,0 +1,22 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BodyMeasurement holds the circumferences in cm and the body fat percentage
+// measured on a day, each is nil when it was not measured.
+type BodyMeasurement struct {
+	ID        string       `db:"id" goqu:"omitempty" json:"id"`
+	ProfileID string       `db:"profile_id" goqu:"omitempty" json:"profile_id"`
+	Date      time.Time    `db:"date" goqu:"omitempty" json:"date"`
+	Waist     *float64     `db:"waist" goqu:"omitempty" json:"waist"`
+	Hip       *float64     `db:"hip" goqu:"omitempty" json:"hip"`
+	Neck      *float64     `db:"neck" goqu:"omitempty" json:"neck"`
+	Chest     *float64     `db:"chest" goqu:"omitempty" json:"chest"`
+	BodyFat   *float64     `db:"body_fat" goqu:"omitempty" json:"body_fat"`
+	CreatedAt time.Time    `db:"created_at" goqu:"omitempty" json:"created_at"`
+	UpdatedAt sql.NullTime `db:"updated_at" goqu:"omitempty" json:"updated_at"`
+	DeletedAt sql.NullTime `db:"deleted_at" goqu:"omitempty" json:"deleted_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// CalorieAdjustment records a recalibration of a weight goal from the energy
+// balance, with the figures it was estimated from and the values it replaced.
+type CalorieAdjustment struct {
+	ID                         string       `db:"id" goqu:"omitempty" json:"id"`
+	ProfileID                  string       `db:"profile_id" goqu:"omitempty" json:"profile_id"`
+	WeightGoalID               string       `db:"weight_goal_id" goqu:"omitempty" json:"weight_goal_id"`
+	Source                     string       `db:"source" goqu:"omitempty" json:"source"` // manual | auto
+	Confidence                 string       `db:"confidence" goqu:"omitempty" json:"confidence"`
+	Days                       int          `db:"days" goqu:"omitempty" json:"days"`
+	Intake                     float64      `db:"intake" json:"intake"`
+	IntakeSource               string       `db:"intake_source" goqu:"omitempty" json:"intake_source"` // diary | budget
+	WeightChange               float64      `db:"weight_change" json:"weight_change"`
+	PreviousCaloriesToMaintain float64      `db:"previous_calories_to_maintain" goqu:"omitempty" json:"previous_calories_to_maintain"`
+	CaloriesToMaintain         float64      `db:"calories_to_maintain" goqu:"omitempty" json:"calories_to_maintain"`
+	PreviousDailyCalorieBudget float64      `db:"previous_daily_calories_budget" goqu:"omitempty" json:"previous_daily_calories_budget"`
+	DailyCalorieBudget         float64      `db:"daily_calories_budget" goqu:"omitempty" json:"daily_calories_budget"`
+	CreatedAt                  time.Time    `db:"created_at" goqu:"omitempty" json:"created_at"`
+	UpdatedAt                  sql.NullTime `db:"updated_at" goqu:"omitempty" json:"updated_at"`
+	DeletedAt                  sql.NullTime `db:"deleted_at" goqu:"omitempty" json:"deleted_at"`
+}
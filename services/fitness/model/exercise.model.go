@@ -0,0 +1,24 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ExerciseLog is a workout. The MET and the weight it was calculated with are
+// kept so the calories stay explainable after the weight changes.
+type ExerciseLog struct {
+	ID        string       `db:"id" goqu:"omitempty" json:"id"`
+	ProfileID string       `db:"profile_id" goqu:"omitempty" json:"profile_id"`
+	Activity  string       `db:"activity" goqu:"omitempty" json:"activity"`
+	Date      time.Time    `db:"date" goqu:"omitempty" json:"date"`
+	Duration  int          `db:"duration" goqu:"omitempty" json:"duration"` // minutes
+	Intensity string       `db:"intensity" goqu:"omitempty" json:"intensity"`
+	Distance  float64      `db:"distance" goqu:"omitempty" json:"distance"` // km
+	MET       float64      `db:"met" goqu:"omitempty" json:"met"`
+	Weight    float64      `db:"weight" goqu:"omitempty" json:"weight"`
+	Calories  float64      `db:"calories" goqu:"omitempty" json:"calories"`
+	CreatedAt time.Time    `db:"created_at" goqu:"omitempty" json:"created_at"`
+	UpdatedAt sql.NullTime `db:"updated_at" goqu:"omitempty" json:"updated_at"`
+	DeletedAt sql.NullTime `db:"deleted_at" goqu:"omitempty" json:"deleted_at"`
+}
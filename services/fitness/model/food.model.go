@@ -0,0 +1,49 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Food nutrients are per 100 g. Catalog foods have no profile and use the
+// catalog code as id so an import updates them in place, custom foods belong
+// to the profile that created them.
+type Food struct {
+	ID           string       `db:"id" goqu:"omitempty" json:"id"`
+	ProfileID    string       `db:"profile_id" goqu:"omitempty" json:"profile_id"`
+	Source       string       `db:"source" goqu:"omitempty" json:"source"` // catalog | custom
+	Name         string       `db:"name" goqu:"omitempty" json:"name"`
+	Category     string       `db:"category" goqu:"omitempty" json:"category"`
+	ServingSize  float64      `db:"serving_size" goqu:"omitempty" json:"serving_size"`
+	ServingUnit  string       `db:"serving_unit" goqu:"omitempty" json:"serving_unit"`
+	Energy       float64      `db:"energy" goqu:"omitempty" json:"energy"`
+	Protein      float64      `db:"protein" goqu:"omitempty" json:"protein"`
+	Carbohydrate float64      `db:"carbohydrate" goqu:"omitempty" json:"carbohydrate"`
+	Fat          float64      `db:"fat" goqu:"omitempty" json:"fat"`
+	Fibre        float64      `db:"fibre" goqu:"omitempty" json:"fibre"`
+	CreatedAt    time.Time    `db:"created_at" goqu:"omitempty" json:"created_at"`
+	UpdatedAt    sql.NullTime `db:"updated_at" goqu:"omitempty" json:"updated_at"`
+	DeletedAt    sql.NullTime `db:"deleted_at" goqu:"omitempty" json:"deleted_at"`
+}
+
+// FoodDiary is a meal entry. The food name and nutrients are copied when the
+// entry is logged, so later changes to the food keep past days as they were.
+type FoodDiary struct {
+	ID           string       `db:"id" goqu:"omitempty" json:"id"`
+	ProfileID    string       `db:"profile_id" goqu:"omitempty" json:"profile_id"`
+	FoodID       string       `db:"food_id" goqu:"omitempty" json:"food_id"`
+	FoodName     string       `db:"food_name" goqu:"omitempty" json:"food_name"`
+	Date         time.Time    `db:"date" goqu:"omitempty" json:"date"`
+	Meal         string       `db:"meal" goqu:"omitempty" json:"meal"` // breakfast | lunch | dinner | snack
+	Amount       float64      `db:"amount" goqu:"omitempty" json:"amount"`
+	Unit         string       `db:"unit" goqu:"omitempty" json:"unit"` // g | ml | serving
+	Grams        float64      `db:"grams" goqu:"omitempty" json:"grams"`
+	Energy       float64      `db:"energy" goqu:"omitempty" json:"energy"`
+	Protein      float64      `db:"protein" goqu:"omitempty" json:"protein"`
+	Carbohydrate float64      `db:"carbohydrate" goqu:"omitempty" json:"carbohydrate"`
+	Fat          float64      `db:"fat" goqu:"omitempty" json:"fat"`
+	Fibre        float64      `db:"fibre" goqu:"omitempty" json:"fibre"`
+	CreatedAt    time.Time    `db:"created_at" goqu:"omitempty" json:"created_at"`
+	UpdatedAt    sql.NullTime `db:"updated_at" goqu:"omitempty" json:"updated_at"`
+	DeletedAt    sql.NullTime `db:"deleted_at" goqu:"omitempty" json:"deleted_at"`
+}
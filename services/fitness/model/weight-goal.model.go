@@ -17,6 +17,18 @@ type WeightGoal struct {
 	Flag               string       `db:"flag" goqu:"omitempty" json:"flag"` // gain | loss | maintain
 	ActivityLevel      string       `db:"activity_level" goqu:"omitempty" json:"activity_level"`
 	Pace               string       `db:"pace" goqu:"omitempty" json:"pace"`
+	Formula            string       `db:"formula" goqu:"omitempty" json:"formula"`
+	BodyFat            *float64     `db:"body_fat" goqu:"omitempty" json:"body_fat"`
+	MacroPreset        string       `db:"macro_preset" goqu:"omitempty" json:"macro_preset"`
+	ProteinTarget      float64      `db:"protein_target" goqu:"omitempty" json:"protein_target"`
+	CarbohydrateTarget float64      `db:"carbohydrate_target" goqu:"omitempty" json:"carbohydrate_target"`
+	FatTarget          float64      `db:"fat_target" goqu:"omitempty" json:"fat_target"`
+	FibreTarget        float64      `db:"fibre_target" goqu:"omitempty" json:"fibre_target"`
+	AutoCalibrate      *bool        `db:"auto_calibrate" goqu:"omitempty" json:"auto_calibrate"`
+	Status             string       `db:"status" goqu:"omitempty" json:"status"` // active | achieved | maintaining | abandoned | superseded
+	AchievedAt         sql.NullTime `db:"achieved_at" goqu:"omitempty" json:"achieved_at"`
+	EndedAt            sql.NullTime `db:"ended_at" goqu:"omitempty" json:"ended_at"`
+	EndWeight          float64      `db:"end_weight" goqu:"omitempty" json:"end_weight"`
 	CreatedAt          time.Time    `db:"created_at" goqu:"omitempty" json:"created_at"`
 	UpdatedAt          sql.NullTime `db:"updated_at" goqu:"omitempty" json:"updated_at"`
 	DeletedAt          sql.NullTime `db:"deleted_at" goqu:"omitempty" json:"deleted_at"`
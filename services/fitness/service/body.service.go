@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"monorepo/internal/dto"
+	"monorepo/pkg/body"
+	"monorepo/pkg/common"
+	"monorepo/services/fitness/model"
+	"sort"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	bodyCompositionDays    = 90
+	bodyCompositionMaxDays = 366
+
+	// the current composition folds the latest of each measurement from
+	// this many measurement days
+	bodyCurrentLimit = 50
+
+	bodyFatMeasured = "measured"
+	bodyFatNavy     = "navy"
+)
+
+func NewBodyService(
+	tbBodyMeasurement common.Repository[model.BodyMeasurement, string],
+	tbWeightHistory common.Repository[model.WeightHistory, string],
+	profileService ProfileServiceInterface,
+) *BodyService {
+	service := &BodyService{}
+	service.tables.bodyMeasurement = tbBodyMeasurement
+	service.tables.weightHistory = tbWeightHistory
+	service.profileService = profileService
+
+	return service
+}
+
+// BodyService keeps a history of body measurements, one row a day like the
+// weight history, and derives the body composition from them.
+type BodyService struct {
+	tables struct {
+		bodyMeasurement common.Repository[model.BodyMeasurement, string]
+		weightHistory   common.Repository[model.WeightHistory, string]
+	}
+	profileService ProfileServiceInterface
+}
+
+// PutBodyMeasurement records the measurements of a day, merged into the ones
+// already recorded that day.
+func (service *BodyService) PutBodyMeasurement(ctx context.Context, req dto.PutBodyMeasurementRequest) (*dto.BodyMeasurementResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	day, err := parseDay(req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := service.tables.bodyMeasurement.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profile.ID),
+			goqu.C("date").Eq(day.Format(shortdDateLayout)),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	measurement := &model.BodyMeasurement{
+		Waist:   req.Waist,
+		Hip:     req.Hip,
+		Neck:    req.Neck,
+		Chest:   req.Chest,
+		BodyFat: req.BodyFat,
+	}
+
+	if len(existing) > 0 {
+		measurement.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		if err := service.tables.bodyMeasurement.Update(ctx, existing[0].ID, measurement); err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+
+		measurement, err = service.tables.bodyMeasurement.Get(ctx, existing[0].ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+		}
+	} else {
+		measurement.ID = ulid.Make().String()
+		measurement.ProfileID = profile.ID
+		measurement.Date = day
+		measurement.CreatedAt = time.Now()
+		if err := service.tables.bodyMeasurement.Create(ctx, measurement); err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+	}
+
+	res := bodyMeasurementResponse(measurement)
+	return &res, nil
+}
+
+func (service *BodyService) ListBodyMeasurements(ctx context.Context, filter dto.FilterGetBodyMeasurements) ([]dto.BodyMeasurementResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	where := []exp.Expression{goqu.C("profile_id").Eq(profile.ID)}
+	if filter.DateFrom != "" {
+		where = append(where, goqu.C("date").Gte(filter.DateFrom))
+	}
+	if filter.DateTo != "" {
+		where = append(where, goqu.C("date").Lte(filter.DateTo))
+	}
+
+	measurements, err := service.tables.bodyMeasurement.List(ctx, &common.FilterOptions{
+		Filter: where,
+		Sort:   []exp.OrderedExpression{goqu.I("date").Desc()},
+		Page:   filter.Page,
+		Limit:  filter.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	res := make([]dto.BodyMeasurementResponse, 0, len(measurements))
+	for _, m := range measurements {
+		res = append(res, bodyMeasurementResponse(m))
+	}
+
+	return res, nil
+}
+
+func (service *BodyService) DeleteBodyMeasurement(ctx context.Context, id string) error {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	measurement, err := service.tables.bodyMeasurement.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) {
+		return ErrBodyMeasurementNotFound
+	} else if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if measurement.ProfileID != profile.ID {
+		return ErrBodyMeasurementNotFound
+	}
+
+	if err := service.tables.bodyMeasurement.Delete(ctx, id); err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// BodyComposition returns the composition series between from and to, the 90
+// days up to today when not given, along with the current composition.
+func (service *BodyService) BodyComposition(ctx context.Context, from, to string) (*dto.BodyCompositionResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	end, err := parseDay(to)
+	if err != nil {
+		return nil, err
+	}
+
+	start := end.AddDate(0, 0, -bodyCompositionDays)
+	if from != "" {
+		if start, err = parseDay(from); err != nil {
+			return nil, err
+		}
+	}
+
+	if end.Sub(start) > bodyCompositionMaxDays*24*time.Hour || start.After(end) {
+		return nil, ErrInvalidDateRange
+	}
+
+	measurements, err := service.tables.bodyMeasurement.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profile.ID),
+			goqu.C("date").Between(goqu.Range(start.Format(shortdDateLayout), end.Format(shortdDateLayout))),
+		},
+		Sort:  []exp.OrderedExpression{goqu.I("date").Asc()},
+		Page:  1,
+		Limit: bodyCompositionMaxDays + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	histories, err := service.tables.weightHistory.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profile.ID),
+			goqu.L("DATE(created_at)").Between(goqu.Range(start.Format(shortdDateLayout), end.Format(shortdDateLayout))),
+		},
+		Sort:  []exp.OrderedExpression{goqu.I("created_at").Asc()},
+		Page:  1,
+		Limit: bodyCompositionMaxDays + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	res := &dto.BodyCompositionResponse{
+		Height: profile.Height,
+		Series: bodyCompositionSeries(profile.Sex, profile.Height, histories, measurements),
+	}
+
+	latest, err := service.tables.bodyMeasurement.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("profile_id").Eq(profile.ID)},
+		Sort:   []exp.OrderedExpression{goqu.I("date").Desc()},
+		Page:   1,
+		Limit:  bodyCurrentLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	weight, err := latestWeight(ctx, service.tables.weightHistory, profile)
+	if err != nil && !errors.Is(err, ErrWeightUnknown) {
+		return nil, err
+	}
+
+	if len(latest) > 0 || weight > 0 {
+		current := bodyComposition(profile.Sex, profile.Height, time.Now(), weight, foldMeasurements(latest))
+		res.Current = &current
+	}
+
+	return res, nil
+}
+
+// bodyCompositionSeries has a point for every day with a weighing or a
+// measurement, sorted by date.
+func bodyCompositionSeries(sex string, height float64, histories []*model.WeightHistory, measurements []*model.BodyMeasurement) []dto.BodyComposition {
+	weights := map[string]float64{}
+	for _, wh := range histories {
+		weights[wh.CreatedAt.UTC().Format(shortdDateLayout)] = wh.Weight
+	}
+
+	byDay := map[string]*model.BodyMeasurement{}
+	for _, m := range measurements {
+		byDay[m.Date.UTC().Format(shortdDateLayout)] = m
+	}
+
+	days := make([]string, 0, len(weights)+len(byDay))
+	for day := range weights {
+		days = append(days, day)
+	}
+	for day := range byDay {
+		if _, ok := weights[day]; !ok {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+
+	series := make([]dto.BodyComposition, 0, len(days))
+	for _, day := range days {
+		date, _ := time.Parse(shortdDateLayout, day)
+		series = append(series, bodyComposition(sex, height, date, weights[day], byDay[day]))
+	}
+
+	return series
+}
+
+// bodyComposition derives what the measurements allow, m may be nil.
+func bodyComposition(sex string, height float64, date time.Time, weight float64, m *model.BodyMeasurement) dto.BodyComposition {
+	res := dto.BodyComposition{Date: date.Format(shortdDateLayout), Weight: weight}
+	if bmi, err := body.BMI(weight, height); err == nil {
+		res.BMI = bmi
+		res.BMIClass = body.BMIClass(bmi)
+	}
+
+	if m == nil {
+		return res
+	}
+
+	res.Waist, res.Hip, res.Neck, res.Chest = m.Waist, m.Hip, m.Neck, m.Chest
+	waist, hip, neck := valueOf(m.Waist), valueOf(m.Hip), valueOf(m.Neck)
+
+	res.WaistToHeight, res.WaistToHeightRisk, _ = body.WaistToHeight(waist, height)
+	res.WaistToHip, res.WaistToHipRisk, _ = body.WaistToHip(sex, waist, hip)
+
+	if m.BodyFat != nil {
+		res.BodyFat = *m.BodyFat
+		res.BodyFatSource = bodyFatMeasured
+	} else if fat, err := body.NavyBodyFat(sex, height, waist, neck, hip); err == nil {
+		res.BodyFat = fat
+		res.BodyFatSource = bodyFatNavy
+	}
+
+	return res
+}
+
+// foldMeasurements takes the latest of each measurement from measurements
+// sorted latest first, nil when there are none.
+func foldMeasurements(measurements []*model.BodyMeasurement) *model.BodyMeasurement {
+	if len(measurements) == 0 {
+		return nil
+	}
+
+	res := &model.BodyMeasurement{}
+	for _, m := range measurements {
+		for _, f := range []struct{ dst, src **float64 }{
+			{&res.Waist, &m.Waist},
+			{&res.Hip, &m.Hip},
+			{&res.Neck, &m.Neck},
+			{&res.Chest, &m.Chest},
+			{&res.BodyFat, &m.BodyFat},
+		} {
+			if *f.dst == nil {
+				*f.dst = *f.src
+			}
+		}
+	}
+
+	return res
+}
+
+func bodyMeasurementResponse(m *model.BodyMeasurement) dto.BodyMeasurementResponse {
+	return dto.BodyMeasurementResponse{
+		ID:      m.ID,
+		Date:    m.Date.Format(shortdDateLayout),
+		Waist:   m.Waist,
+		Hip:     m.Hip,
+		Neck:    m.Neck,
+		Chest:   m.Chest,
+		BodyFat: m.BodyFat,
+	}
+}
+
+func valueOf(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+
+	return *v
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"monorepo/internal/dto"
+	"monorepo/pkg/body"
+	"monorepo/services/fitness/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func Test_bodyCompositionSeries(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, time.March, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	histories := []*model.WeightHistory{
+		{Weight: 81, CreatedAt: day(1).Add(7 * time.Hour)},
+		{Weight: 80, CreatedAt: day(3).Add(7 * time.Hour)},
+	}
+
+	measurements := []*model.BodyMeasurement{
+		{Date: day(2), Waist: ptr(90), Hip: ptr(100), BodyFat: ptr(20)},
+		{Date: day(3), Waist: ptr(90), Neck: ptr(38)},
+	}
+
+	tests := []struct {
+		name         string
+		histories    []*model.WeightHistory
+		measurements []*model.BodyMeasurement
+		want         []dto.BodyComposition
+	}{
+		{
+			name: "Without weighings or measurements",
+			want: []dto.BodyComposition{},
+		},
+		{
+			name:         "Weighings and measurements",
+			histories:    histories,
+			measurements: measurements,
+			want: []dto.BodyComposition{
+				{Date: "2024-03-01", Weight: 81, BMI: 25, BMIClass: body.Obese1},
+				{
+					Date: "2024-03-02", Waist: ptr(90), Hip: ptr(100),
+					WaistToHeight: 0.5, WaistToHeightRisk: true, WaistToHip: 0.9, WaistToHipRisk: true,
+					BodyFat: 20, BodyFatSource: bodyFatMeasured,
+				},
+				{
+					Date: "2024-03-03", Weight: 80, BMI: 24.7, BMIClass: body.Overweight, Waist: ptr(90), Neck: ptr(38),
+					WaistToHeight: 0.5, WaistToHeightRisk: true,
+					BodyFat: 19.8, BodyFatSource: bodyFatNavy,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bodyCompositionSeries(body.SexMale, 180, tt.histories, tt.measurements)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bodyCompositionSeries() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_foldMeasurements(t *testing.T) {
+	tests := []struct {
+		name         string
+		measurements []*model.BodyMeasurement
+		want         *model.BodyMeasurement
+	}{
+		{
+			name: "Without measurements",
+		},
+		{
+			name: "Latest of each measurement",
+			measurements: []*model.BodyMeasurement{
+				{Waist: ptr(88)},
+				{Waist: ptr(90), Hip: ptr(100), Neck: ptr(38)},
+				{Neck: ptr(40), BodyFat: ptr(22)},
+			},
+			want: &model.BodyMeasurement{Waist: ptr(88), Hip: ptr(100), Neck: ptr(38), BodyFat: ptr(22)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := foldMeasurements(tt.measurements); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("foldMeasurements() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
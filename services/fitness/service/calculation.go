@@ -3,40 +3,56 @@ package service
 import (
 	"math"
 	"monorepo/internal/constants"
+	"monorepo/pkg/energy"
 	"strings"
 	"time"
 )
 
-// Calculate BMR (basal metabolic rate) using Harris Benedict formula
-func CalculateBMR(gender string, weight, height float64, age int) float64 {
-	if strings.ToLower(gender) == constants.GenderMale {
-		return 66.5 + (13.75 * weight) + (5.003 * height) - (6.75 * float64(age))
-	} else if strings.ToLower(gender) == constants.GenderFemale {
-		return 655.1 + (9.563 * weight) + (1.850 * height) - (4.676 * float64(age))
-	}
-
-	return 0.0
+// Calculate BMR (basal metabolic rate) with the given formula
+func CalculateBMR(formula energy.Formula, subject energy.Subject) (float64, error) {
+	return formula.BMR(subject)
 }
 
-// Calculating daily calorie budget based on activity level
-func CalculateCalorieToMaintain(gender string, weight, height float64, age int, activityLevel string) float64 {
-	bmr := CalculateBMR(gender, weight, height, age)
-
-	var activityFactor float64
+// Multiplier of the BMR for the activity level, sedentary when unknown
+func ActivityFactor(activityLevel string) float64 {
 	switch activityLevel {
-	case constants.ActivityLevelSedentary:
-		activityFactor = constants.ActivityLevelSedentaryVal
 	case constants.ActivityLevelLightActive:
-		activityFactor = constants.ActivityLevelModerateActiveVal
+		return constants.ActivityLevelLightActiveVal
 	case constants.ActivityLevelModerateActive:
-		activityFactor = constants.ActivityLevelLightActiveVal
+		return constants.ActivityLevelModerateActiveVal
 	case constants.ActivityLevelVeryActive:
-		activityFactor = constants.ActivityLevelVeryActiveVal
+		return constants.ActivityLevelVeryActiveVal
 	default:
-		activityFactor = constants.ActivityLevelSedentaryVal
+		return constants.ActivityLevelSedentaryVal
+	}
+}
+
+// Activity factor implied by the net calories burned in a week of workouts on
+// top of a sedentary day, and the activity level closest to it
+func SuggestActivityLevel(bmr, weeklyCalories float64) (float64, string) {
+	factor := constants.ActivityLevelSedentaryVal
+	if bmr > 0 {
+		factor += weeklyCalories / 7 / bmr
+	}
+
+	level := constants.ActivityLevelSedentary
+	for _, l := range []string{constants.ActivityLevelLightActive, constants.ActivityLevelModerateActive, constants.ActivityLevelVeryActive} {
+		if math.Abs(ActivityFactor(l)-factor) < math.Abs(ActivityFactor(level)-factor) {
+			level = l
+		}
+	}
+
+	return math.Round(factor*1000) / 1000, level
+}
+
+// Calculating daily calorie budget (TDEE) based on activity level
+func CalculateCalorieToMaintain(formula energy.Formula, subject energy.Subject, activityLevel string) (float64, error) {
+	bmr, err := CalculateBMR(formula, subject)
+	if err != nil {
+		return 0, err
 	}
 
-	return bmr * activityFactor
+	return bmr * ActivityFactor(activityLevel), nil
 }
 
 // Calciulate daily deficit or surplus calorie
@@ -6,11 +6,22 @@ import (
 )
 
 var (
-	ErrRepositoryQueryFail  = errors.New("failed to fetch data from repository")
-	ErrRepositoryMutateFail = errors.New("failed to mutate data to repository")
-	ErrNoResult             = repository.ErrNoResult
-	ErrGetProfile           = errors.New("failed to get profile")
-	ErrUpdateProfile        = errors.New("failed to update profile")
-	ErrWeightGoalExist      = errors.New("data weight goal is already exists")
-	ErrNotFound             = errors.New("data not found")
+	ErrRepositoryQueryFail     = errors.New("failed to fetch data from repository")
+	ErrRepositoryMutateFail    = errors.New("failed to mutate data to repository")
+	ErrNoResult                = repository.ErrNoResult
+	ErrGetProfile              = errors.New("failed to get profile")
+	ErrUpdateProfile           = errors.New("failed to update profile")
+	ErrNotFound                = errors.New("data not found")
+	ErrClinicNotFound          = errors.New("clinic not found")
+	ErrFoodNotFound            = errors.New("food not found")
+	ErrFoodDiaryNotFound       = errors.New("food diary entry not found")
+	ErrExerciseNotFound        = errors.New("exercise not found")
+	ErrWeightUnknown           = errors.New("no weight recorded for the profile")
+	ErrInvalidDate             = errors.New("date has to be formatted as yyyy-mm-dd")
+	ErrWeightGoalTransition    = errors.New("weight goal can not change status")
+	ErrWeightGoalNotActive     = errors.New("weight goal is no longer active, start a new one instead")
+	ErrWeightGoalChanged       = errors.New("weight goal changed meanwhile, try again")
+	ErrBodyMeasurementNotFound = errors.New("body measurement not found")
+	ErrInvalidDateRange        = errors.New("date range has to start before it ends and span at most a year")
+	ErrWeightImportTooLarge    = errors.New("import file spans too many days")
 )
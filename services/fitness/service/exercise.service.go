@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"monorepo/internal/dto"
+	"monorepo/pkg/common"
+	"monorepo/pkg/energy"
+	"monorepo/pkg/met"
+	"monorepo/services/fitness/model"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+)
+
+// a week rarely has more workouts, the weekly totals read them in one page
+const exerciseWeekLimit = 200
+
+func NewExerciseService(
+	tbExerciseLog common.Repository[model.ExerciseLog, string],
+	tbWeightHistory common.Repository[model.WeightHistory, string],
+	tbWeightGoal common.Repository[model.WeightGoal, string],
+	profileService ProfileServiceInterface,
+) *ExerciseService {
+	service := &ExerciseService{}
+	service.tables.exerciseLog = tbExerciseLog
+	service.tables.weightHistory = tbWeightHistory
+	service.tables.weightGoal = tbWeightGoal
+	service.profileService = profileService
+
+	return service
+}
+
+// ExerciseService logs workouts and works out the calories they burn from the
+// MET of the activity and the latest recorded weight.
+type ExerciseService struct {
+	tables struct {
+		exerciseLog   common.Repository[model.ExerciseLog, string]
+		weightHistory common.Repository[model.WeightHistory, string]
+		weightGoal    common.Repository[model.WeightGoal, string]
+	}
+	profileService ProfileServiceInterface
+}
+
+func (service *ExerciseService) ListActivities() []dto.ExerciseActivityResponse {
+	activities := met.Activities()
+
+	res := make([]dto.ExerciseActivityResponse, 0, len(activities))
+	for _, a := range activities {
+		res = append(res, dto.ExerciseActivityResponse{
+			Name:       a.Name,
+			Category:   a.Category,
+			Intensity:  a.Intensity,
+			SpeedBased: a.SpeedBased(),
+		})
+	}
+
+	return res
+}
+
+// LogExercise adds a workout, today when no date is given.
+func (service *ExerciseService) LogExercise(ctx context.Context, body dto.CreateExerciseLogRequest) (*dto.ExerciseLogResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	day, err := parseDay(body.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := met.Get(body.Activity)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Duration(body.Duration) * time.Minute
+	value, err := activity.MET(body.Intensity, body.Distance, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	weight, err := latestWeight(ctx, service.tables.weightHistory, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &model.ExerciseLog{
+		ID:        ulid.Make().String(),
+		ProfileID: profile.ID,
+		Activity:  activity.Name,
+		Date:      day,
+		Duration:  body.Duration,
+		Intensity: strings.ToLower(body.Intensity),
+		Distance:  body.Distance,
+		MET:       value,
+		Weight:    weight,
+		Calories:  met.Calories(value, weight, duration),
+		CreatedAt: time.Now(),
+	}
+
+	if err := service.tables.exerciseLog.Create(ctx, entry); err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := exerciseLogResponse(entry)
+	return &res, nil
+}
+
+// ListExercise returns the workouts of a day, today when no date is given.
+func (service *ExerciseService) ListExercise(ctx context.Context, date string) ([]dto.ExerciseLogResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	day, err := parseDay(date)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := service.between(ctx, profile.ID, day, day)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.ExerciseLogResponse, 0, len(logs))
+	for _, entry := range logs {
+		res = append(res, exerciseLogResponse(entry))
+	}
+
+	return res, nil
+}
+
+func (service *ExerciseService) DeleteExercise(ctx context.Context, id string) error {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	entry, err := service.tables.exerciseLog.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) {
+		return ErrExerciseNotFound
+	} else if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if entry.ProfileID != profile.ID {
+		return ErrExerciseNotFound
+	}
+
+	if err := service.tables.exerciseLog.Delete(ctx, id); err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// ExerciseWeek totals the seven days up to date, today when no date is given,
+// and suggests another activity level for the goal when the workouts no
+// longer match it.
+func (service *ExerciseService) ExerciseWeek(ctx context.Context, date string) (*dto.ExerciseWeekResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	to, err := parseDay(date)
+	if err != nil {
+		return nil, err
+	}
+	from := to.AddDate(0, 0, -6)
+
+	logs, err := service.between(ctx, profile.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := summarizeExerciseWeek(from, logs)
+
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
+	if errors.Is(err, ErrNotFound) {
+		return res, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	weight, err := latestWeight(ctx, service.tables.weightHistory, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	formula, err := energy.Get(goal.Formula)
+	if err != nil {
+		return nil, err
+	}
+
+	// without a bmr for the profile there is nothing to compare against
+	bmr, err := CalculateBMR(formula, energySubject(profile, weight, goal.BodyFat))
+	if err != nil {
+		return res, nil
+	}
+
+	net := 0.0
+	for _, entry := range logs {
+		net += netCalories(entry)
+	}
+
+	factor, level := SuggestActivityLevel(bmr, net)
+	res.ActivityLevel = goal.ActivityLevel
+	res.ActivityFactor = factor
+	if ActivityFactor(level) != ActivityFactor(goal.ActivityLevel) {
+		res.SuggestedActivityLevel = level
+	}
+
+	return res, nil
+}
+
+func summarizeExerciseWeek(from time.Time, logs []*model.ExerciseLog) *dto.ExerciseWeekResponse {
+	res := &dto.ExerciseWeekResponse{
+		From: from.Format(shortdDateLayout),
+		To:   from.AddDate(0, 0, 6).Format(shortdDateLayout),
+		Days: make([]dto.ExerciseDay, 7),
+	}
+
+	for i := range res.Days {
+		res.Days[i].Date = from.AddDate(0, 0, i).Format(shortdDateLayout)
+	}
+
+	for _, entry := range logs {
+		i := int(math.Round(entry.Date.Sub(from).Hours() / 24))
+		if i < 0 || i >= len(res.Days) {
+			continue
+		}
+
+		res.Days[i].Sessions++
+		res.Days[i].Duration += entry.Duration
+		res.Days[i].Calories += entry.Calories
+		res.Sessions++
+		res.Duration += entry.Duration
+		res.Calories += entry.Calories
+	}
+
+	for i := range res.Days {
+		res.Days[i].Calories = roundTenth(res.Days[i].Calories)
+	}
+	res.Calories = roundTenth(res.Calories)
+
+	return res
+}
+
+func (service *ExerciseService) between(ctx context.Context, profileID string, from, to time.Time) ([]*model.ExerciseLog, error) {
+	logs, err := service.tables.exerciseLog.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profileID),
+			goqu.C("date").Between(goqu.Range(from.Format(shortdDateLayout), to.Format(shortdDateLayout))),
+		},
+		Sort:  []exp.OrderedExpression{goqu.I("date").Asc(), goqu.I("created_at").Asc()},
+		Page:  1,
+		Limit: exerciseWeekLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	return logs, nil
+}
+
+func exerciseLogResponse(entry *model.ExerciseLog) dto.ExerciseLogResponse {
+	return dto.ExerciseLogResponse{
+		ID:        entry.ID,
+		Activity:  entry.Activity,
+		Date:      entry.Date.Format(shortdDateLayout),
+		Duration:  entry.Duration,
+		Intensity: entry.Intensity,
+		Distance:  entry.Distance,
+		MET:       entry.MET,
+		Weight:    entry.Weight,
+		Calories:  entry.Calories,
+	}
+}
+
+// netCalories of a workout are what it burned above resting, see met.NetCalories.
+func netCalories(entry *model.ExerciseLog) float64 {
+	return met.NetCalories(entry.MET, entry.Weight, time.Duration(entry.Duration)*time.Minute)
+}
+
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
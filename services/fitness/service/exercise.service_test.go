@@ -0,0 +1,51 @@
+package service
+
+import (
+	"monorepo/internal/constants"
+	"monorepo/services/fitness/model"
+	"testing"
+	"time"
+)
+
+func TestSuggestActivityLevel(t *testing.T) {
+	tests := []struct {
+		name           string
+		bmr            float64
+		weeklyCalories float64
+		wantFactor     float64
+		wantLevel      string
+	}{
+		{"No workouts", 1700, 0, 1.2, constants.ActivityLevelSedentary},
+		{"Light workouts", 1700, 2000, 1.368, constants.ActivityLevelLightActive},
+		{"Moderate workouts", 1700, 4000, 1.536, constants.ActivityLevelModerateActive},
+		{"Daily hard workouts", 1700, 7000, 1.788, constants.ActivityLevelVeryActive},
+		{"Unknown bmr", 0, 4000, 1.2, constants.ActivityLevelSedentary},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factor, level := SuggestActivityLevel(tt.bmr, tt.weeklyCalories)
+			if factor != tt.wantFactor || level != tt.wantLevel {
+				t.Errorf("SuggestActivityLevel() = %v, %v; want %v, %v", factor, level, tt.wantFactor, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func Test_summarizeExerciseWeek(t *testing.T) {
+	from := time.Date(2024, time.August, 6, 0, 0, 0, 0, time.UTC)
+	logs := []*model.ExerciseLog{
+		{Date: from, Duration: 30, Calories: 343},
+		{Date: from, Duration: 20, Calories: 98.4},
+		{Date: from.AddDate(0, 0, 6), Duration: 45, Calories: 216.6},
+		{Date: from.AddDate(0, 0, 7), Duration: 60, Calories: 500},
+	}
+
+	got := summarizeExerciseWeek(from, logs)
+	if got.From != "2024-08-06" || got.To != "2024-08-12" || got.Sessions != 3 || got.Duration != 95 || got.Calories != 658 {
+		t.Errorf("summarizeExerciseWeek() = %+v", got)
+	}
+
+	if len(got.Days) != 7 || got.Days[0].Sessions != 2 || got.Days[0].Calories != 441.4 || got.Days[6].Date != "2024-08-12" || got.Days[6].Duration != 45 {
+		t.Errorf("summarizeExerciseWeek().Days = %+v", got.Days)
+	}
+}
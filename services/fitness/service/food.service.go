@@ -0,0 +1,652 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/internal/repository"
+	"monorepo/pkg/common"
+	"monorepo/pkg/nutrition"
+	"monorepo/services/fitness/model"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	foodPageLimit    = 20
+	foodPageLimitMax = 100
+
+	// a day rarely has more entries, the summary reads them in one page
+	foodDiaryDayLimit = 200
+)
+
+// catalogCSV seeds the catalog on an empty database, it follows the
+// Indonesian food composition table (TKPI) with nutrients per 100 g.
+//
+//go:embed catalog/foods.csv
+var catalogCSV []byte
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+var meals = []string{constants.MealBreakfast, constants.MealLunch, constants.MealDinner, constants.MealSnack}
+
+func NewFoodService(
+	tbFood common.Repository[model.Food, string],
+	tbFoodDiary common.Repository[model.FoodDiary, string],
+	tbWeightGoal common.Repository[model.WeightGoal, string],
+	tbExerciseLog common.Repository[model.ExerciseLog, string],
+	profileService ProfileServiceInterface,
+) *FoodService {
+	service := &FoodService{}
+	service.tables.food = tbFood
+	service.tables.foodDiary = tbFoodDiary
+	service.tables.weightGoal = tbWeightGoal
+	service.tables.exerciseLog = tbExerciseLog
+	service.profileService = profileService
+
+	return service
+}
+
+// FoodService keeps the food catalog, the custom foods of every profile and
+// the food diary the daily intake is measured from.
+type FoodService struct {
+	tables struct {
+		food        common.Repository[model.Food, string]
+		foodDiary   common.Repository[model.FoodDiary, string]
+		weightGoal  common.Repository[model.WeightGoal, string]
+		exerciseLog common.Repository[model.ExerciseLog, string]
+	}
+	profileService ProfileServiceInterface
+}
+
+// SeedCatalog imports the bundled catalog unless a catalog was imported before.
+func (service *FoodService) SeedCatalog(ctx context.Context) error {
+	existing, err := service.tables.food.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("source").Eq(constants.FoodSourceCatalog)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(existing) > 0 {
+		return nil
+	}
+
+	_, err = service.ImportCatalog(ctx, bytes.NewReader(catalogCSV))
+	return err
+}
+
+// ImportCatalog adds the foods of a CSV catalog, foods already in the catalog
+// are matched by code and updated. Codes of custom foods are skipped.
+func (service *FoodService) ImportCatalog(ctx context.Context, r io.Reader) (*dto.FoodImportResponse, error) {
+	foods, err := nutrition.ParseCatalog(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(foods) == 0 {
+		return &dto.FoodImportResponse{}, nil
+	}
+
+	now := time.Now()
+	rows := make([]any, 0, len(foods))
+	for _, food := range foods {
+		rows = append(rows, goqu.Record{
+			"id":           food.Code,
+			"profile_id":   "",
+			"source":       constants.FoodSourceCatalog,
+			"name":         food.Name,
+			"category":     food.Category,
+			"serving_size": food.ServingSize,
+			"serving_unit": food.ServingUnit,
+			"energy":       food.Per100g.Energy,
+			"protein":      food.Per100g.Protein,
+			"carbohydrate": food.Per100g.Carbohydrate,
+			"fat":          food.Per100g.Fat,
+			"fibre":        food.Per100g.Fibre,
+			"created_at":   now,
+		})
+	}
+
+	// one statement so a catalog is imported whole or not at all, the values
+	// are inlined as a large catalog would run out of bind parameters
+	stmt, _, err := goqu.Dialect("postgres").Insert(repository.Tables.Food).
+		Rows(rows...).
+		OnConflict(goqu.DoUpdate("id", goqu.Record{
+			"name":         goqu.L("EXCLUDED.name"),
+			"category":     goqu.L("EXCLUDED.category"),
+			"serving_size": goqu.L("EXCLUDED.serving_size"),
+			"serving_unit": goqu.L("EXCLUDED.serving_unit"),
+			"energy":       goqu.L("EXCLUDED.energy"),
+			"protein":      goqu.L("EXCLUDED.protein"),
+			"carbohydrate": goqu.L("EXCLUDED.carbohydrate"),
+			"fat":          goqu.L("EXCLUDED.fat"),
+			"fibre":        goqu.L("EXCLUDED.fibre"),
+			"updated_at":   now,
+			"deleted_at":   nil,
+		}).Where(goqu.I(repository.Tables.Food + ".source").Eq(constants.FoodSourceCatalog))).
+		ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := service.tables.food.Raw(ctx, stmt)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	// codes taken by a custom food are left to it
+	imported, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.FoodImportResponse{Imported: int(imported), Skipped: len(foods) - int(imported)}, nil
+}
+
+// SearchFoods lists the catalog and the custom foods of the profile by name.
+func (service *FoodService) SearchFoods(ctx context.Context, filter dto.FilterSearchFoods) ([]dto.FoodResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	where := []exp.Expression{goqu.Or(goqu.C("profile_id").Eq(""), goqu.C("profile_id").Eq(profile.ID))}
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		where = append(where, goqu.C("name").ILike("%"+likeEscaper.Replace(query)+"%"))
+	}
+	if filter.Category != "" {
+		where = append(where, goqu.C("category").ILike(likeEscaper.Replace(filter.Category)))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = foodPageLimit
+	}
+
+	foods, err := service.tables.food.List(ctx, &common.FilterOptions{
+		Filter: where,
+		Sort:   []exp.OrderedExpression{goqu.I("name").Asc()},
+		Page:   filter.Page,
+		Limit:  min(limit, foodPageLimitMax),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	res := make([]dto.FoodResponse, 0, len(foods))
+	for _, food := range foods {
+		res = append(res, foodResponse(food))
+	}
+
+	return res, nil
+}
+
+// CreateFood adds a custom food only the profile can see.
+func (service *FoodService) CreateFood(ctx context.Context, body dto.CreateFoodRequest) (*dto.FoodResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	food := &model.Food{
+		ID:           ulid.Make().String(),
+		ProfileID:    profile.ID,
+		Source:       constants.FoodSourceCustom,
+		Name:         strings.TrimSpace(body.Name),
+		Category:     strings.TrimSpace(body.Category),
+		ServingSize:  body.ServingSize,
+		ServingUnit:  strings.TrimSpace(body.ServingUnit),
+		Energy:       body.Energy,
+		Protein:      body.Protein,
+		Carbohydrate: body.Carbohydrate,
+		Fat:          body.Fat,
+		Fibre:        body.Fibre,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := service.tables.food.Create(ctx, food); err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := foodResponse(food)
+	return &res, nil
+}
+
+// DeleteFood removes a custom food of the profile. Diary entries keep the
+// copy of the food they were logged with.
+func (service *FoodService) DeleteFood(ctx context.Context, id string) error {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	food, err := service.food(ctx, profile.ID, id)
+	if err != nil {
+		return err
+	} else if food.ProfileID != profile.ID {
+		return ErrFoodNotFound
+	}
+
+	if err := service.tables.food.Delete(ctx, id); err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// LogFood adds a portion of a food to the diary, today when no date is given.
+func (service *FoodService) LogFood(ctx context.Context, body dto.CreateFoodDiaryRequest) (*dto.FoodDiaryResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	date, err := parseDay(body.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	food, err := service.food(ctx, profile.ID, body.FoodID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &model.FoodDiary{
+		ID:        ulid.Make().String(),
+		ProfileID: profile.ID,
+		FoodID:    food.ID,
+		FoodName:  food.Name,
+		Date:      date,
+		Meal:      body.Meal,
+		CreatedAt: time.Now(),
+	}
+	if err := portion(entry, food, body.Amount, body.Unit); err != nil {
+		return nil, err
+	}
+
+	if err := service.tables.foodDiary.Create(ctx, entry); err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := foodDiaryResponse(entry)
+	return &res, nil
+}
+
+// ListFoodDiary returns the entries of a day, today when no date is given.
+func (service *FoodService) ListFoodDiary(ctx context.Context, date string) ([]dto.FoodDiaryResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	day, err := parseDay(date)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := service.day(ctx, profile.ID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.FoodDiaryResponse, 0, len(entries))
+	for _, entry := range entries {
+		res = append(res, foodDiaryResponse(entry))
+	}
+
+	return res, nil
+}
+
+// UpdateFoodDiary changes the meal or the portion of an entry. The nutrients
+// are worked out again from the food when it still exists, else scaled from
+// the logged portion.
+func (service *FoodService) UpdateFoodDiary(ctx context.Context, id string, body dto.UpdateFoodDiaryRequest) (*dto.FoodDiaryResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	entry, err := service.entry(ctx, profile.ID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := entry.Amount
+	if body.Amount > 0 {
+		amount = body.Amount
+	}
+
+	unit := entry.Unit
+	if body.Unit != "" {
+		unit = body.Unit
+	}
+
+	if body.Meal != "" {
+		entry.Meal = body.Meal
+	}
+
+	food, err := service.food(ctx, profile.ID, entry.FoodID)
+	if errors.Is(err, ErrFoodNotFound) {
+		food = loggedFood(entry)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := portion(entry, food, amount, unit); err != nil {
+		return nil, err
+	}
+	entry.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	// nutrients can drop to zero, which Update would skip
+	stmt, args, err := goqu.Dialect("postgres").Update(repository.Tables.FoodDiary).
+		Set(goqu.Record{
+			"meal":         entry.Meal,
+			"amount":       entry.Amount,
+			"unit":         entry.Unit,
+			"grams":        entry.Grams,
+			"energy":       entry.Energy,
+			"protein":      entry.Protein,
+			"carbohydrate": entry.Carbohydrate,
+			"fat":          entry.Fat,
+			"fibre":        entry.Fibre,
+			"updated_at":   entry.UpdatedAt,
+		}).
+		Where(goqu.C("id").Eq(entry.ID)).
+		ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := service.tables.foodDiary.Raw(ctx, stmt, args...); err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := foodDiaryResponse(entry)
+	return &res, nil
+}
+
+func (service *FoodService) DeleteFoodDiary(ctx context.Context, id string) error {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	if _, err := service.entry(ctx, profile.ID, id); err != nil {
+		return err
+	}
+
+	if err := service.tables.foodDiary.Delete(ctx, id); err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// FoodDiarySummary adds up a day per meal and compares it against the budget
+// and the macronutrient targets of the active weight goal. Calories burned
+// with logged workouts are earned on top of the budget.
+func (service *FoodService) FoodDiarySummary(ctx context.Context, date string) (*dto.FoodDiarySummaryResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	day, err := parseDay(date)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := service.day(ctx, profile.ID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	workouts, err := service.tables.exerciseLog.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profile.ID),
+			goqu.C("date").Eq(day.Format(shortdDateLayout)),
+		},
+		Page:  1,
+		Limit: exerciseWeekLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	earned := 0.0
+	for _, workout := range workouts {
+		earned += netCalories(workout)
+	}
+
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	return summarizeFoodDiary(day, entries, roundTenth(earned), goal), nil
+}
+
+func summarizeFoodDiary(day time.Time, entries []*model.FoodDiary, earned float64, goal *model.WeightGoal) *dto.FoodDiarySummaryResponse {
+	consumed := nutrition.Nutrients{}
+	perMeal := map[string]nutrition.Nutrients{}
+	count := map[string]int{}
+	for _, entry := range entries {
+		n := entryNutrients(entry)
+		consumed = consumed.Add(n)
+		perMeal[entry.Meal] = perMeal[entry.Meal].Add(n)
+		count[entry.Meal]++
+	}
+
+	res := &dto.FoodDiarySummaryResponse{
+		Date:     day.Format(shortdDateLayout),
+		Consumed: nutrientsResponse(consumed.Round()),
+		Earned:   earned,
+		Meals:    make([]dto.FoodDiaryMeal, 0, len(meals)),
+	}
+
+	for _, meal := range meals {
+		res.Meals = append(res.Meals, dto.FoodDiaryMeal{
+			Meal:      meal,
+			Entries:   count[meal],
+			Nutrients: nutrientsResponse(perMeal[meal].Round()),
+		})
+	}
+
+	if goal == nil {
+		return res
+	}
+
+	res.DailyCaloriesBudget = goal.DailyCalorieBudget
+	res.Target = goalMacros(goal)
+
+	remaining := nutrition.Nutrients{Energy: goal.DailyCalorieBudget + earned - consumed.Energy}
+	if res.Target != nil {
+		remaining.Protein = res.Target.Protein - consumed.Protein
+		remaining.Carbohydrate = res.Target.Carbohydrate - consumed.Carbohydrate
+		remaining.Fat = res.Target.Fat - consumed.Fat
+		remaining.Fibre = res.Target.Fibre - consumed.Fibre
+	}
+	remainingRes := nutrientsResponse(remaining.Round())
+	res.Remaining = &remainingRes
+
+	return res
+}
+
+// food returns a catalog food or a custom food of the profile.
+func (service *FoodService) food(ctx context.Context, profileID, id string) (*model.Food, error) {
+	food, err := service.tables.food.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) {
+		return nil, ErrFoodNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if food.ProfileID != "" && food.ProfileID != profileID {
+		return nil, ErrFoodNotFound
+	}
+
+	return food, nil
+}
+
+func (service *FoodService) entry(ctx context.Context, profileID, id string) (*model.FoodDiary, error) {
+	entry, err := service.tables.foodDiary.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) {
+		return nil, ErrFoodDiaryNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if entry.ProfileID != profileID {
+		return nil, ErrFoodDiaryNotFound
+	}
+
+	return entry, nil
+}
+
+func (service *FoodService) day(ctx context.Context, profileID string, day time.Time) ([]*model.FoodDiary, error) {
+	entries, err := service.tables.foodDiary.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profileID),
+			goqu.C("date").Eq(day.Format(shortdDateLayout)),
+		},
+		Sort:  []exp.OrderedExpression{goqu.I("created_at").Asc()},
+		Page:  1,
+		Limit: foodDiaryDayLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	return entries, nil
+}
+
+func parseDay(date string) (time.Time, error) {
+	if date == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	day, err := time.Parse(shortdDateLayout, date)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+
+	return day, nil
+}
+
+// portion sets the amount, grams and nutrients of the entry.
+func portion(entry *model.FoodDiary, food *model.Food, amount float64, unit string) error {
+	grams, n, err := catalogFood(food).Portion(amount, unit)
+	if err != nil {
+		return err
+	}
+
+	entry.Amount = amount
+	entry.Unit = strings.ToLower(unit)
+	entry.Grams = grams
+	entry.Energy = n.Energy
+	entry.Protein = n.Protein
+	entry.Carbohydrate = n.Carbohydrate
+	entry.Fat = n.Fat
+	entry.Fibre = n.Fibre
+
+	return nil
+}
+
+// loggedFood rebuilds the food of an entry whose food was deleted from what
+// the entry logged, so its portion can still be changed.
+func loggedFood(entry *model.FoodDiary) *model.Food {
+	food := &model.Food{ID: entry.FoodID, Name: entry.FoodName}
+	if entry.Grams <= 0 {
+		return food
+	}
+
+	per100g := entryNutrients(entry).Scale(100 / entry.Grams)
+	food.Energy = per100g.Energy
+	food.Protein = per100g.Protein
+	food.Carbohydrate = per100g.Carbohydrate
+	food.Fat = per100g.Fat
+	food.Fibre = per100g.Fibre
+	if entry.Unit == nutrition.UnitServing && entry.Amount > 0 {
+		food.ServingSize = math.Round(entry.Grams/entry.Amount*100) / 100
+	}
+
+	return food
+}
+
+func catalogFood(food *model.Food) nutrition.Food {
+	return nutrition.Food{
+		Code:        food.ID,
+		Name:        food.Name,
+		Category:    food.Category,
+		ServingSize: food.ServingSize,
+		ServingUnit: food.ServingUnit,
+		Per100g: nutrition.Nutrients{
+			Energy:       food.Energy,
+			Protein:      food.Protein,
+			Carbohydrate: food.Carbohydrate,
+			Fat:          food.Fat,
+			Fibre:        food.Fibre,
+		},
+	}
+}
+
+func entryNutrients(entry *model.FoodDiary) nutrition.Nutrients {
+	return nutrition.Nutrients{
+		Energy:       entry.Energy,
+		Protein:      entry.Protein,
+		Carbohydrate: entry.Carbohydrate,
+		Fat:          entry.Fat,
+		Fibre:        entry.Fibre,
+	}
+}
+
+func nutrientsResponse(n nutrition.Nutrients) dto.Nutrients {
+	return dto.Nutrients{
+		Energy:       n.Energy,
+		Protein:      n.Protein,
+		Carbohydrate: n.Carbohydrate,
+		Fat:          n.Fat,
+		Fibre:        n.Fibre,
+	}
+}
+
+func foodResponse(food *model.Food) dto.FoodResponse {
+	return dto.FoodResponse{
+		ID:          food.ID,
+		Source:      food.Source,
+		Name:        food.Name,
+		Category:    food.Category,
+		ServingSize: food.ServingSize,
+		ServingUnit: food.ServingUnit,
+		Per100g:     nutrientsResponse(catalogFood(food).Per100g),
+	}
+}
+
+func foodDiaryResponse(entry *model.FoodDiary) dto.FoodDiaryResponse {
+	return dto.FoodDiaryResponse{
+		ID:        entry.ID,
+		FoodID:    entry.FoodID,
+		FoodName:  entry.FoodName,
+		Date:      entry.Date.Format(shortdDateLayout),
+		Meal:      entry.Meal,
+		Amount:    entry.Amount,
+		Unit:      entry.Unit,
+		Grams:     entry.Grams,
+		Nutrients: nutrientsResponse(entryNutrients(entry)),
+	}
+}
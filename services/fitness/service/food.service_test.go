@@ -0,0 +1,70 @@
+package service
+
+import (
+	"monorepo/internal/dto"
+	"monorepo/services/fitness/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_summarizeFoodDiary(t *testing.T) {
+	day := time.Date(2024, time.August, 12, 0, 0, 0, 0, time.UTC)
+	entries := []*model.FoodDiary{
+		{Meal: "breakfast", Energy: 270, Protein: 4.5, Carbohydrate: 59.7, Fat: 0.5, Fibre: 0.3},
+		{Meal: "breakfast", Energy: 84.7, Protein: 6.8, Carbohydrate: 0.4, Fat: 5.9},
+		{Meal: "lunch", Energy: 552, Protein: 30.1, Carbohydrate: 60.2, Fat: 20.4, Fibre: 2.5},
+	}
+	goal := &model.WeightGoal{
+		DailyCalorieBudget: 1842.38,
+		StartingWeight:     80,
+		ProteinTarget:      96,
+		CarbohydrateTarget: 219,
+		FatTarget:          65,
+		FibreTarget:        26,
+	}
+
+	meals := []dto.FoodDiaryMeal{
+		{Meal: "breakfast", Entries: 2, Nutrients: dto.Nutrients{Energy: 354.7, Protein: 11.3, Carbohydrate: 60.1, Fat: 6.4, Fibre: 0.3}},
+		{Meal: "lunch", Entries: 1, Nutrients: dto.Nutrients{Energy: 552, Protein: 30.1, Carbohydrate: 60.2, Fat: 20.4, Fibre: 2.5}},
+		{Meal: "dinner"},
+		{Meal: "snack"},
+	}
+
+	tests := []struct {
+		name   string
+		earned float64
+		goal   *model.WeightGoal
+		want   *dto.FoodDiarySummaryResponse
+	}{
+		{
+			name:   "Against the weight goal",
+			earned: 343,
+			goal:   goal,
+			want: &dto.FoodDiarySummaryResponse{
+				Date:                "2024-08-12",
+				DailyCaloriesBudget: 1842.38,
+				Consumed:            dto.Nutrients{Energy: 906.7, Protein: 41.4, Carbohydrate: 120.3, Fat: 26.8, Fibre: 2.8},
+				Earned:              343,
+				Target:              &dto.MacroTargets{Protein: 96, Carbohydrate: 219, Fat: 65, Fibre: 26},
+				Remaining:           &dto.Nutrients{Energy: 1278.7, Protein: 54.6, Carbohydrate: 98.7, Fat: 38.2, Fibre: 23.2},
+				Meals:               meals,
+			},
+		},
+		{
+			name: "Without weight goal",
+			want: &dto.FoodDiarySummaryResponse{
+				Date:     "2024-08-12",
+				Consumed: dto.Nutrients{Energy: 906.7, Protein: 41.4, Carbohydrate: 120.3, Fat: 26.8, Fibre: 2.8},
+				Meals:    meals,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeFoodDiary(day, entries, tt.earned, tt.goal); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizeFoodDiary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/pkg/common"
+	"monorepo/pkg/energy"
+	"monorepo/pkg/macro"
+	"monorepo/services/fitness/model"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	// the energy balance covers the last four weeks, the four before only seed
+	// the weight trend so it does not start from a single weighing
+	maintenanceDays     = 28
+	maintenanceSeedDays = 28
+
+	// diary entries and workouts of the span are read in one page
+	maintenanceEntryLimit = 2000
+
+	// a goal is recalibrated on its own at most once a week, and only when the
+	// budget moves enough to be worth following
+	calibrationInterval  = 7 * 24 * time.Hour
+	calibrationMinChange = 50.0
+
+	// a calibration moves the budget by at most a step, and never below the
+	// bmr or what is safe to eat without supervision, whatever was logged
+	calibrationMaxStep   = 250.0
+	calibrationMinBudget = 1200.0
+)
+
+// EstimateMaintenance estimates the calories to maintain of the profile from
+// its logged weight and intake, and the budget the goal would get from it.
+func (service *WeightGoalService) EstimateMaintenance(ctx context.Context) (*dto.MaintenanceEstimateResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := service.estimateMaintenance(ctx, goal, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	res.FromMetric(profile.Units)
+	return res, nil
+}
+
+// ApplyMaintenance recalibrates the goal with the estimate, whatever its
+// confidence, within the bounds of a calibration.
+func (service *WeightGoalService) ApplyMaintenance(ctx context.Context) (*dto.CalorieAdjustmentResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate, err := service.estimateMaintenance(ctx, goal, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	floor, err := service.budgetFloor(ctx, goal, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	budget, _ := boundBudget(estimate.SuggestedDailyCaloriesBudget, goal.DailyCalorieBudget, floor)
+	adjustment, err := service.calibrate(ctx, goal, estimate, budget, constants.CalorieAdjustManual)
+	if err != nil {
+		return nil, err
+	}
+
+	res := calorieAdjustmentResponse(adjustment)
+	res.FromMetric(profile.Units)
+
+	return &res, nil
+}
+
+func (service *WeightGoalService) ListCalorieAdjustments(ctx context.Context, page, limit int) ([]dto.CalorieAdjustmentResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	adjustments, err := service.tables.calorieAdjustment.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("profile_id").Eq(profile.ID)},
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Page:   page,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	res := make([]dto.CalorieAdjustmentResponse, 0, len(adjustments))
+	for _, adjustment := range adjustments {
+		data := calorieAdjustmentResponse(adjustment)
+		data.FromMetric(profile.Units)
+
+		res = append(res, data)
+	}
+
+	return res, nil
+}
+
+// autoCalibrate recalibrates an active goal that opted in, once the estimate is
+// trustworthy. Too little data is not an error, the goal just stays as it is,
+// as it does when the estimate is out of the bounds of a calibration.
+func (service *WeightGoalService) autoCalibrate(ctx context.Context, goal *model.WeightGoal) error {
+	flag := strings.ToLower(goal.Flag)
+	if !autoCalibrates(goal) || goal.Status != constants.WeightGoalActive ||
+		(flag != constants.WeightGoalGain && flag != constants.WeightGoalLoss) {
+		return nil
+	}
+
+	now := time.Now()
+	last, err := service.tables.calorieAdjustment.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("weight_goal_id").Eq(goal.ID)},
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(last) > 0 && now.Sub(last[0].CreatedAt) < calibrationInterval {
+		return nil
+	}
+
+	estimate, err := service.estimateMaintenance(ctx, goal, now)
+	if errors.Is(err, energy.ErrNotEnoughData) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if estimate.Confidence == energy.ConfidenceLow ||
+		math.Abs(estimate.SuggestedDailyCaloriesBudget-goal.DailyCalorieBudget) < calibrationMinChange {
+		return nil
+	}
+
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	floor, err := service.budgetFloor(ctx, goal, profile)
+	if err != nil {
+		return err
+	}
+
+	// an estimate that far off is more likely an under-logged diary than a
+	// change in expenditure, it is left to the user to apply
+	budget, bounded := boundBudget(estimate.SuggestedDailyCaloriesBudget, goal.DailyCalorieBudget, floor)
+	if bounded {
+		return nil
+	}
+
+	_, err = service.calibrate(ctx, goal, estimate, budget, constants.CalorieAdjustAuto)
+	return err
+}
+
+// budgetFloor is the least a calibration budgets for the goal, the bmr at the
+// latest weight and at least calibrationMinBudget.
+func (service *WeightGoalService) budgetFloor(ctx context.Context, goal *model.WeightGoal, profile *dto.ResponseGetProfile) (float64, error) {
+	weight, err := latestWeight(ctx, service.tables.weightHistory, profile)
+	if errors.Is(err, ErrWeightUnknown) {
+		return calibrationMinBudget, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	formula, err := energy.Get(goal.Formula)
+	if err != nil {
+		return 0, err
+	}
+
+	// without a bmr for the profile the fixed minimum applies
+	bmr, err := CalculateBMR(formula, energySubject(profile, weight, goal.BodyFat))
+	if err != nil {
+		return calibrationMinBudget, nil
+	}
+
+	return max(roundHundredth(bmr), calibrationMinBudget), nil
+}
+
+// boundBudget keeps a suggested budget within calibrationMaxStep of the
+// current one and no lower than floor, and reports whether it had to.
+func boundBudget(suggested, current, floor float64) (float64, bool) {
+	budget := min(max(suggested, current-calibrationMaxStep), current+calibrationMaxStep)
+	budget = max(budget, floor)
+
+	return budget, budget != suggested
+}
+
+// calibrate moves the goal to the estimated calories to maintain and the
+// budget, and records the adjustment.
+func (service *WeightGoalService) calibrate(ctx context.Context, goal *model.WeightGoal, estimate *dto.MaintenanceEstimateResponse, budget float64, source string) (*model.CalorieAdjustment, error) {
+	preset, err := macro.Get(goal.MacroPreset)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	targets := preset.Split(budget, goal.StartingWeight)
+
+	err = service.tables.weightGoal.Update(ctx, goal.ID, &model.WeightGoal{
+		CaloriesToMaintain: estimate.CaloriesToMaintain,
+		DailyCalorieBudget: budget,
+		ProteinTarget:      targets.Protein,
+		CarbohydrateTarget: targets.Carbohydrate,
+		FatTarget:          targets.Fat,
+		FibreTarget:        targets.Fibre,
+		UpdatedAt:          sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	adjustment := &model.CalorieAdjustment{
+		ID:                         ulid.Make().String(),
+		ProfileID:                  goal.ProfileID,
+		WeightGoalID:               goal.ID,
+		Source:                     source,
+		Confidence:                 estimate.Confidence,
+		Days:                       estimate.Days,
+		Intake:                     estimate.Intake,
+		IntakeSource:               estimate.IntakeSource,
+		WeightChange:               estimate.WeightChange,
+		PreviousCaloriesToMaintain: goal.CaloriesToMaintain,
+		CaloriesToMaintain:         estimate.CaloriesToMaintain,
+		PreviousDailyCalorieBudget: goal.DailyCalorieBudget,
+		DailyCalorieBudget:         budget,
+		CreatedAt:                  now,
+	}
+
+	if err := service.tables.calorieAdjustment.Create(ctx, adjustment); err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return adjustment, nil
+}
+
+func (service *WeightGoalService) estimateMaintenance(ctx context.Context, goal *model.WeightGoal, now time.Time) (*dto.MaintenanceEstimateResponse, error) {
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -maintenanceDays)
+	seed := from.AddDate(0, 0, -maintenanceSeedDays)
+
+	histories, err := service.tables.weightHistory.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(goal.ProfileID),
+			goqu.L("DATE(created_at)").Gte(seed.Format(shortdDateLayout)),
+		},
+		Sort:  []exp.OrderedExpression{goqu.I("created_at").Asc()},
+		Page:  1,
+		Limit: maintenanceDays + maintenanceSeedDays + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	span := goqu.C("date").Between(goqu.Range(seed.Format(shortdDateLayout), to.Format(shortdDateLayout)))
+	diary, err := service.tables.foodDiary.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("profile_id").Eq(goal.ProfileID), span},
+		Page:   1,
+		Limit:  maintenanceEntryLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	workouts, err := service.tables.exerciseLog.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("profile_id").Eq(goal.ProfileID), span},
+		Page:   1,
+		Limit:  maintenanceEntryLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	return balanceEstimate(histories, diary, workouts, goal, from)
+}
+
+// balanceEstimate runs the energy balance from the last weighing on or before
+// from, else the first after it, to the latest weighing. The trend weight is
+// compared so a single heavy morning does not swing the estimate.
+func balanceEstimate(histories []*model.WeightHistory, diary []*model.FoodDiary, workouts []*model.ExerciseLog, goal *model.WeightGoal, from time.Time) (*dto.MaintenanceEstimateResponse, error) {
+	smoothed := smoothHistory(histories)
+	if len(smoothed) < 2 {
+		return nil, energy.ErrNotEnoughData
+	}
+
+	start := 0
+	for i, s := range smoothed {
+		if s.Date.After(from) {
+			break
+		}
+		start = i
+	}
+
+	first, last := smoothed[start], smoothed[len(smoothed)-1]
+	days := int(math.Round(last.Date.Sub(first.Date).Hours() / 24))
+	within := func(date time.Time) bool {
+		date = date.UTC()
+		return !date.Before(first.Date) && date.Before(last.Date)
+	}
+
+	intake := map[string]float64{}
+	for _, entry := range diary {
+		if within(entry.Date) {
+			intake[entry.Date.UTC().Format(shortdDateLayout)] += entry.Energy
+		}
+	}
+
+	var exercise float64
+	for _, workout := range workouts {
+		if within(workout.Date) {
+			exercise += netCalories(workout)
+		}
+	}
+
+	balance := energy.Balance{
+		Days:         days,
+		Weighings:    len(smoothed) - start,
+		IntakeDays:   len(intake),
+		WeightChange: last.Trend - first.Trend,
+	}
+
+	source := constants.IntakeSourceDiary
+	if 2*balance.IntakeDays < balance.Days {
+		// too little was logged to go by, assume the budget was kept
+		source = constants.IntakeSourceBudget
+		balance.Intake = goal.DailyCalorieBudget
+		balance.IntakeDays = 0
+	} else {
+		for _, kcal := range intake {
+			balance.Intake += kcal
+		}
+		balance.Intake /= float64(balance.IntakeDays)
+	}
+
+	expenditure, err := balance.Expenditure()
+	if err != nil {
+		return nil, err
+	}
+
+	exercise /= float64(days)
+	caloriesToMaintain := roundHundredth(expenditure - exercise)
+	planned := goal.DailyCalorieBudget - goal.CaloriesToMaintain
+
+	return &dto.MaintenanceEstimateResponse{
+		From:                         first.Date.Format(shortdDateLayout),
+		To:                           last.Date.Format(shortdDateLayout),
+		Days:                         balance.Days,
+		Weighings:                    balance.Weighings,
+		IntakeDays:                   len(intake),
+		Intake:                       roundHundredth(balance.Intake),
+		IntakeSource:                 source,
+		Exercise:                     roundHundredth(exercise),
+		WeightChange:                 roundHundredth(balance.WeightChange),
+		Confidence:                   balance.Confidence(),
+		CaloriesToMaintain:           caloriesToMaintain,
+		CurrentCaloriesToMaintain:    goal.CaloriesToMaintain,
+		CurrentDailyCaloriesBudget:   goal.DailyCalorieBudget,
+		SuggestedDailyCaloriesBudget: roundHundredth(caloriesToMaintain + planned),
+		AutoCalibrate:                autoCalibrates(goal),
+	}, nil
+}
+
+func autoCalibrates(goal *model.WeightGoal) bool {
+	return goal.AutoCalibrate != nil && *goal.AutoCalibrate
+}
+
+func calorieAdjustmentResponse(adjustment *model.CalorieAdjustment) dto.CalorieAdjustmentResponse {
+	return dto.CalorieAdjustmentResponse{
+		ID:                          adjustment.ID,
+		Date:                        adjustment.CreatedAt.Format(shortdDateLayout),
+		Source:                      adjustment.Source,
+		Confidence:                  adjustment.Confidence,
+		Days:                        adjustment.Days,
+		Intake:                      adjustment.Intake,
+		IntakeSource:                adjustment.IntakeSource,
+		WeightChange:                adjustment.WeightChange,
+		PreviousCaloriesToMaintain:  adjustment.PreviousCaloriesToMaintain,
+		CaloriesToMaintain:          adjustment.CaloriesToMaintain,
+		PreviousDailyCaloriesBudget: adjustment.PreviousDailyCalorieBudget,
+		DailyCaloriesBudget:         adjustment.DailyCalorieBudget,
+	}
+}
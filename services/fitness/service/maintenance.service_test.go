@@ -0,0 +1,144 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"monorepo/internal/dto"
+	"monorepo/pkg/energy"
+	"monorepo/services/fitness/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_balanceEstimate(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	from := start.AddDate(0, 0, 28)
+	autoCalibrate := true
+
+	// 50 g a day down from 80 kg, weighed every morning for eight weeks
+	var histories []*model.WeightHistory
+	for i := 0; i <= 56; i++ {
+		histories = append(histories, &model.WeightHistory{
+			Weight:    80 - 0.05*float64(i),
+			CreatedAt: start.AddDate(0, 0, i).Add(7 * time.Hour),
+		})
+	}
+
+	var diary []*model.FoodDiary
+	for i := 28; i < 56; i++ {
+		day := start.AddDate(0, 0, i)
+		diary = append(diary,
+			&model.FoodDiary{Date: day, Energy: 600},
+			&model.FoodDiary{Date: day, Energy: 1200},
+		)
+	}
+
+	// 280 kcal above resting each
+	workouts := []*model.ExerciseLog{
+		{Date: start.AddDate(0, 0, 30), Duration: 30, MET: 8, Weight: 80, Calories: 320},
+		{Date: start.AddDate(0, 0, 44), Duration: 30, MET: 8, Weight: 80, Calories: 320},
+		{Date: start.AddDate(0, 0, 10), Duration: 60, MET: 7, Weight: 80, Calories: 560},
+	}
+
+	goal := &model.WeightGoal{
+		DailyCalorieBudget: 1842.38,
+		CaloriesToMaintain: 2117.38,
+		Flag:               "loss",
+		AutoCalibrate:      &autoCalibrate,
+	}
+
+	tests := []struct {
+		name      string
+		histories []*model.WeightHistory
+		diary     []*model.FoodDiary
+		want      *dto.MaintenanceEstimateResponse
+		wantErr   error
+	}{
+		{
+			name:      "Intake from the diary",
+			histories: histories,
+			diary:     diary,
+			want: &dto.MaintenanceEstimateResponse{
+				From:                         "2024-03-29",
+				To:                           "2024-04-26",
+				Days:                         28,
+				Weighings:                    29,
+				IntakeDays:                   28,
+				Intake:                       1800,
+				IntakeSource:                 "diary",
+				Exercise:                     20,
+				WeightChange:                 -1.38,
+				Confidence:                   "high",
+				CaloriesToMaintain:           2158.86,
+				CurrentCaloriesToMaintain:    2117.38,
+				CurrentDailyCaloriesBudget:   1842.38,
+				SuggestedDailyCaloriesBudget: 1883.86,
+				AutoCalibrate:                true,
+			},
+		},
+		{
+			name:      "Budget taken as eaten without a diary",
+			histories: histories,
+			diary:     diary[:20],
+			want: &dto.MaintenanceEstimateResponse{
+				From:                         "2024-03-29",
+				To:                           "2024-04-26",
+				Days:                         28,
+				Weighings:                    29,
+				IntakeDays:                   10,
+				Intake:                       1842.38,
+				IntakeSource:                 "budget",
+				Exercise:                     20,
+				WeightChange:                 -1.38,
+				Confidence:                   "low",
+				CaloriesToMaintain:           2201.24,
+				CurrentCaloriesToMaintain:    2117.38,
+				CurrentDailyCaloriesBudget:   1842.38,
+				SuggestedDailyCaloriesBudget: 1926.24,
+				AutoCalibrate:                true,
+			},
+		},
+		{
+			name:      "Too few days of weighings",
+			histories: histories[46:],
+			diary:     diary,
+			wantErr:   energy.ErrNotEnoughData,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := balanceEstimate(tt.histories, tt.diary, workouts, goal, from)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("balanceEstimate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("balanceEstimate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_boundBudget(t *testing.T) {
+	tests := []struct {
+		name                      string
+		suggested, current, floor float64
+		want                      float64
+		wantBounded               bool
+	}{
+		{"Within bounds", 1883.86, 1842.38, 1650, 1883.86, false},
+		{"Limited to a step down", 1300, 1842.38, 1200, 1592.38, true},
+		{"Limited to a step up", 2400, 1842.38, 1200, 2092.38, true},
+		{"Not under the floor", 1500, 1600, 1564.2, 1564.2, true},
+		{"Floor over a step", 1000, 1100, 1200, 1200, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, bounded := boundBudget(tt.suggested, tt.current, tt.floor)
+			if math.Abs(got-tt.want) > 1e-9 || bounded != tt.wantBounded {
+				t.Errorf("boundBudget() = %v, %v; want %v, %v", got, bounded, tt.want, tt.wantBounded)
+			}
+		})
+	}
+}
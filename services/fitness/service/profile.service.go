@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"monorepo/internal/acting"
 	"monorepo/internal/dto"
+	"monorepo/pkg/units"
 	"monorepo/pkg/utils"
 	"net/http"
 	"os"
@@ -28,7 +30,8 @@ type ProfileServiceInterface interface {
 }
 
 func (p *ProfileService) GetProfile(ctx context.Context) (*dto.ResponseGetProfile, error) {
-	url := p.ProfileURL + "/profile"
+	// weights and heights as kept, the profile has the units shown to the user
+	url := p.ProfileURL + "/profile?units=" + units.Metric
 
 	type GetProfileResponse struct {
 		Data    dto.ResponseGetProfile `json:"data"`
@@ -36,12 +39,12 @@ func (p *ProfileService) GetProfile(ctx context.Context) (*dto.ResponseGetProfil
 	}
 	resp := GetProfileResponse{}
 
-	headers := []utils.Header{
+	headers := acting.Headers(ctx, []utils.Header{
 		{
 			Key:   "Authorization",
 			Value: "Bearer " + ctx.Value(oauth.AccessTokenContext).(string),
 		},
-	}
+	})
 
 	if _, err := utils.DoRequest(http.MethodGet, url, headers, nil, &resp); err != nil {
 		errMessage := fmt.Sprintf("call api error : %s", err.Error())
@@ -55,12 +58,12 @@ func (p *ProfileService) UpdateProfile(ctx context.Context, userID string, data
 	url := p.ProfileURL + "/profile/" + userID
 	var resp any
 
-	headers := []utils.Header{
+	headers := acting.Headers(ctx, []utils.Header{
 		{
 			Key:   "Authorization",
 			Value: "Bearer " + ctx.Value(oauth.AccessTokenContext).(string),
 		},
-	}
+	})
 
 	if _, err := utils.DoRequest(http.MethodPatch, url, headers, data, &resp); err != nil {
 		errMessage := fmt.Sprintf("call api error : %s", err.Error())
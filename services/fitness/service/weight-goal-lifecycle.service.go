@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/internal/repository"
+	"monorepo/pkg/common"
+	"monorepo/pkg/macro"
+	"monorepo/services/fitness/model"
+	"slices"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+// A goal is active until the target weight is reached, achieved then, and
+// maintaining once the user settles at the new weight. Abandoned and
+// superseded goals are over and only kept as history, a profile has at most
+// one goal in any other status.
+var (
+	openGoalStatuses = []string{constants.WeightGoalActive, constants.WeightGoalAchieved, constants.WeightGoalMaintaining}
+	goalTransitions  = map[string][]string{
+		constants.WeightGoalActive:      {constants.WeightGoalAchieved, constants.WeightGoalAbandoned, constants.WeightGoalSuperseded},
+		constants.WeightGoalAchieved:    {constants.WeightGoalMaintaining, constants.WeightGoalAbandoned, constants.WeightGoalSuperseded},
+		constants.WeightGoalMaintaining: {constants.WeightGoalAbandoned, constants.WeightGoalSuperseded},
+	}
+)
+
+// MaintainWeightGoal moves an achieved goal to maintaining the reached weight,
+// the budget becomes the calories to maintain.
+func (service *WeightGoalService) MaintainWeightGoal(ctx context.Context) (*dto.GetWeightGoalResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.transition(ctx, goal, constants.WeightGoalMaintaining, 0); err != nil {
+		return nil, err
+	}
+
+	return service.GetWeightGoal(ctx)
+}
+
+func (service *WeightGoalService) AbandonWeightGoal(ctx context.Context) error {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
+	if err != nil {
+		return err
+	}
+
+	return service.endGoal(ctx, goal, constants.WeightGoalAbandoned, profile)
+}
+
+// ListPastWeightGoals returns the goals that are over, latest first.
+func (service *WeightGoalService) ListPastWeightGoals(ctx context.Context, page, limit int) ([]dto.WeightGoalSummary, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	goals, err := service.tables.weightGoal.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profile.ID),
+			goqu.C("status").NotIn(openGoalStatuses),
+		},
+		Sort:  []exp.OrderedExpression{goqu.I("ended_at").Desc()},
+		Page:  page,
+		Limit: limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	res := make([]dto.WeightGoalSummary, 0, len(goals))
+	for _, goal := range goals {
+		data := weightGoalSummary(goal)
+		data.FromMetric(profile.Units)
+
+		res = append(res, data)
+	}
+
+	return res, nil
+}
+
+// endGoal closes the goal with the latest weight as its outcome.
+func (service *WeightGoalService) endGoal(ctx context.Context, goal *model.WeightGoal, status string, profile *dto.ResponseGetProfile) error {
+	weight, err := latestWeight(ctx, service.tables.weightHistory, profile)
+	if err != nil && !errors.Is(err, ErrWeightUnknown) {
+		return err
+	}
+
+	return service.transition(ctx, goal, status, weight)
+}
+
+// replaceGoal creates goal, superseding current if there is one.
+func (service *WeightGoalService) replaceGoal(ctx context.Context, goal, current *model.WeightGoal, profile *dto.ResponseGetProfile) error {
+	weight, err := latestWeight(ctx, service.tables.weightHistory, profile)
+	if err != nil && !errors.Is(err, ErrWeightUnknown) {
+		return err
+	}
+
+	stmt, args, err := replaceGoalStatement(goal, current, weight, time.Now())
+	if err != nil {
+		return err
+	}
+
+	result, err := service.tables.weightGoal.Raw(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	// nothing is inserted when the current goal changed since it was read
+	if inserted, _ := result.RowsAffected(); inserted == 0 {
+		return ErrWeightGoalChanged
+	}
+
+	if current != nil {
+		current.Status = constants.WeightGoalSuperseded
+	}
+
+	return nil
+}
+
+// replaceGoalStatement inserts goal and supersedes current in one statement,
+// so a profile is never left with two goals in progress or none. The insert
+// reads the superseded goal, which runs the update first and keeps the unique
+// index on the goal in progress satisfied.
+func replaceGoalStatement(goal, current *model.WeightGoal, endWeight float64, now time.Time) (string, []any, error) {
+	dialect := goqu.Dialect("postgres")
+	if current == nil {
+		return dialect.Insert(repository.Tables.WeightGoal).Rows(goal).ToSQL()
+	}
+
+	row, err := exp.NewInsertExpression(goal)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cols := []any{}
+	for _, col := range row.Cols().Columns() {
+		cols = append(cols, col)
+	}
+
+	vals := []any{}
+	for _, val := range row.Vals()[0] {
+		vals = append(vals, goqu.V(val))
+	}
+
+	superseded := dialect.Update(repository.Tables.WeightGoal).
+		Set(goqu.Record{
+			"status":     constants.WeightGoalSuperseded,
+			"ended_at":   now,
+			"end_weight": endWeight,
+			"updated_at": now,
+		}).
+		Where(
+			goqu.C("id").Eq(current.ID),
+			goqu.C("status").Eq(current.Status),
+			goqu.C("deleted_at").IsNull(),
+		).
+		Returning("id")
+
+	return dialect.Insert(repository.Tables.WeightGoal).
+		With("superseded", superseded).
+		Cols(cols...).
+		FromQuery(dialect.Select(vals...).Where(goqu.L("EXISTS (SELECT 1 FROM superseded)"))).
+		ToSQL()
+}
+
+// transition moves the goal to status if the lifecycle allows it. Weight is
+// the outcome of a goal that ends, 0 when unknown.
+func (service *WeightGoalService) transition(ctx context.Context, goal *model.WeightGoal, status string, weight float64) error {
+	if !slices.Contains(goalTransitions[goal.Status], status) {
+		return fmt.Errorf("%w: %s to %s", ErrWeightGoalTransition, goal.Status, status)
+	}
+
+	now := time.Now()
+	update := model.WeightGoal{
+		Status:    status,
+		UpdatedAt: sql.NullTime{Time: now, Valid: true},
+	}
+
+	switch status {
+	case constants.WeightGoalAchieved:
+		update.AchievedAt = sql.NullTime{Time: now, Valid: true}
+	case constants.WeightGoalMaintaining:
+		preset, err := macro.Get(goal.MacroPreset)
+		if err != nil {
+			return err
+		}
+
+		targets := preset.Split(goal.CaloriesToMaintain, goal.TargetWeight)
+		update.Flag = constants.WeightGoalMaintain
+		update.DailyCalorieBudget = goal.CaloriesToMaintain
+		update.ProteinTarget = targets.Protein
+		update.CarbohydrateTarget = targets.Carbohydrate
+		update.FatTarget = targets.Fat
+		update.FibreTarget = targets.Fibre
+	case constants.WeightGoalAbandoned, constants.WeightGoalSuperseded:
+		update.EndedAt = sql.NullTime{Time: now, Valid: true}
+		update.EndWeight = weight
+	}
+
+	if err := service.tables.weightGoal.Update(ctx, goal.ID, &update); err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	goal.Status = status
+	return nil
+}
+
+// currentWeightGoal returns the goal of the profile that is not over yet.
+func currentWeightGoal(ctx context.Context, tb common.Repository[model.WeightGoal, string], profileID string) (*model.WeightGoal, error) {
+	wg, err := tb.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profileID),
+			goqu.C("status").In(openGoalStatuses),
+		},
+		Sort:  []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(wg) < 1 {
+		return nil, fmt.Errorf("%w", ErrNotFound)
+	}
+
+	return wg[0], nil
+}
+
+func weightGoalSummary(goal *model.WeightGoal) dto.WeightGoalSummary {
+	res := dto.WeightGoalSummary{
+		ID:             goal.ID,
+		Status:         goal.Status,
+		Flag:           goal.Flag,
+		StartingWeight: goal.StartingWeight,
+		StartingDate:   goal.StartingDate.Format(shortdDateLayout),
+		TargetWeight:   goal.TargetWeight,
+		TargetDate:     goal.TargetDate.Format(shortdDateLayout),
+		EndWeight:      goal.EndWeight,
+		EndedAt:        goal.EndedAt.Time.Format(shortdDateLayout),
+		Days:           int(goal.EndedAt.Time.Sub(goal.StartingDate).Hours() / 24),
+		Achieved:       goal.AchievedAt.Valid,
+	}
+
+	if goal.EndWeight > 0 {
+		res.WeightChange = roundHundredth(goal.EndWeight - goal.StartingWeight)
+	}
+
+	if goal.AchievedAt.Valid {
+		res.AchievedAt = goal.AchievedAt.Time.Format(shortdDateLayout)
+	}
+
+	return res
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"database/sql"
+	"monorepo/internal/dto"
+	"monorepo/services/fitness/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_weightGoalSummary(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days int) sql.NullTime {
+		return sql.NullTime{Time: start.AddDate(0, 0, days), Valid: true}
+	}
+
+	tests := []struct {
+		name string
+		goal *model.WeightGoal
+		want dto.WeightGoalSummary
+	}{
+		{
+			name: "Achieved then superseded",
+			goal: &model.WeightGoal{
+				ID: "01J", Status: "superseded", Flag: "maintain",
+				StartingWeight: 80, StartingDate: start, TargetWeight: 75, TargetDate: start.AddDate(0, 0, 70),
+				AchievedAt: at(63), EndedAt: at(120), EndWeight: 74.6,
+			},
+			want: dto.WeightGoalSummary{
+				ID: "01J", Status: "superseded", Flag: "maintain",
+				StartingWeight: 80, StartingDate: "2024-03-01", TargetWeight: 75, TargetDate: "2024-05-10",
+				EndWeight: 74.6, EndedAt: "2024-06-29", WeightChange: -5.4, Days: 120,
+				Achieved: true, AchievedAt: "2024-05-03",
+			},
+		},
+		{
+			name: "Abandoned without a weight",
+			goal: &model.WeightGoal{
+				ID: "01K", Status: "abandoned", Flag: "gain",
+				StartingWeight: 55, StartingDate: start, TargetWeight: 60, TargetDate: start.AddDate(0, 0, 140),
+				EndedAt: at(30),
+			},
+			want: dto.WeightGoalSummary{
+				ID: "01K", Status: "abandoned", Flag: "gain",
+				StartingWeight: 55, StartingDate: "2024-03-01", TargetWeight: 60, TargetDate: "2024-07-19",
+				EndedAt: "2024-03-31", Days: 30,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weightGoalSummary(tt.goal); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("weightGoalSummary() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_replaceGoalStatement(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 7, 0, 0, 0, time.UTC)
+	goal := &model.WeightGoal{ID: "02J", ProfileID: "profile", StartingWeight: 80, TargetWeight: 75, Status: "active", CreatedAt: now}
+	current := &model.WeightGoal{ID: "01J", ProfileID: "profile", Status: "achieved"}
+
+	stmt, _, err := replaceGoalStatement(goal, nil, 0, now)
+	if err != nil {
+		t.Fatalf("replaceGoalStatement() error = %v", err)
+	}
+	if !strings.HasPrefix(stmt, `INSERT INTO "weight_goal"`) || strings.Contains(stmt, "superseded") {
+		t.Errorf("replaceGoalStatement() = %s, want a plain insert", stmt)
+	}
+
+	stmt, _, err = replaceGoalStatement(goal, current, 74.6, now)
+	if err != nil {
+		t.Fatalf("replaceGoalStatement() error = %v", err)
+	}
+
+	for _, part := range []string{
+		`WITH superseded AS (UPDATE "weight_goal" SET "end_weight"=74.6,"ended_at"='2024-03-01T07:00:00Z',"status"='superseded'`,
+		`WHERE (("id" = '01J') AND ("status" = 'achieved') AND ("deleted_at" IS NULL)) RETURNING "id")`,
+		`INSERT INTO "weight_goal" ("created_at", "id", "profile_id", "starting_weight", "status", "target_weight") SELECT '2024-03-01T07:00:00Z', '02J', 'profile', 80, 'active', 75`,
+		"WHERE EXISTS (SELECT 1 FROM superseded)",
+	} {
+		if !strings.Contains(stmt, part) {
+			t.Errorf("replaceGoalStatement() = %s, want it to contain %s", stmt, part)
+		}
+	}
+}
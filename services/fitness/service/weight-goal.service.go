@@ -4,19 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"monorepo/internal/constants"
 	"monorepo/internal/dto"
 	"monorepo/pkg/common"
+	"monorepo/pkg/energy"
+	"monorepo/pkg/macro"
+	"monorepo/pkg/units"
+	"monorepo/pkg/utils"
+	clinicModels "monorepo/services/clinic/models"
 	"monorepo/services/fitness/model"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/doug-martin/goqu/v9"
-	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/go-playground/validator/v10"
 	"github.com/oklog/ulid/v2"
 )
@@ -26,12 +29,20 @@ const shortdDateLayout = "2006-01-02"
 func NewWeightGoalService(
 	tbWeightGoal common.Repository[model.WeightGoal, string],
 	tbWeightHistory common.Repository[model.WeightHistory, string],
+	tbClinic common.Repository[clinicModels.Clinic, string],
+	tbFoodDiary common.Repository[model.FoodDiary, string],
+	tbExerciseLog common.Repository[model.ExerciseLog, string],
+	tbCalorieAdjustment common.Repository[model.CalorieAdjustment, string],
 	profileService ProfileServiceInterface,
 ) *WeightGoalService {
 	service := &WeightGoalService{}
 	service.validate = validator.New()
 	service.tables.weightGoal = tbWeightGoal
 	service.tables.weightHistory = tbWeightHistory
+	service.tables.clinic = tbClinic
+	service.tables.foodDiary = tbFoodDiary
+	service.tables.exerciseLog = tbExerciseLog
+	service.tables.calorieAdjustment = tbCalorieAdjustment
 	service.profileService = profileService
 
 	return service
@@ -40,27 +51,16 @@ func NewWeightGoalService(
 type WeightGoalService struct {
 	validate *validator.Validate
 	tables   struct {
-		weightGoal    common.Repository[model.WeightGoal, string]
-		weightHistory common.Repository[model.WeightHistory, string]
+		weightGoal        common.Repository[model.WeightGoal, string]
+		weightHistory     common.Repository[model.WeightHistory, string]
+		clinic            common.Repository[clinicModels.Clinic, string]
+		foodDiary         common.Repository[model.FoodDiary, string]
+		exerciseLog       common.Repository[model.ExerciseLog, string]
+		calorieAdjustment common.Repository[model.CalorieAdjustment, string]
 	}
 	profileService ProfileServiceInterface
 }
 
-func (service *WeightGoalService) IsWeightGoalExists(ctx context.Context, profileID string) (bool, error) {
-	existing, err := service.tables.weightGoal.List(ctx, &common.FilterOptions{
-		Filter: []exp.Expression{
-			goqu.C("profile_id").Eq(profileID),
-		},
-		Page:  1,
-		Limit: 1,
-	})
-	if err != nil {
-		return false, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
-	}
-
-	return len(existing) > 0, nil
-}
-
 func (service *WeightGoalService) CreateWightGoal(ctx context.Context, body dto.CreateWeightGoalRequest) (*dto.CreateWeightGoalResponse, error) {
 	now := time.Now()
 	profile, err := service.profileService.GetProfile(ctx)
@@ -68,14 +68,14 @@ func (service *WeightGoalService) CreateWightGoal(ctx context.Context, body dto.
 		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
 	}
 
-	// check exist
-	isExist, err := service.IsWeightGoalExists(ctx, profile.ID)
-	if err != nil {
+	if err := body.ToMetric(profile.Units); err != nil {
 		return nil, err
 	}
 
-	if isExist {
-		return nil, fmt.Errorf("%w", ErrWeightGoalExist)
+	// a goal in progress is archived once the new one is set
+	current, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
 	}
 
 	var wgFlag string
@@ -98,9 +98,26 @@ func (service *WeightGoalService) CreateWightGoal(ctx context.Context, body dto.
 		paceVal = constants.WeeklyWeightPaceStrictVal
 	}
 
-	caloriesToMaintain := math.Round(CalculateCalorieToMaintain(profile.Sex, body.StartingWeight, profile.Height, age, body.ActivityLevel)*100) / 100
+	subject := energySubject(profile, body.StartingWeight, body.BodyFat)
+	formula, err := service.energyFormula(ctx, body.Formula, body.ClinicID, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	preset, err := macro.Get(body.MacroPreset)
+	if err != nil {
+		return nil, err
+	}
+
+	caloriesToMaintain, err := CalculateCalorieToMaintain(formula, subject, body.ActivityLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	caloriesToMaintain = math.Round(caloriesToMaintain*100) / 100
 	dailyCaloriesBudget := math.Round(CalculateDailyCalorieBudget(caloriesToMaintain, paceVal, wgFlag)*100) / 100
 	targetDate := CalculateTargetDate(profile.Sex, age, profile.Height, body.StartingWeight, body.TargetWeight, paceVal, body.ActivityLevel, wgFlag, now)
+	targets := preset.Split(dailyCaloriesBudget, body.StartingWeight)
 
 	newWeightGoal := &model.WeightGoal{
 		ID:                 ulid.Make().String(),
@@ -115,11 +132,20 @@ func (service *WeightGoalService) CreateWightGoal(ctx context.Context, body dto.
 		CreatedAt:          now,
 		ActivityLevel:      body.ActivityLevel,
 		Pace:               body.Pace,
+		Formula:            formula.Name(),
+		BodyFat:            body.BodyFat,
+		MacroPreset:        preset.Name,
+		ProteinTarget:      targets.Protein,
+		CarbohydrateTarget: targets.Carbohydrate,
+		FatTarget:          targets.Fat,
+		FibreTarget:        targets.Fibre,
+		AutoCalibrate:      body.AutoCalibrate,
+		Status:             constants.WeightGoalActive,
 	}
 
 	// insert weight goal
-	if err := service.tables.weightGoal.Create(ctx, newWeightGoal); err != nil {
-		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	if err := service.replaceGoal(ctx, newWeightGoal, current, profile); err != nil {
+		return nil, err
 	}
 
 	newWeightHistory := &model.WeightHistory{
@@ -135,14 +161,15 @@ func (service *WeightGoalService) CreateWightGoal(ctx context.Context, body dto.
 	}
 
 	// update profile
-	if err := service.profileService.UpdateProfile(ctx, profile.UserID, dto.RequestUpdateProfile{
+	if err := service.profileService.UpdateProfile(ctx, profile.AccountID(), dto.RequestUpdateProfile{
 		Weight:        body.StartingWeight,
+		WeightUnit:    units.Kilogram,
 		ActivityLevel: body.ActivityLevel,
 	}); err != nil {
 		return nil, fmt.Errorf("%w; %w", ErrUpdateProfile, err)
 	}
 
-	return &dto.CreateWeightGoalResponse{
+	res := &dto.CreateWeightGoalResponse{
 		StartingWeight:      newWeightGoal.StartingWeight,
 		StartingDate:        newWeightGoal.StartingDate.Format(shortdDateLayout),
 		TargetWeight:        newWeightGoal.TargetWeight,
@@ -152,7 +179,16 @@ func (service *WeightGoalService) CreateWightGoal(ctx context.Context, body dto.
 		CaloriesToMaintain:  newWeightGoal.CaloriesToMaintain,
 		Flag:                newWeightGoal.Flag,
 		Pace:                newWeightGoal.Pace,
-	}, nil
+		Formula:             newWeightGoal.Formula,
+		BodyFat:             newWeightGoal.BodyFat,
+		MacroPreset:         newWeightGoal.MacroPreset,
+		Macros:              goalMacros(newWeightGoal),
+		AutoCalibrate:       autoCalibrates(newWeightGoal),
+		Status:              newWeightGoal.Status,
+	}
+
+	res.FromMetric(profile.Units)
+	return res, nil
 }
 
 func (service *WeightGoalService) GetWeightGoal(ctx context.Context) (*dto.GetWeightGoalResponse, error) {
@@ -161,35 +197,31 @@ func (service *WeightGoalService) GetWeightGoal(ctx context.Context) (*dto.GetWe
 		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
 	}
 
-	wg, err := service.tables.weightGoal.List(ctx, &common.FilterOptions{
-		Filter: []exp.Expression{
-			goqu.C("profile_id").Eq(profile.ID),
-		},
-		Sort:  []exp.OrderedExpression{goqu.I("updated_at").Desc()},
-		Page:  1,
-		Limit: 1,
-	})
-
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
 	if err != nil {
-		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
-	}
-
-	if len(wg) < 1 {
-		return nil, fmt.Errorf("%w", ErrNotFound)
+		return nil, err
 	}
 
 	res := dto.GetWeightGoalResponse{
-		StartingWeight:      wg[0].StartingWeight,
-		StartingDate:        wg[0].StartingDate.Format(shortdDateLayout),
-		TargetWeight:        wg[0].TargetWeight,
-		TargetDate:          wg[0].TargetDate.Format(shortdDateLayout),
-		ActivityLevel:       wg[0].ActivityLevel,
-		DailyCaloriesBudget: wg[0].DailyCalorieBudget,
-		CaloriesToMaintain:  wg[0].CaloriesToMaintain,
-		Flag:                wg[0].Flag,
-		Pace:                wg[0].Pace,
-	}
-
+		StartingWeight:      goal.StartingWeight,
+		StartingDate:        goal.StartingDate.Format(shortdDateLayout),
+		TargetWeight:        goal.TargetWeight,
+		TargetDate:          goal.TargetDate.Format(shortdDateLayout),
+		ActivityLevel:       goal.ActivityLevel,
+		DailyCaloriesBudget: goal.DailyCalorieBudget,
+		CaloriesToMaintain:  goal.CaloriesToMaintain,
+		Flag:                goal.Flag,
+		Pace:                goal.Pace,
+		Formula:             utils.Ternary(goal.Formula != "", goal.Formula, energy.Default),
+		BodyFat:             goal.BodyFat,
+		MacroPreset:         utils.Ternary(goal.MacroPreset != "", goal.MacroPreset, macro.Default),
+		Macros:              goalMacros(goal),
+		AutoCalibrate:       autoCalibrates(goal),
+		Status:              goal.Status,
+		AchievedAt:          formatNullDate(goal.AchievedAt),
+	}
+
+	res.FromMetric(profile.Units)
 	return &res, nil
 }
 
@@ -208,22 +240,19 @@ func (service *WeightGoalService) UpdateWeightGoal(ctx context.Context, body *dt
 		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
 	}
 
-	// get wg
-	wg, err := service.tables.weightGoal.List(ctx, &common.FilterOptions{
-		Filter: []exp.Expression{
-			goqu.C("profile_id").Eq(profile.ID),
-		},
-		Sort:  []exp.OrderedExpression{goqu.I("updated_at").Desc()},
-		Page:  1,
-		Limit: 1,
-	})
+	if err := body.ToMetric(profile.Units); err != nil {
+		return nil, err
+	}
 
+	// get wg
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
 	if err != nil {
-		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+		return nil, err
 	}
 
-	if len(wg) < 1 {
-		return nil, fmt.Errorf("%w", ErrNotFound)
+	// reached goals are not planned again, a new goal takes over instead
+	if goal.Status != constants.WeightGoalActive {
+		return nil, ErrWeightGoalNotActive
 	}
 
 	body.StartingDate = startDate.Format(time.RFC3339)
@@ -238,7 +267,7 @@ func (service *WeightGoalService) UpdateWeightGoal(ctx context.Context, body *dt
 	}
 
 	if startTime.IsZero() {
-		startDate = wg[0].StartingDate
+		startDate = goal.StartingDate
 	}
 
 	if startDate == dateNow {
@@ -247,35 +276,29 @@ func (service *WeightGoalService) UpdateWeightGoal(ctx context.Context, body *dt
 		updateWeightGoal.StartingWeight = body.StartingWeight
 	}
 
-	if body.TargetWeight > body.TargetWeight {
-		updateWeightGoal.Flag = constants.WeightGoalGain
-	} else {
-		updateWeightGoal.Flag = constants.WeightGoalLoss
-	}
-
 	age, _ := strconv.Atoi(profile.Age)
-	activLevel := wg[0].ActivityLevel
+	activLevel := goal.ActivityLevel
 	if body.ActivityLevel != "" {
 		activLevel = body.ActivityLevel
 	}
 
-	startWeight := wg[0].StartingWeight
+	startWeight := goal.StartingWeight
 	if body.StartingWeight != 0 {
 		startWeight = body.StartingWeight
 	}
 
-	targetWeight := wg[0].TargetWeight
+	targetWeight := goal.TargetWeight
 	if body.TargetWeight != 0 {
 		targetWeight = body.TargetWeight
 	}
 
-	pace := wg[0].Pace
+	pace := goal.Pace
 	if body.Pace != "" {
 		pace = body.Pace
 	}
 
 	var wgFlag string
-	if body.TargetWeight > body.StartingWeight {
+	if targetWeight > startWeight {
 		wgFlag = constants.WeightGoalGain
 	} else {
 		wgFlag = constants.WeightGoalLoss
@@ -291,10 +314,45 @@ func (service *WeightGoalService) UpdateWeightGoal(ctx context.Context, body *dt
 		paceVal = constants.WeeklyWeightPaceStrictVal
 	}
 
-	caloriesToMaintain := math.Round(CalculateCalorieToMaintain(profile.Sex, startWeight, profile.Height, age, activLevel)*100) / 100
+	// the goal keeps its formula unless another one or a clinic is chosen
+	formulaName := body.Formula
+	if formulaName == "" && body.ClinicID == "" {
+		formulaName = goal.Formula
+	}
+
+	bodyFat := goal.BodyFat
+	if body.BodyFat != nil {
+		bodyFat = body.BodyFat
+	}
+
+	subject := energySubject(profile, startWeight, bodyFat)
+	formula, err := service.energyFormula(ctx, formulaName, body.ClinicID, subject)
+	if err != nil {
+		return nil, err
+	}
+
+	preset, err := macro.Get(utils.Ternary(body.MacroPreset != "", body.MacroPreset, goal.MacroPreset))
+	if err != nil {
+		return nil, err
+	}
+
+	caloriesToMaintain, err := CalculateCalorieToMaintain(formula, subject, activLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	caloriesToMaintain = math.Round(caloriesToMaintain*100) / 100
 	dailyCaloriesBudget := math.Round(CalculateDailyCalorieBudget(caloriesToMaintain, paceVal, wgFlag)*100) / 100
 	targetDate := CalculateTargetDate(profile.Sex, age, profile.Height, startWeight, targetWeight, paceVal, activLevel, wgFlag, startDate)
-
+	targets := preset.Split(dailyCaloriesBudget, startWeight)
+
+	updateWeightGoal.Formula = formula.Name()
+	updateWeightGoal.BodyFat = bodyFat
+	updateWeightGoal.MacroPreset = preset.Name
+	updateWeightGoal.ProteinTarget = targets.Protein
+	updateWeightGoal.CarbohydrateTarget = targets.Carbohydrate
+	updateWeightGoal.FatTarget = targets.Fat
+	updateWeightGoal.FibreTarget = targets.Fibre
 	updateWeightGoal.CaloriesToMaintain = caloriesToMaintain
 	updateWeightGoal.DailyCalorieBudget = dailyCaloriesBudget
 	updateWeightGoal.TargetDate = targetDate
@@ -302,28 +360,21 @@ func (service *WeightGoalService) UpdateWeightGoal(ctx context.Context, body *dt
 	updateWeightGoal.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
 	// update wg
-	err = service.tables.weightGoal.Update(ctx, wg[0].ID, &updateWeightGoal)
+	err = service.tables.weightGoal.Update(ctx, goal.ID, &updateWeightGoal)
 	if err != nil {
 		return nil, err
 	}
 
-	updatedWG, err := service.tables.weightGoal.List(ctx, &common.FilterOptions{
-		Filter: []exp.Expression{
-			goqu.C("profile_id").Eq(profile.ID),
-		},
-		Sort:  []exp.OrderedExpression{goqu.I("updated_at").Desc()},
-		Page:  1,
-		Limit: 1,
-	})
-
+	updatedWG, err := service.tables.weightGoal.Get(ctx, goal.ID)
 	if err != nil {
 		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
 	}
 
 	// update profile
-	if err := service.profileService.UpdateProfile(ctx, profile.UserID, dto.RequestUpdateProfile{
+	if err := service.profileService.UpdateProfile(ctx, profile.AccountID(), dto.RequestUpdateProfile{
 		Weight:        body.CurrentWeight,
-		ActivityLevel: updatedWG[0].ActivityLevel,
+		WeightUnit:    units.Kilogram,
+		ActivityLevel: updatedWG.ActivityLevel,
 	}); err != nil {
 		return nil, fmt.Errorf("%w; %w", ErrUpdateProfile, err)
 	}
@@ -331,22 +382,30 @@ func (service *WeightGoalService) UpdateWeightGoal(ctx context.Context, body *dt
 	if _, err = service.PutWeightHistory(ctx, dto.CreateWeightHistoryRequest{
 		Weight: startWeight,
 		Date:   startDate.Format(shortdDateLayout),
+		Unit:   units.Kilogram,
 	}); err != nil {
 		return nil, err
 	}
 
 	res := dto.CreateWeightGoalResponse{
-		StartingWeight:      updatedWG[0].StartingWeight,
-		StartingDate:        updatedWG[0].StartingDate.Format(shortdDateLayout),
-		TargetWeight:        updatedWG[0].TargetWeight,
-		TargetDate:          updatedWG[0].TargetDate.Format(shortdDateLayout),
-		ActivityLevel:       updatedWG[0].ActivityLevel,
+		StartingWeight:      updatedWG.StartingWeight,
+		StartingDate:        updatedWG.StartingDate.Format(shortdDateLayout),
+		TargetWeight:        updatedWG.TargetWeight,
+		TargetDate:          updatedWG.TargetDate.Format(shortdDateLayout),
+		ActivityLevel:       updatedWG.ActivityLevel,
 		DailyCaloriesBudget: dailyCaloriesBudget,
 		CaloriesToMaintain:  caloriesToMaintain,
-		Flag:                updatedWG[0].Flag,
+		Flag:                updatedWG.Flag,
 		Pace:                pace,
+		Formula:             updatedWG.Formula,
+		BodyFat:             updatedWG.BodyFat,
+		MacroPreset:         updatedWG.MacroPreset,
+		Macros:              goalMacros(updatedWG),
+		AutoCalibrate:       autoCalibrates(updatedWG),
+		Status:              updatedWG.Status,
 	}
 
+	res.FromMetric(profile.Units)
 	return &res, nil
 }
 
@@ -361,6 +420,10 @@ func (service *WeightGoalService) WightGoalSimulation(ctx context.Context, body
 		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
 	}
 
+	if err := body.ToMetric(profile.Units); err != nil {
+		return nil, err
+	}
+
 	var wgFlag string
 	if body.TargetWeight > body.StartingWeight {
 		wgFlag = constants.WeightGoalGain
@@ -368,45 +431,156 @@ func (service *WeightGoalService) WightGoalSimulation(ctx context.Context, body
 		wgFlag = constants.WeightGoalLoss
 	}
 
-	age, _ := strconv.Atoi(profile.Age)
-	caloriesToMaintain := math.Round(CalculateCalorieToMaintain(profile.Sex, body.StartingWeight, profile.Height, age, body.ActivityLevel)*100) / 100
+	subject := energySubject(profile, body.StartingWeight, body.BodyFat)
+	formula, err := service.energyFormula(ctx, body.Formula, body.ClinicID, subject)
+	if err != nil {
+		return nil, err
+	}
 
-	paces := map[string]float64{
-		constants.WeeklyWeightPaceRelaxed: constants.WeeklyWeightPaceRelaxedVal,
-		constants.WeeklyWeightPaceNormal:  constants.WeeklyWeightPaceNormalVal,
-		constants.WeeklyWeightStrict:      constants.WeeklyWeightPaceStrictVal,
+	preset, err := macro.Get(body.MacroPreset)
+	if err != nil {
+		return nil, err
 	}
 
-	for name, val := range paces {
-		dailyCaloriesBudget := math.Round(CalculateDailyCalorieBudget(caloriesToMaintain, val, wgFlag)*100) / 100
-		targetDate := CalculateTargetDate(profile.Sex, age, profile.Height, body.StartingWeight, body.TargetWeight, val, body.ActivityLevel, wgFlag, now)
+	age, _ := strconv.Atoi(profile.Age)
 
-		pace := dto.WeightGoalPace{
-			Pace:                name,
-			DailyCaloriesBudget: dailyCaloriesBudget,
-			TargetDate:          targetDate.Format(shortdDateLayout),
+	// every formula the profile has the measurements for, side by side
+	for _, f := range energy.Formulas() {
+		bmr, err := CalculateBMR(f, subject)
+		if err != nil {
+			if f.Name() == formula.Name() {
+				return nil, err
+			}
+			continue
 		}
-		res.Pacing = append(res.Pacing, pace)
-	}
 
-	// set default order of paces
-	paceOrder := map[string]int{
-		constants.WeeklyWeightPaceRelaxed: 1,
-		constants.WeeklyWeightPaceNormal:  2,
-		constants.WeeklyWeightStrict:      3,
-	}
+		caloriesToMaintain := math.Round(bmr*ActivityFactor(body.ActivityLevel)*100) / 100
+		outcome := dto.WeightGoalFormula{
+			Formula:            f.Name(),
+			BMR:                math.Round(bmr*100) / 100,
+			CaloriesToMaintain: caloriesToMaintain,
+			Pacing:             simulatePacing(profile, age, body, preset, caloriesToMaintain, wgFlag, now),
+		}
+		res.Formulas = append(res.Formulas, outcome)
 
-	// Sort the pacing order using the default  paceOrder
-	sort.Slice(res.Pacing, func(i, j int) bool {
-		return paceOrder[res.Pacing[i].Pace] < paceOrder[res.Pacing[j].Pace]
-	})
+		if f.Name() == formula.Name() {
+			res.CaloriesToMaintain = outcome.CaloriesToMaintain
+			res.Pacing = outcome.Pacing
+		}
+	}
 
 	res.StartingWeight = body.StartingWeight
 	res.StartingDate = now.Format(shortdDateLayout)
 	res.TargetWeight = body.TargetWeight
 	res.ActivityLevel = body.ActivityLevel
-	res.CaloriesToMaintain = caloriesToMaintain
 	res.Flag = wgFlag
+	res.Formula = formula.Name()
+	res.MacroPreset = preset.Name
+	res.FromMetric(profile.Units)
 
 	return &res, nil
 }
+
+// simulatePacing returns the daily budget, macronutrient targets and target
+// date of every pace, from relaxed to strict.
+func simulatePacing(profile *dto.ResponseGetProfile, age int, body dto.SimulationWeightGoalRequest, preset macro.Preset, caloriesToMaintain float64, wgFlag string, now time.Time) []dto.WeightGoalPace {
+	paces := []struct {
+		name string
+		val  float64
+	}{
+		{constants.WeeklyWeightPaceRelaxed, constants.WeeklyWeightPaceRelaxedVal},
+		{constants.WeeklyWeightPaceNormal, constants.WeeklyWeightPaceNormalVal},
+		{constants.WeeklyWeightStrict, constants.WeeklyWeightPaceStrictVal},
+	}
+
+	pacing := make([]dto.WeightGoalPace, 0, len(paces))
+	for _, p := range paces {
+		dailyCaloriesBudget := math.Round(CalculateDailyCalorieBudget(caloriesToMaintain, p.val, wgFlag)*100) / 100
+		targetDate := CalculateTargetDate(profile.Sex, age, profile.Height, body.StartingWeight, body.TargetWeight, p.val, body.ActivityLevel, wgFlag, now)
+
+		pacing = append(pacing, dto.WeightGoalPace{
+			Pace:                p.name,
+			DailyCaloriesBudget: dailyCaloriesBudget,
+			TargetDate:          targetDate.Format(shortdDateLayout),
+			Macros:              macroTargets(preset.Split(dailyCaloriesBudget, body.StartingWeight)),
+		})
+	}
+
+	return pacing
+}
+
+// energyFormula returns the formula chosen for the goal, else the one of the
+// clinic, else the default. A clinic's formula the subject lacks measurements
+// for falls back to the default, the patient did not choose it.
+func (service *WeightGoalService) energyFormula(ctx context.Context, name, clinicID string, subject energy.Subject) (energy.Formula, error) {
+	if name != "" || clinicID == "" {
+		return energy.Get(name)
+	}
+
+	clinic, err := service.tables.clinic.Get(ctx, clinicID)
+	if errors.Is(err, ErrNoResult) {
+		return nil, ErrClinicNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	formula, err := energy.Get(clinic.EnergyFormula)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := formula.BMR(subject); err != nil {
+		return energy.Get(energy.Default)
+	}
+
+	return formula, nil
+}
+
+func energySubject(profile *dto.ResponseGetProfile, weight float64, bodyFat *float64) energy.Subject {
+	age, _ := strconv.Atoi(profile.Age)
+
+	return energy.Subject{
+		Sex:     profile.Sex,
+		Weight:  weight,
+		Height:  profile.Height,
+		Age:     age,
+		BodyFat: bodyFat,
+	}
+}
+
+// goalMacros returns the targets stored on the goal, goals created before
+// targets were stored get them derived from their budget.
+func formatNullDate(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+
+	return t.Time.Format(shortdDateLayout)
+}
+
+func goalMacros(wg *model.WeightGoal) *dto.MacroTargets {
+	if wg.ProteinTarget == 0 && wg.CarbohydrateTarget == 0 && wg.FatTarget == 0 {
+		preset, err := macro.Get(wg.MacroPreset)
+		if err != nil {
+			return nil
+		}
+
+		return macroTargets(preset.Split(wg.DailyCalorieBudget, wg.StartingWeight))
+	}
+
+	return &dto.MacroTargets{
+		Protein:      wg.ProteinTarget,
+		Carbohydrate: wg.CarbohydrateTarget,
+		Fat:          wg.FatTarget,
+		Fibre:        wg.FibreTarget,
+	}
+}
+
+func macroTargets(t macro.Targets) *dto.MacroTargets {
+	return &dto.MacroTargets{
+		Protein:      t.Protein,
+		Carbohydrate: t.Carbohydrate,
+		Fat:          t.Fat,
+		Fibre:        t.Fibre,
+	}
+}
@@ -16,6 +16,41 @@ import (
 	"github.com/golang/mock/gomock"
 )
 
+func TestWeightGoalService_WightGoalSimulationUnits(t *testing.T) {
+	ctx := context.WithValue(context.Background(), oauth.AccessTokenContext, "")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProfile := mock.NewMockProfileServiceInterface(ctrl)
+	mockProfile.EXPECT().GetProfile(ctx).Return(&dto.ResponseGetProfile{
+		Age:    "30",
+		Height: 160,
+		Sex:    "Male",
+		Units:  "imperial",
+	}, nil)
+
+	service := &WeightGoalService{profileService: mockProfile}
+
+	// 176.37 and 132.28 lb are 80 and 60 kg, in the units of the profile
+	got, err := service.WightGoalSimulation(ctx, dto.SimulationWeightGoalRequest{
+		StartingWeight: 176.37,
+		TargetWeight:   132.28,
+		ActivityLevel:  "Sadentary",
+	})
+	if err != nil {
+		t.Fatalf("WeightGoalService.WightGoalSimulation() error = %v", err)
+	}
+
+	if got.StartingWeight != 176.37 || got.TargetWeight != 132.28 || got.WeightUnit != "lb" {
+		t.Errorf("WeightGoalService.WightGoalSimulation() weights = %v, %v %s; want 176.37, 132.28 lb", got.StartingWeight, got.TargetWeight, got.WeightUnit)
+	}
+
+	if got.CaloriesToMaintain != 2117.38 {
+		t.Errorf("WeightGoalService.WightGoalSimulation() calories to maintain = %v, want 2117.38", got.CaloriesToMaintain)
+	}
+}
+
 func TestWeightGoalService_WightGoalSimulation(t *testing.T) {
 	mockTime := time.Date(2024, time.August, 12, 0, 0, 0, 0, time.UTC)
 	patches := gomonkey.ApplyFunc(time.Now, func() time.Time {
@@ -62,21 +97,59 @@ func TestWeightGoalService_WightGoalSimulation(t *testing.T) {
 				ActivityLevel:      "Sadentary",
 				CaloriesToMaintain: 2117.38,
 				Flag:               "loss",
+				Formula:            "harris_benedict",
+				MacroPreset:        "balanced",
+				WeightUnit:         "kg",
 				Pacing: []dto.WeightGoalPace{
 					{
 						Pace:                "relaxed",
 						DailyCaloriesBudget: 1842.38,
 						TargetDate:          "2026-02-23",
+						Macros:              &dto.MacroTargets{Protein: 96, Carbohydrate: 219, Fat: 65, Fibre: 26},
 					},
 					{
 						Pace:                "normal",
 						DailyCaloriesBudget: 1567.38,
 						TargetDate:          "2025-05-19",
+						Macros:              &dto.MacroTargets{Protein: 96, Carbohydrate: 178, Fat: 53, Fibre: 22},
 					},
 					{
 						Pace:                "strict",
 						DailyCaloriesBudget: 1017.38,
 						TargetDate:          "2024-12-30",
+						Macros:              &dto.MacroTargets{Protein: 96, Carbohydrate: 95, Fat: 28, Fibre: 14},
+					},
+				},
+				Formulas: []dto.WeightGoalFormula{
+					{
+						Formula:            "harris_benedict",
+						BMR:                1764.48,
+						CaloriesToMaintain: 2117.38,
+						Pacing: []dto.WeightGoalPace{
+							{Pace: "relaxed", DailyCaloriesBudget: 1842.38, TargetDate: "2026-02-23", Macros: &dto.MacroTargets{Protein: 96, Carbohydrate: 219, Fat: 65, Fibre: 26}},
+							{Pace: "normal", DailyCaloriesBudget: 1567.38, TargetDate: "2025-05-19", Macros: &dto.MacroTargets{Protein: 96, Carbohydrate: 178, Fat: 53, Fibre: 22}},
+							{Pace: "strict", DailyCaloriesBudget: 1017.38, TargetDate: "2024-12-30", Macros: &dto.MacroTargets{Protein: 96, Carbohydrate: 95, Fat: 28, Fibre: 14}},
+						},
+					},
+					{
+						Formula:            "harris_benedict_revised",
+						BMR:                1757.65,
+						CaloriesToMaintain: 2109.18,
+						Pacing: []dto.WeightGoalPace{
+							{Pace: "relaxed", DailyCaloriesBudget: 1834.18, TargetDate: "2026-02-23", Macros: &dto.MacroTargets{Protein: 96, Carbohydrate: 218, Fat: 64, Fibre: 26}},
+							{Pace: "normal", DailyCaloriesBudget: 1559.18, TargetDate: "2025-05-19", Macros: &dto.MacroTargets{Protein: 96, Carbohydrate: 176, Fat: 52, Fibre: 22}},
+							{Pace: "strict", DailyCaloriesBudget: 1009.18, TargetDate: "2024-12-30", Macros: &dto.MacroTargets{Protein: 96, Carbohydrate: 94, Fat: 28, Fibre: 14}},
+						},
+					},
+					{
+						Formula:            "mifflin_st_jeor",
+						BMR:                1655,
+						CaloriesToMaintain: 1986,
+						Pacing: []dto.WeightGoalPace{
+							{Pace: "relaxed", DailyCaloriesBudget: 1711, TargetDate: "2026-02-23", Macros: &dto.MacroTargets{Protein: 96, Carbohydrate: 199, Fat: 59, Fibre: 24}},
+							{Pace: "normal", DailyCaloriesBudget: 1436, TargetDate: "2025-05-19", Macros: &dto.MacroTargets{Protein: 96, Carbohydrate: 158, Fat: 47, Fibre: 20}},
+							{Pace: "strict", DailyCaloriesBudget: 886, TargetDate: "2024-12-30", Macros: &dto.MacroTargets{Protein: 89, Carbohydrate: 80, Fat: 24, Fibre: 12}},
+						},
 					},
 				},
 			},
@@ -108,26 +181,108 @@ func TestWeightGoalService_WightGoalSimulation(t *testing.T) {
 				ActivityLevel:      "Sadentary",
 				CaloriesToMaintain: 1787.38,
 				Flag:               "gain",
+				Formula:            "harris_benedict",
+				MacroPreset:        "balanced",
+				WeightUnit:         "kg",
 				Pacing: []dto.WeightGoalPace{
 					{
 						Pace:                "relaxed",
 						DailyCaloriesBudget: 2062.38,
 						TargetDate:          "2025-05-19",
+						Macros:              &dto.MacroTargets{Protein: 72, Carbohydrate: 266, Fat: 79, Fibre: 29},
 					},
 					{
 						Pace:                "normal",
 						DailyCaloriesBudget: 2337.38,
 						TargetDate:          "2024-12-30",
+						Macros:              &dto.MacroTargets{Protein: 72, Carbohydrate: 307, Fat: 91, Fibre: 33},
 					},
 					{
 						Pace:                "strict",
 						DailyCaloriesBudget: 2887.38,
 						TargetDate:          "2024-10-21",
+						Macros:              &dto.MacroTargets{Protein: 72, Carbohydrate: 390, Fat: 116, Fibre: 40},
+					},
+				},
+				Formulas: []dto.WeightGoalFormula{
+					{
+						Formula:            "harris_benedict",
+						BMR:                1489.48,
+						CaloriesToMaintain: 1787.38,
+						Pacing: []dto.WeightGoalPace{
+							{Pace: "relaxed", DailyCaloriesBudget: 2062.38, TargetDate: "2025-05-19", Macros: &dto.MacroTargets{Protein: 72, Carbohydrate: 266, Fat: 79, Fibre: 29}},
+							{Pace: "normal", DailyCaloriesBudget: 2337.38, TargetDate: "2024-12-30", Macros: &dto.MacroTargets{Protein: 72, Carbohydrate: 307, Fat: 91, Fibre: 33}},
+							{Pace: "strict", DailyCaloriesBudget: 2887.38, TargetDate: "2024-10-21", Macros: &dto.MacroTargets{Protein: 72, Carbohydrate: 390, Fat: 116, Fibre: 40}},
+						},
+					},
+					{
+						Formula:            "harris_benedict_revised",
+						BMR:                1489.71,
+						CaloriesToMaintain: 1787.65,
+						Pacing: []dto.WeightGoalPace{
+							{Pace: "relaxed", DailyCaloriesBudget: 2062.65, TargetDate: "2025-05-19", Macros: &dto.MacroTargets{Protein: 72, Carbohydrate: 266, Fat: 79, Fibre: 29}},
+							{Pace: "normal", DailyCaloriesBudget: 2337.65, TargetDate: "2024-12-30", Macros: &dto.MacroTargets{Protein: 72, Carbohydrate: 307, Fat: 91, Fibre: 33}},
+							{Pace: "strict", DailyCaloriesBudget: 2887.65, TargetDate: "2024-10-21", Macros: &dto.MacroTargets{Protein: 72, Carbohydrate: 390, Fat: 116, Fibre: 40}},
+						},
+					},
+					{
+						Formula:            "mifflin_st_jeor",
+						BMR:                1455,
+						CaloriesToMaintain: 1746,
+						Pacing: []dto.WeightGoalPace{
+							{Pace: "relaxed", DailyCaloriesBudget: 2021, TargetDate: "2025-05-19", Macros: &dto.MacroTargets{Protein: 72, Carbohydrate: 260, Fat: 77, Fibre: 28}},
+							{Pace: "normal", DailyCaloriesBudget: 2296, TargetDate: "2024-12-30", Macros: &dto.MacroTargets{Protein: 72, Carbohydrate: 301, Fat: 89, Fibre: 32}},
+							{Pace: "strict", DailyCaloriesBudget: 2846, TargetDate: "2024-10-21", Macros: &dto.MacroTargets{Protein: 72, Carbohydrate: 384, Fat: 114, Fibre: 40}},
+						},
 					},
 				},
 			},
 			wantErr: false,
 		},
+		{
+			name: "Error sex unknown to the formula",
+			args: args{
+				ctx: context.WithValue(context.Background(), oauth.AccessTokenContext, ""),
+				body: dto.SimulationWeightGoalRequest{
+					StartingWeight: 80,
+					TargetWeight:   60,
+					ActivityLevel:  "Sadentary",
+				},
+			},
+			setupMocks: func(ctrl *gomock.Controller) ProfileServiceInterface {
+				mockProfile := mock.NewMockProfileServiceInterface(ctrl)
+				mockProfile.EXPECT().GetProfile(ctx).Return(&dto.ResponseGetProfile{
+					Age:    "30",
+					Height: 160,
+				}, nil)
+				return mockProfile
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Error unknown macronutrient preset",
+			args: args{
+				ctx: context.WithValue(context.Background(), oauth.AccessTokenContext, ""),
+				body: dto.SimulationWeightGoalRequest{
+					StartingWeight: 80,
+					TargetWeight:   60,
+					ActivityLevel:  "Sadentary",
+					MacroPreset:    "carnivore",
+				},
+			},
+			setupMocks: func(ctrl *gomock.Controller) ProfileServiceInterface {
+				mockProfile := mock.NewMockProfileServiceInterface(ctrl)
+				mockProfile.EXPECT().GetProfile(ctx).Return(&dto.ResponseGetProfile{
+					Age:    "30",
+					Height: 160,
+					Sex:    "Male",
+				}, nil)
+				return mockProfile
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "Error get profile",
 			args: args{
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"monorepo/internal/constants"
 	"monorepo/internal/dto"
@@ -55,6 +56,10 @@ func (service *WeightGoalService) PutWeightHistory(ctx context.Context, body dto
 		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
 	}
 
+	if err := body.ToMetric(profile.Units); err != nil {
+		return nil, err
+	}
+
 	// check exist
 	isExist, whExist, err := service.IsWeightHistoryExists(ctx, profile.ID, weightDate)
 	if err != nil {
@@ -84,40 +89,36 @@ func (service *WeightGoalService) PutWeightHistory(ctx context.Context, body dto
 		}
 	}
 
-	wg, err := service.tables.weightGoal.List(ctx, &common.FilterOptions{
-		Filter: []exp.Expression{
-			goqu.C("profile_id").Eq(profile.ID),
-		},
-		Sort:  []exp.OrderedExpression{goqu.I("updated_at").Desc()},
-		Page:  1,
-		Limit: 1,
-	})
+	res := &dto.WeightHistoryResponse{
+		Weight: newWeightHistory.Weight,
+		Date:   newWeightHistory.CreatedAt.Format(shortdDateLayout),
+	}
+	res.FromMetric(profile.Units)
 
-	if err != nil {
-		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
-	}
-
-	// update wg to maintain
-	if len(wg) > 0 {
-		goal := wg[0]
-		if IsAchieveGoal(body.Weight, goal.TargetWeight, goal.Flag) && (weightDate.After(goal.TargetDate) || weightDate.Format(shortdDateLayout) == goal.TargetDate.Format(shortdDateLayout)) {
-			updateWeightGoal := model.WeightGoal{
-				Flag:      constants.WeightGoalMaintain,
-				UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
-			}
-
-			// update wg
-			err = service.tables.weightGoal.Update(ctx, wg[0].ID, &updateWeightGoal)
-			if err != nil {
-				return nil, err
-			}
-		}
+	if err := service.weighed(ctx, profile.ID, weightDate, body.Weight); err != nil {
+		return nil, err
 	}
 
-	return &dto.WeightHistoryResponse{
-		Weight: newWeightHistory.Weight,
-		Date:   newWeightHistory.CreatedAt.Format(shortdDateLayout),
-	}, nil
+	return res, nil
+}
+
+// weighed follows up a weighing on the goal of the profile, if any.
+func (service *WeightGoalService) weighed(ctx context.Context, profileID string, date time.Time, weight float64) error {
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profileID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// reaching the target weight achieves the goal, whether to maintain it or
+	// start a new one is up to the user
+	sinceStart := date.Format(shortdDateLayout) >= goal.StartingDate.Format(shortdDateLayout)
+	if goal.Status == constants.WeightGoalActive && sinceStart && IsAchieveGoal(weight, goal.TargetWeight, goal.Flag) {
+		return service.transition(ctx, goal, constants.WeightGoalAchieved, 0)
+	}
+
+	return service.autoCalibrate(ctx, goal)
 }
 
 func (service *WeightGoalService) GetWeightHistory(ctx context.Context, filter dto.FilterGetWeightHistory) ([]dto.WeightHistoryResponse, error) {
@@ -156,9 +157,31 @@ func (service *WeightGoalService) GetWeightHistory(ctx context.Context, filter d
 			Weight: wh.Weight,
 			Date:   wh.CreatedAt.Format(shortdDateLayout),
 		}
+		data.FromMetric(profile.Units)
 
 		res = append(res, data)
 	}
 
 	return res, nil
 }
+
+// latestWeight is the last weight recorded in the history, else the profile's.
+func latestWeight(ctx context.Context, tb common.Repository[model.WeightHistory, string], profile *dto.ResponseGetProfile) (float64, error) {
+	histories, err := tb.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("profile_id").Eq(profile.ID)},
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(histories) > 0 && histories[0].Weight > 0 {
+		return histories[0].Weight, nil
+	} else if profile.Weight > 0 {
+		return profile.Weight, nil
+	}
+
+	return 0, ErrWeightUnknown
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/internal/repository"
+	"monorepo/pkg/common"
+	"monorepo/pkg/units"
+	"monorepo/pkg/weighing"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+)
+
+// a file spanning longer than this is likely not one profile's weighings, and
+// keeps the insert within the bind parameters postgres allows
+const weightImportMaxDays = 10000
+
+// ImportWeightHistory imports the weighings of a file exported by another app,
+// the latest of each day. Days already in the history are kept as they are, so
+// importing a file again imports nothing. A preview only reports what would be
+// imported.
+func (service *WeightGoalService) ImportWeightHistory(ctx context.Context, r io.Reader, req dto.WeightImportRequest) (*dto.WeightImportResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	unit := req.Unit
+	if unit == "" {
+		unit = units.WeightUnit(profile.Units)
+	}
+
+	parsed, err := weighing.Parse(r, req.Format, unit)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := service.weighedDays(ctx, profile.ID, parsed.Weighings)
+	if err != nil {
+		return nil, err
+	}
+
+	res, imports, err := planWeightImport(parsed, existing, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	res.Preview = req.Preview
+
+	if !req.Preview && len(imports) > 0 {
+		stmt, args, err := weightImportStatement(profile.ID, imports)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := service.tables.weightHistory.Raw(ctx, stmt, args...)
+		if err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+
+		imported, _ := result.RowsAffected()
+		res.Imported = int(imported)
+
+		// files often end before the last weighing put, the goal follows up on
+		// the latest weighing of the history
+		latest, err := service.tables.weightHistory.List(ctx, &common.FilterOptions{
+			Filter: []exp.Expression{goqu.C("profile_id").Eq(profile.ID)},
+			Sort:   []exp.OrderedExpression{goqu.I("created_at").Desc()},
+			Page:   1,
+			Limit:  1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+		}
+
+		if len(latest) > 0 {
+			if err := service.weighed(ctx, profile.ID, latest[0].CreatedAt, latest[0].Weight); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res.FromMetric(profile.Units)
+	return res, nil
+}
+
+// weighedDays are the days of the weighings already in the history.
+func (service *WeightGoalService) weighedDays(ctx context.Context, profileID string, weighings []weighing.Weighing) (map[string]bool, error) {
+	days := map[string]bool{}
+	if len(weighings) == 0 {
+		return days, nil
+	}
+
+	from, to := weighings[0].Day(), weighings[0].Day()
+	for _, w := range weighings {
+		from, to = min(from, w.Day()), max(to, w.Day())
+	}
+
+	for page := 1; ; page++ {
+		histories, err := service.tables.weightHistory.List(ctx, &common.FilterOptions{
+			Filter: []exp.Expression{
+				goqu.C("profile_id").Eq(profileID),
+				goqu.L("DATE(created_at)").Gte(from),
+				goqu.L("DATE(created_at)").Lte(to),
+			},
+			Select: []any{"created_at"},
+			Sort:   []exp.OrderedExpression{goqu.I("created_at").Asc()},
+			Page:   page,
+			Limit:  weightImportMaxDays,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+		}
+
+		for _, history := range histories {
+			days[history.CreatedAt.Format(shortdDateLayout)] = true
+		}
+
+		if len(histories) < weightImportMaxDays {
+			return days, nil
+		}
+	}
+}
+
+// planWeightImport reports the weighings of a file against the days already
+// in the history and returns the days to import, sorted. Weighings in the
+// future are row errors.
+func planWeightImport(parsed *weighing.Result, existing map[string]bool, now time.Time) (*dto.WeightImportResponse, []weighing.Weighing, error) {
+	res := &dto.WeightImportResponse{
+		Format:  parsed.Format,
+		Rows:    len(parsed.Weighings) + len(parsed.Errors),
+		Entries: []dto.WeightImportDay{},
+		Errors:  []dto.WeightImportError{},
+	}
+
+	for _, rowErr := range parsed.Errors {
+		res.Errors = append(res.Errors, dto.WeightImportError{Row: rowErr.Row, Error: rowErr.Err.Error()})
+	}
+
+	today := now.Format(shortdDateLayout)
+	weighings := make([]weighing.Weighing, 0, len(parsed.Weighings))
+	for _, w := range parsed.Weighings {
+		if w.Day() > today {
+			res.Errors = append(res.Errors, dto.WeightImportError{Row: w.Row, Error: fmt.Sprintf("date %s is in the future", w.Day())})
+			continue
+		}
+		weighings = append(weighings, w)
+	}
+	res.Invalid = len(res.Errors)
+
+	daily := weighing.Daily(weighings)
+	if len(daily) > weightImportMaxDays {
+		return nil, nil, ErrWeightImportTooLarge
+	}
+
+	imports := make([]weighing.Weighing, 0, len(daily))
+	for _, w := range daily {
+		day := dto.WeightImportDay{Row: w.Row, Date: w.Day(), Weight: w.Weight, Status: constants.WeightImportNew}
+		if existing[w.Day()] {
+			day.Status = constants.WeightImportExisting
+			res.Existing++
+		} else {
+			imports = append(imports, w)
+			res.New++
+		}
+		res.Entries = append(res.Entries, day)
+	}
+	res.Days = len(daily)
+
+	return res, imports, nil
+}
+
+// weightImportStatement inserts the weighings in one statement, so an import
+// is all or nothing. Days weighed since the preview are skipped rather than
+// duplicated.
+func weightImportStatement(profileID string, weighings []weighing.Weighing) (string, []any, error) {
+	rows := make([]string, 0, len(weighings))
+	args := make([]any, 0, 4*len(weighings))
+	for _, w := range weighings {
+		rows = append(rows, "(?, ?, ?::float8, ?::timestamptz)")
+		args = append(args, ulid.Make().String(), profileID, w.Weight, dayTime(w))
+	}
+
+	values := goqu.L("(VALUES "+strings.Join(rows, ", ")+") AS v(id, profile_id, weight, created_at)", args...)
+	weighed := goqu.From(goqu.T(repository.Tables.WeightHistory).As("w")).
+		Select(goqu.L("1")).
+		Where(
+			goqu.I("w.profile_id").Eq(goqu.I("v.profile_id")),
+			goqu.L("DATE(w.created_at) = DATE(v.created_at)"),
+			goqu.I("w.deleted_at").IsNull(),
+		)
+
+	return goqu.Dialect("postgres").Insert(repository.Tables.WeightHistory).
+		Cols("id", "profile_id", "weight", "created_at").
+		FromQuery(goqu.From(values).
+			Select("v.id", "v.profile_id", "v.weight", "v.created_at").
+			Where(goqu.L("NOT EXISTS ?", weighed))).
+		Prepared(true).
+		ToSQL()
+}
+
+// dayTime is the day of a weighing at midnight UTC, as a weighing put on a
+// date is recorded.
+func dayTime(w weighing.Weighing) time.Time {
+	day, _ := time.Parse(shortdDateLayout, w.Day())
+	return day
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"errors"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/pkg/weighing"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_planWeightImport(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.Local)
+	at := func(d, h int) time.Time {
+		return time.Date(2024, time.March, d, h, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name        string
+		parsed      *weighing.Result
+		existing    map[string]bool
+		want        *dto.WeightImportResponse
+		wantImports []weighing.Weighing
+	}{
+		{
+			name:   "Empty file",
+			parsed: &weighing.Result{Format: weighing.FormatCSV},
+			want: &dto.WeightImportResponse{
+				Format:  weighing.FormatCSV,
+				Entries: []dto.WeightImportDay{},
+				Errors:  []dto.WeightImportError{},
+			},
+			wantImports: []weighing.Weighing{},
+		},
+		{
+			name: "Latest weighing of new days",
+			parsed: &weighing.Result{
+				Format: weighing.FormatCSV,
+				Weighings: []weighing.Weighing{
+					{Row: 2, Time: at(1, 7), Weight: 81},
+					{Row: 3, Time: at(1, 21), Weight: 81.6},
+					{Row: 4, Time: at(2, 7), Weight: 80.8},
+					{Row: 5, Time: at(3, 7), Weight: 80.5},
+				},
+				Errors: []weighing.RowError{{Row: 6, Err: weighing.ErrInvalidWeight}},
+			},
+			existing: map[string]bool{"2024-03-02": true},
+			want: &dto.WeightImportResponse{
+				Format:   weighing.FormatCSV,
+				Rows:     5,
+				Invalid:  1,
+				Days:     3,
+				New:      2,
+				Existing: 1,
+				Entries: []dto.WeightImportDay{
+					{Row: 3, Date: "2024-03-01", Weight: 81.6, Status: constants.WeightImportNew},
+					{Row: 4, Date: "2024-03-02", Weight: 80.8, Status: constants.WeightImportExisting},
+					{Row: 5, Date: "2024-03-03", Weight: 80.5, Status: constants.WeightImportNew},
+				},
+				Errors: []dto.WeightImportError{{Row: 6, Error: weighing.ErrInvalidWeight.Error()}},
+			},
+			wantImports: []weighing.Weighing{
+				{Row: 3, Time: at(1, 21), Weight: 81.6},
+				{Row: 5, Time: at(3, 7), Weight: 80.5},
+			},
+		},
+		{
+			name: "Weighings in the future",
+			parsed: &weighing.Result{
+				Format: weighing.FormatFitbit,
+				Weighings: []weighing.Weighing{
+					{Row: 1, Time: at(10, 23), Weight: 80},
+					{Row: 2, Time: at(11, 7), Weight: 79.8},
+				},
+			},
+			want: &dto.WeightImportResponse{
+				Format:  weighing.FormatFitbit,
+				Rows:    2,
+				Invalid: 1,
+				Days:    1,
+				New:     1,
+				Entries: []dto.WeightImportDay{
+					{Row: 1, Date: "2024-03-10", Weight: 80, Status: constants.WeightImportNew},
+				},
+				Errors: []dto.WeightImportError{{Row: 2, Error: "date 2024-03-11 is in the future"}},
+			},
+			wantImports: []weighing.Weighing{{Row: 1, Time: at(10, 23), Weight: 80}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, imports, err := planWeightImport(tt.parsed, tt.existing, now)
+			if err != nil {
+				t.Fatalf("planWeightImport() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planWeightImport() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(imports, tt.wantImports) {
+				t.Errorf("planWeightImport() imports = %+v, want %+v", imports, tt.wantImports)
+			}
+		})
+	}
+}
+
+func Test_planWeightImportTooLarge(t *testing.T) {
+	start := time.Date(1990, time.January, 1, 7, 0, 0, 0, time.Local)
+	parsed := &weighing.Result{Format: weighing.FormatCSV}
+	for i := 0; i <= weightImportMaxDays; i++ {
+		parsed.Weighings = append(parsed.Weighings, weighing.Weighing{Row: i + 1, Time: start.AddDate(0, 0, i), Weight: 80})
+	}
+
+	if _, _, err := planWeightImport(parsed, nil, time.Now()); !errors.Is(err, ErrWeightImportTooLarge) {
+		t.Errorf("planWeightImport() error = %v, want %v", err, ErrWeightImportTooLarge)
+	}
+}
+
+func Test_weightImportStatement(t *testing.T) {
+	weighings := []weighing.Weighing{
+		{Time: time.Date(2024, time.March, 1, 21, 0, 0, 0, time.Local), Weight: 81.6},
+		{Time: time.Date(2024, time.March, 3, 7, 0, 0, 0, time.Local), Weight: 80.5},
+	}
+
+	stmt, args, err := weightImportStatement("profile", weighings)
+	if err != nil {
+		t.Fatalf("weightImportStatement() error = %v", err)
+	}
+
+	for _, part := range []string{
+		`INSERT INTO "weight_history" ("id", "profile_id", "weight", "created_at")`,
+		"(VALUES ($1, $2, $3::float8, $4::timestamptz), ($5, $6, $7::float8, $8::timestamptz)) AS v(id, profile_id, weight, created_at)",
+		"NOT EXISTS (SELECT 1",
+	} {
+		if !strings.Contains(stmt, part) {
+			t.Errorf("weightImportStatement() = %s, want it to contain %s", stmt, part)
+		}
+	}
+
+	if len(args) != 8 {
+		t.Fatalf("weightImportStatement() args = %v, want 8", args)
+	}
+	if args[1] != "profile" || args[2] != 81.6 || args[3] != time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("weightImportStatement() args = %v", args)
+	}
+}
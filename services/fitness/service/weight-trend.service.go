@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/pkg/common"
+	"monorepo/pkg/trend"
+	"monorepo/services/fitness/model"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+const (
+	weightTrendDays    = 90
+	weightTrendMaxDays = 365
+
+	// the weekly rate follows the trend of the last four weeks
+	weightTrendRateWindow = 28 * 24 * time.Hour
+)
+
+// GetWeightTrend smooths the weighings of the last days, 90 when not given, and
+// projects when the trend reaches the target weight of the goal.
+func (service *WeightGoalService) GetWeightTrend(ctx context.Context, days int) (*dto.WeightTrendResponse, error) {
+	profile, err := service.profileService.GetProfile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrGetProfile, err)
+	}
+
+	if days <= 0 {
+		days = weightTrendDays
+	}
+	days = min(days, weightTrendMaxDays)
+
+	now := time.Now()
+	from := now.AddDate(0, 0, -days)
+	histories, err := service.tables.weightHistory.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profile.ID),
+			goqu.L("DATE(created_at)").Gte(from.Format(shortdDateLayout)),
+		},
+		Sort:  []exp.OrderedExpression{goqu.I("created_at").Asc()},
+		Page:  1,
+		Limit: days + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	goal, err := currentWeightGoal(ctx, service.tables.weightGoal, profile.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	res := summarizeWeightTrend(histories, goal, now)
+	res.FromMetric(profile.Units)
+
+	return res, nil
+}
+
+// summarizeWeightTrend compares the trend with the plan of the goal, a straight
+// line from the starting weight to the target weight on the target date.
+func summarizeWeightTrend(histories []*model.WeightHistory, goal *model.WeightGoal, now time.Time) *dto.WeightTrendResponse {
+	res := &dto.WeightTrendResponse{Points: make([]dto.WeightTrendPoint, 0, len(histories))}
+	if len(histories) == 0 {
+		return res
+	}
+
+	smoothed := smoothHistory(histories)
+	for _, s := range smoothed {
+		res.Points = append(res.Points, dto.WeightTrendPoint{
+			Date:   s.Date.Format(shortdDateLayout),
+			Weight: s.Weight,
+			Trend:  roundHundredth(s.Trend),
+		})
+	}
+
+	last := smoothed[len(smoothed)-1]
+	rate := trend.WeeklyRate(smoothed, weightTrendRateWindow)
+	res.Trend = roundHundredth(last.Trend)
+	res.WeeklyRate = roundHundredth(rate)
+
+	if goal == nil || goal.TargetWeight <= 0 {
+		return res
+	}
+
+	flag := strings.ToLower(goal.Flag)
+	if flag != constants.WeightGoalGain && flag != constants.WeightGoalLoss {
+		return res
+	}
+
+	res.TargetWeight = goal.TargetWeight
+	res.TargetDate = goal.TargetDate.Format(shortdDateLayout)
+
+	if projected, ok := trend.Project(last.Date, last.Trend, goal.TargetWeight, rate); ok {
+		res.ProjectedDate = projected.Format(shortdDateLayout)
+	}
+
+	planned := goal.TargetDate.Sub(goal.StartingDate)
+	if planned <= 0 {
+		return res
+	}
+
+	elapsed := min(max(now.Sub(goal.StartingDate), 0), planned)
+	change := goal.TargetWeight - goal.StartingWeight
+	plannedWeight := goal.StartingWeight + change*elapsed.Hours()/planned.Hours()
+
+	res.PlannedWeight = roundHundredth(plannedWeight)
+	res.PlannedWeeklyRate = roundHundredth(change / (planned.Hours() / 24 / 7))
+	res.Pace = constants.WeightTrendBehind
+	if IsAchieveGoal(last.Trend, plannedWeight, flag) {
+		res.Pace = constants.WeightTrendAhead
+	}
+
+	return res
+}
+
+// smoothHistory runs the trend over weighings sorted by date, at day precision.
+func smoothHistory(histories []*model.WeightHistory) []trend.Smoothed {
+	points := make([]trend.Point, 0, len(histories))
+	for _, wh := range histories {
+		date := wh.CreatedAt.UTC()
+		points = append(points, trend.Point{
+			Date:   time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+			Weight: wh.Weight,
+		})
+	}
+
+	return trend.Smooth(points, trend.Alpha)
+}
+
+func roundHundredth(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"monorepo/internal/dto"
+	"monorepo/services/fitness/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_summarizeWeightTrend(t *testing.T) {
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	now := start.AddDate(0, 0, 28)
+
+	// half a kilo a week down from 80 kg, weighed every seventh day
+	var histories []*model.WeightHistory
+	for i := 0; i <= 4; i++ {
+		histories = append(histories, &model.WeightHistory{
+			Weight:    80 - 0.5*float64(i),
+			CreatedAt: start.AddDate(0, 0, 7*i),
+		})
+	}
+
+	goal := func(flag string, targetWeight float64, targetDate time.Time) *model.WeightGoal {
+		return &model.WeightGoal{
+			StartingWeight: 80,
+			StartingDate:   start,
+			TargetWeight:   targetWeight,
+			TargetDate:     targetDate,
+			Flag:           flag,
+		}
+	}
+
+	points := []dto.WeightTrendPoint{
+		{Date: "2024-03-01", Weight: 80, Trend: 80},
+		{Date: "2024-03-08", Weight: 79.5, Trend: 79.74},
+		{Date: "2024-03-15", Weight: 79, Trend: 79.35},
+		{Date: "2024-03-22", Weight: 78.5, Trend: 78.91},
+		{Date: "2024-03-29", Weight: 78, Trend: 78.43},
+	}
+
+	tests := []struct {
+		name      string
+		histories []*model.WeightHistory
+		goal      *model.WeightGoal
+		want      *dto.WeightTrendResponse
+	}{
+		{
+			name: "Without weighings",
+			goal: goal("loss", 75, start.AddDate(0, 0, 70)),
+			want: &dto.WeightTrendResponse{Points: []dto.WeightTrendPoint{}},
+		},
+		{
+			name:      "Without weight goal",
+			histories: histories,
+			want:      &dto.WeightTrendResponse{Points: points, Trend: 78.43, WeeklyRate: -0.4},
+		},
+		{
+			name:      "Maintaining",
+			histories: histories,
+			goal:      goal("maintain", 78, start),
+			want:      &dto.WeightTrendResponse{Points: points, Trend: 78.43, WeeklyRate: -0.4},
+		},
+		{
+			name:      "Ahead of a relaxed loss",
+			histories: histories,
+			goal:      goal("loss", 75, start.AddDate(0, 0, 140)),
+			want: &dto.WeightTrendResponse{
+				Points:            points,
+				Trend:             78.43,
+				WeeklyRate:        -0.4,
+				TargetWeight:      75,
+				TargetDate:        "2024-07-19",
+				ProjectedDate:     "2024-05-29",
+				PlannedWeight:     79,
+				PlannedWeeklyRate: -0.25,
+				Pace:              "ahead",
+			},
+		},
+		{
+			name:      "Behind a strict loss",
+			histories: histories,
+			goal:      goal("loss", 75, start.AddDate(0, 0, 35)),
+			want: &dto.WeightTrendResponse{
+				Points:            points,
+				Trend:             78.43,
+				WeeklyRate:        -0.4,
+				TargetWeight:      75,
+				TargetDate:        "2024-04-05",
+				ProjectedDate:     "2024-05-29",
+				PlannedWeight:     76,
+				PlannedWeeklyRate: -1,
+				Pace:              "behind",
+			},
+		},
+		{
+			name:      "Trend moving away from a gain",
+			histories: histories,
+			goal:      goal("gain", 85, start.AddDate(0, 0, 70)),
+			want: &dto.WeightTrendResponse{
+				Points:            points,
+				Trend:             78.43,
+				WeeklyRate:        -0.4,
+				TargetWeight:      85,
+				TargetDate:        "2024-05-10",
+				PlannedWeight:     82,
+				PlannedWeeklyRate: 0.5,
+				Pace:              "behind",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeWeightTrend(tt.histories, tt.goal, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summarizeWeightTrend() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"monorepo/internal/dto"
+	"monorepo/services/user/service"
+	"net/http"
+
+	"github.com/go-chi/oauth"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+func (rest *REST) RequestAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var request dto.RequestAccountDeletion
+	json.Unmarshal(payload, &request)
+
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		err = err.(validator.ValidationErrors)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := rest.deletionService.RequestDeletion(ctx, fClaims.UserID, &request)
+	if err != nil {
+		logrus.Errorf("failed to request account deletion: %s; err: %s", fClaims.UserID, err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Request Account Deletion"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseAccountDeletion]{Data: data, Message: "Your account is scheduled for deletion"})
+}
+
+func (rest *REST) GetAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	data, err := rest.deletionService.GetDeletion(ctx, fClaims.UserID)
+	if errors.Is(err, service.ErrDeletionNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get Account Deletion"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get Account Deletion"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseAccountDeletion]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) CancelAccountDeletion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	err := rest.deletionService.CancelDeletion(ctx, fClaims.UserID)
+	if errors.Is(err, service.ErrDeletionNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Cancel Account Deletion"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Cancel Account Deletion"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "Your account deletion has been canceled"})
+}
@@ -0,0 +1,323 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"monorepo/internal/dto"
+	"monorepo/services/user/service"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/oauth"
+	"github.com/go-playground/validator/v10"
+)
+
+func (rest *REST) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	pageStr := r.URL.Query().Get("page")
+	page, _ := strconv.Atoi(pageStr)
+
+	limitStr := r.URL.Query().Get("limit")
+	limit, _ := strconv.Atoi(limitStr)
+
+	data, err := rest.adminService.SearchUsers(ctx, fClaims.UserID, dto.FilterSearchUsers{
+		Query: r.URL.Query().Get("q"),
+		Page:  page,
+		Limit: limit,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Search Users"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[[]dto.ResponseAdminUser]{Data: &data, Message: "OK"})
+}
+
+func (rest *REST) GetAdminUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	data, err := rest.adminService.GetUser(ctx, fClaims.UserID, chi.URLParam(r, "id"))
+	if errors.Is(err, service.ErrUserNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get User"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get User"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseAdminUserDetail]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) ViewUserAsSupport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	data, err := rest.adminService.ViewAsSupport(ctx, fClaims.UserID, chi.URLParam(r, "id"))
+	if errors.Is(err, service.ErrUserNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to View User"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to View User"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseSupportView]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) SetAccountStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var req dto.RequestAccountStatus
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.adminService.SetStatus(ctx, fClaims.UserID, chi.URLParam(r, "id"), &req)
+	if errors.Is(err, service.ErrUserNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Change Account Status"})
+		return
+	} else if errors.Is(err, service.ErrAdminSelf) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Change Account Status"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Change Account Status"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "OK"})
+}
+
+func (rest *REST) AssignRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var req dto.RequestAssignRole
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.adminService.AssignRole(ctx, fClaims.UserID, chi.URLParam(r, "id"), &req)
+	if errors.Is(err, service.ErrUserNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Assign Role"})
+		return
+	} else if errors.Is(err, service.ErrAdminSelf) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Assign Role"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Assign Role"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "OK"})
+}
+
+func (rest *REST) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var req dto.RequestAdminPasswordReset
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.adminService.ForcePasswordReset(ctx, fClaims.UserID, chi.URLParam(r, "id"), &req)
+	if errors.Is(err, service.ErrUserNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Reset Password"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Reset Password"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "A reset link has been sent to the user's email"})
+}
+
+func (rest *REST) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	pageStr := r.URL.Query().Get("page")
+	page, _ := strconv.Atoi(pageStr)
+
+	limitStr := r.URL.Query().Get("limit")
+	limit, _ := strconv.Atoi(limitStr)
+
+	data, err := rest.adminService.ListAuditLogs(ctx, dto.FilterAuditLogs{
+		ActorID:      r.URL.Query().Get("actor_id"),
+		TargetUserID: r.URL.Query().Get("target_user_id"),
+		Page:         page,
+		Limit:        limit,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get Audit Logs"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[[]dto.ResponseAuditLog]{Data: &data, Message: "OK"})
+}
+
+func (rest *REST) AssignClinic(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var req dto.RequestClinicStaff
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.adminService.AssignClinic(ctx, fClaims.UserID, chi.URLParam(r, "id"), chi.URLParam(r, "clinicId"), &req)
+	if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrClinicNotFound) || errors.Is(err, service.ErrClinicStaffNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Assign Clinic"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Assign Clinic"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "OK"})
+}
+
+func (rest *REST) RemoveClinic(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var req dto.RequestClinicStaff
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.adminService.RemoveClinic(ctx, fClaims.UserID, chi.URLParam(r, "id"), chi.URLParam(r, "clinicId"), &req)
+	if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrClinicNotFound) || errors.Is(err, service.ErrClinicStaffNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Remove Clinic"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Remove Clinic"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "OK"})
+}
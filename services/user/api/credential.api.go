@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"monorepo/internal/dto"
+	"monorepo/services/user/service"
+	"net/http"
+
+	"github.com/go-chi/oauth"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+func (rest *REST) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var request dto.RequestChangePassword
+	json.Unmarshal(payload, &request)
+
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		err = err.(validator.ValidationErrors)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.userService.ChangePassword(ctx, fClaims.UserID, fClaims.SessionID, &request)
+	if err != nil {
+		logrus.Errorf("failed to change password: %s; err: %s", fClaims.UserID, err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Change Password"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "Your password has been updated"})
+}
+
+func (rest *REST) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var request dto.RequestChangeEmail
+	json.Unmarshal(payload, &request)
+
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		err = err.(validator.ValidationErrors)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.emailService.ChangeEmail(ctx, rest.env, fClaims.UserID, &request)
+	if err != nil {
+		logrus.Errorf("failed to change email: %s; err: %s", fClaims.UserID, err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Change Email"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "A confirmation link has been sent to your new email"})
+}
+
+func (rest *REST) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
+		return
+	}
+
+	var request dto.RequestConfirmEmailChange
+	json.Unmarshal(payload, &request)
+
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		err = err.(validator.ValidationErrors)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.emailService.ConfirmEmailChange(ctx, &request)
+	if err != nil {
+		logrus.Errorf("failed to confirm email change: %s; err: %s", request.UserID, err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Confirm Email"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "Your email has been updated, please sign in again"})
+}
+
+func (rest *REST) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
+		return
+	}
+
+	var request dto.RequestVerifyEmail
+	json.Unmarshal(payload, &request)
+
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		err = err.(validator.ValidationErrors)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.emailService.VerifyEmail(ctx, &request)
+	if err != nil {
+		logrus.Errorf("failed to verify email: %s; err: %s", request.UserID, err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Verify Email"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "Your email has been verified"})
+}
+
+func (rest *REST) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	err := rest.emailService.ResendEmailVerification(ctx, rest.env, fClaims.UserID)
+	if errors.Is(err, service.ErrTooManyRequests) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Send Email Verification"})
+		return
+	} else if err != nil {
+		logrus.Errorf("failed to resend email verification: %s; err: %s", fClaims.UserID, err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Send Email Verification"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "A verification link has been sent to your email"})
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"monorepo/internal/dto"
+	"monorepo/services/user/service"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/oauth"
+	"github.com/go-playground/validator/v10"
+)
+
+func (rest *REST) ListDependents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	data, err := rest.userService.ListDependents(ctx, fClaims.UserID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get Dependents"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[[]dto.ResponseDependent]{Data: &data, Message: "OK"})
+}
+
+func (rest *REST) CreateDependent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var req dto.RequestCreateDependent
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := rest.userService.CreateDependent(ctx, fClaims.UserID, &req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Create Dependent"})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseDependent]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) RemoveDependent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	err := rest.userService.RemoveDependent(ctx, fClaims.UserID, chi.URLParam(r, "id"))
+	if errors.Is(err, service.ErrNotGuardian) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Remove Dependent"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Remove Dependent"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "Dependent removed successfully"})
+}
+
+func (rest *REST) StartProfileHandover(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	data, err := rest.userService.StartHandover(ctx, fClaims.UserID, chi.URLParam(r, "id"))
+	if errors.Is(err, service.ErrNotGuardian) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Start Profile Handover"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Start Profile Handover"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseProfileHandover]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) ClaimProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var req dto.RequestClaimProfile
+	json.Unmarshal(payload, &req)
+
+	err = validator.New().Struct(req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.userService.ClaimProfile(ctx, fClaims.UserID, req.Token)
+	if errors.Is(err, service.ErrProfileExist) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Claim Profile"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Claim Profile"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "Profile claimed successfully"})
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"monorepo/internal/dto"
+	"monorepo/services/user/service"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/oauth"
+	"github.com/sirupsen/logrus"
+)
+
+func (rest *REST) RequestDataExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	data, err := rest.exportService.RequestExport(ctx, fClaims.UserID)
+	if err != nil {
+		logrus.Errorf("failed to request data export: %s; err: %s", fClaims.UserID, err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Request Data Export"})
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseDataExport]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) GetDataExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+	exportID := chi.URLParam(r, "id")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	data, err := rest.exportService.GetExport(ctx, fClaims.UserID, exportID)
+	if errors.Is(err, service.ErrExportNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get Data Export"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get Data Export"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseDataExport]{Data: data, Message: "OK"})
+}
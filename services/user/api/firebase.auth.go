@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"monorepo/internal/dto"
+	"monorepo/services/user/service"
 	"net/http"
 	"strings"
 
@@ -35,42 +37,51 @@ func (rest *REST) FirebaseAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	exists, err := rest.userService.IsHandleExists(ctx, signedInEmail)
+	payload, err := io.ReadAll(r.Body)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(dto.Object[any]{Error: "User Not Exist"})
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
 		return
 	}
 
-	if !exists {
-		payload, err := io.ReadAll(r.Body)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
-			return
-		}
+	var reqRegister dto.RequestRegisterUser
+	json.Unmarshal(payload, &reqRegister)
 
-		var reqRegister dto.RequestRegisterUser
-		json.Unmarshal(payload, &reqRegister)
+	// the account is found through the provider subject, its email may differ from the signed in one
+	user, created, err := rest.identityService.SignIn(ctx, fbaToken, &reqRegister)
+	if errors.Is(err, service.ErrIdentityUnverified) || errors.Is(err, service.ErrIdentityUnlinked) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	} else if err != nil {
+		logrus.Errorf("failed to sign in user: %s; err: %s", signedInEmail, err)
 
-		reqRegister.Provider = fbaToken.Firebase.SignInProvider
-		reqRegister.Email = signedInEmail
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Register User"})
+		return
+	}
 
-		_, err = rest.userService.RegisterUser(ctx, &reqRegister)
+	if created && !user.EmailVerified {
+		err = rest.emailService.SendEmailVerification(ctx, rest.env, user)
 		if err != nil {
-			logrus.Errorf("failed to register user: %s; err: %s", reqRegister.Email, err)
-
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Register User"})
-			return
+			logrus.Errorf("failed to send email verification: %s; err: %s", user.Handle, err)
 		}
+	}
 
+	challenge, err := rest.mfaService.CreateChallenge(ctx, user.Handle)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	} else if challenge != nil {
+		json.NewEncoder(w).Encode(dto.Object[dto.ResponseMFAChallenge]{Data: challenge, Message: "Two-factor authentication required"})
+		return
 	}
 
 	// login
 	r.ParseForm()
 	r.Form.Set("grant_type", "client_credentials")
-	r.Form.Set("client_id", signedInEmail)
+	r.Form.Set("client_id", user.Handle)
 	r.Form.Set("client_secret", rest.env.JWTSecret)
 	rest.oauthServer.ClientCredentials(w, r)
 }
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"monorepo/internal/dto"
+	"monorepo/services/user/service"
+	"net/http"
+
+	"github.com/go-chi/oauth"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// Login wraps the oauth password grant. Users with two-factor authentication
+// enabled receive an mfa challenge instead of a token.
+func (rest *REST) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	r.ParseForm()
+
+	if r.FormValue("grant_type") == "password" {
+		username, password := r.FormValue("username"), r.FormValue("password")
+		if username == "" {
+			username, password, _ = r.BasicAuth()
+		}
+
+		challenge, err := rest.mfaService.CreateLoginChallenge(ctx, username, password)
+		if err == nil && challenge != nil {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(dto.Object[dto.ResponseMFAChallenge]{Data: challenge, Message: "Two-factor authentication required"})
+			return
+		}
+	}
+
+	rest.oauthServer.UserCredentials(w, r)
+}
+
+func (rest *REST) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
+		return
+	}
+
+	var request dto.RequestMFALogin
+	json.Unmarshal(payload, &request)
+
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		err = err.(validator.ValidationErrors)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	handle, err := rest.mfaService.CompleteChallenge(ctx, &request)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Verify Code"})
+		return
+	}
+
+	// login
+	r.ParseForm()
+	r.Form.Set("grant_type", "client_credentials")
+	r.Form.Set("client_id", handle)
+	r.Form.Set("client_secret", rest.env.JWTSecret)
+	rest.oauthServer.ClientCredentials(w, r)
+}
+
+func (rest *REST) GetMFAStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	data, err := rest.mfaService.GetStatus(ctx, fClaims.UserID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get Two-Factor Status"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseMFAStatus]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	data, err := rest.mfaService.Enroll(ctx, fClaims.UserID)
+	if err != nil {
+		logrus.Errorf("failed to enroll mfa: %s; err: %s", fClaims.UserID, err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Enroll Two-Factor Authentication"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseMFAEnroll]{Data: data, Message: "OK"})
+}
+
+func (rest *REST) ActivateMFA(w http.ResponseWriter, r *http.Request) {
+	rest.handleMFACode(w, r, "Failed to Enable Two-Factor Authentication", rest.mfaService.Activate)
+}
+
+func (rest *REST) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	rest.handleMFACode(w, r, "Failed to Regenerate Recovery Codes", rest.mfaService.RegenerateRecoveryCodes)
+}
+
+func (rest *REST) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var request dto.RequestMFACode
+	json.Unmarshal(payload, &request)
+
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		err = err.(validator.ValidationErrors)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	err = rest.mfaService.Disable(ctx, fClaims.UserID, &request)
+	if errors.Is(err, service.ErrMFARequired) {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Disable Two-Factor Authentication"})
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Disable Two-Factor Authentication"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[any]{Message: "Two-factor authentication has been disabled"})
+}
+
+func (rest *REST) handleMFACode(
+	w http.ResponseWriter,
+	r *http.Request,
+	failMessage string,
+	fn func(ctx context.Context, userID string, body *dto.RequestMFACode) (*dto.ResponseMFARecoveryCodes, error),
+) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: "Failed to Parse Payload"})
+		return
+	}
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	var request dto.RequestMFACode
+	json.Unmarshal(payload, &request)
+
+	validate := validator.New()
+	err = validate.Struct(request)
+	if err != nil {
+		err = err.(validator.ValidationErrors)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := fn(ctx, fClaims.UserID, &request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: failMessage})
+		return
+	}
+
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseMFARecoveryCodes]{Data: data, Message: "OK"})
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"monorepo/internal/dto"
+	"net/http"
+
+	"github.com/go-chi/oauth"
+)
+
+// sessionAuthorizer rejects bearer tokens whose session has been revoked,
+// e.g. after a password change. It must run after oauthAuthorizer.
+func (rest *REST) sessionAuthorizer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		claims := ctx.Value(oauth.ClaimsContext)
+		c, _ := json.Marshal(claims)
+		var fClaims dto.FirebaseClaims
+		json.Unmarshal(c, &fClaims)
+
+		if err := rest.oauthVerifier.ValidateSession(ctx, fClaims.SessionID); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// mfaEnforcer blocks users whose role requires two-factor authentication until
+// they have enrolled. It must run after oauthAuthorizer.
+func (rest *REST) mfaEnforcer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := r.Context().Value(oauth.ClaimsContext)
+		c, _ := json.Marshal(claims)
+		var fClaims dto.FirebaseClaims
+		json.Unmarshal(c, &fClaims)
+
+		if fClaims.IsMFAEnrollmentRequired() {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(dto.Object[any]{Error: "two-factor authentication enrollment required"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -2,13 +2,17 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
+	"monorepo/internal/acting"
 	"monorepo/internal/config"
+	"monorepo/internal/constants"
 	"monorepo/internal/dto"
+	"monorepo/internal/role"
+	"monorepo/pkg/imaging"
+	"monorepo/pkg/units"
 	"monorepo/services/user/service"
 	"net/http"
-	"path/filepath"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -25,6 +29,14 @@ type REST struct {
 	decoder         *schema.Decoder
 	userService     *service.UserService
 	emailService    *service.EmailService
+	mfaService      *service.MFAService
+	deletionService *service.DeletionService
+	exportService   *service.ExportService
+	phoneService    *service.PhoneService
+	medicalService  *service.MedicalService
+	contactService  *service.EmergencyContactService
+	adminService    *service.AdminService
+	identityService *service.IdentityService
 	oauthServer     *oauth.BearerServer
 	oauthVerifier   *service.OauthVerifier
 	oauthAuthorizer func(next http.Handler) http.Handler
@@ -35,6 +47,14 @@ func NewREST(
 	oauthVerifier *service.OauthVerifier,
 	userService *service.UserService,
 	emailService *service.EmailService,
+	mfaService *service.MFAService,
+	deletionService *service.DeletionService,
+	exportService *service.ExportService,
+	phoneService *service.PhoneService,
+	medicalService *service.MedicalService,
+	contactService *service.EmergencyContactService,
+	adminService *service.AdminService,
+	identityService *service.IdentityService,
 	env *config.Environment,
 ) *REST {
 	r := chi.NewRouter()
@@ -50,6 +70,14 @@ func NewREST(
 		decoder:         schema.NewDecoder(),
 		userService:     userService,
 		emailService:    emailService,
+		mfaService:      mfaService,
+		deletionService: deletionService,
+		exportService:   exportService,
+		phoneService:    phoneService,
+		medicalService:  medicalService,
+		contactService:  contactService,
+		adminService:    adminService,
+		identityService: identityService,
 		oauthServer:     oauth.NewBearerServer(env.JWTSecret, time.Hour*4, oauthVerifier, nil),
 		oauthAuthorizer: oauth.Authorize(env.JWTSecret, nil),
 		oauthVerifier:   oauthVerifier,
@@ -59,19 +87,98 @@ func NewREST(
 
 func (rest *REST) InitializeRoutes() {
 	rest.Router.Get("/", rest.Healthcheck)
-	rest.Router.Post("/credentials/login", rest.oauthServer.UserCredentials)
+	rest.Router.Post("/credentials/login", rest.Login)
+	rest.Router.Post("/credentials/login/2fa", rest.LoginMFA)
 	rest.Router.Post("/credentials/firebase-auth", rest.FirebaseAuth)
 	rest.Router.Post("/credentials/forgot-password", rest.ForgotPassword)
 	rest.Router.Post("/credentials/update-password", rest.UpdatePassword)
+	rest.Router.Post("/credentials/email/confirm", rest.ConfirmEmailChange)
+	rest.Router.Post("/credentials/verify-email", rest.VerifyEmail)
+	rest.Router.Post("/emergency-contacts/consent", rest.AnswerContactConsent)
 	rest.Router.Group(func(r chi.Router) {
 		r.Use(rest.oauthAuthorizer)
+		r.Use(rest.sessionAuthorizer)
+
+		r.Get("/credentials/2fa", rest.GetMFAStatus)
+		r.Post("/credentials/2fa/enroll", rest.EnrollMFA)
+		r.Post("/credentials/2fa/verify", rest.ActivateMFA)
+		r.Post("/credentials/2fa/recovery-codes", rest.RegenerateRecoveryCodes)
+		r.Delete("/credentials/2fa", rest.DisableMFA)
+	})
+	rest.Router.Group(func(r chi.Router) {
+		r.Use(rest.oauthAuthorizer)
+		r.Use(rest.sessionAuthorizer)
+		r.Use(rest.mfaEnforcer)
+		r.Use(acting.Middleware)
+
+		r.Put("/credentials/password", rest.ChangePassword)
+		r.Put("/credentials/email", rest.ChangeEmail)
+		r.Post("/credentials/verify-email/resend", rest.ResendEmailVerification)
+		r.Get("/credentials/identities", rest.ListIdentities)
+		r.Post("/credentials/identities", rest.LinkIdentity)
+		r.Delete("/credentials/identities/{id}", rest.UnlinkIdentity)
+
+		r.Get("/account/deletion", rest.GetAccountDeletion)
+		r.Post("/account/deletion", rest.RequestAccountDeletion)
+		r.Delete("/account/deletion", rest.CancelAccountDeletion)
 
 		r.Get("/me", rest.MyCredential)
+		r.Post("/me/exports", rest.RequestDataExport)
+		r.Get("/me/exports/{id}", rest.GetDataExport)
 		r.Get("/profile", rest.GetProfile)
 		r.Post("/profile", rest.CreateProfile)
 		r.Patch("/profile/{id}", rest.UpdateProfile)
 		r.Patch("/profile/{id}/photo", rest.UploadPhoto)
+		r.Post("/profile/phone/otp", rest.SendPhoneOTP)
+		r.Post("/profile/phone/verify", rest.VerifyPhone)
 		r.Delete("/profile/{id}", rest.DeleteProfile)
+		r.Get("/profile/dependents", rest.ListDependents)
+		r.Post("/profile/dependents", rest.CreateDependent)
+		r.Delete("/profile/dependents/{id}", rest.RemoveDependent)
+		r.Post("/profile/dependents/{id}/handover", rest.StartProfileHandover)
+		r.Post("/profile/handover/claim", rest.ClaimProfile)
+		r.Get("/profile/allergies", rest.ListAllergies)
+		r.Post("/profile/allergies", rest.CreateAllergy)
+		r.Put("/profile/allergies/{id}", rest.UpdateAllergy)
+		r.Delete("/profile/allergies/{id}", rest.DeleteAllergy)
+		r.Get("/profile/conditions", rest.ListConditions)
+		r.Post("/profile/conditions", rest.CreateCondition)
+		r.Put("/profile/conditions/{id}", rest.UpdateCondition)
+		r.Delete("/profile/conditions/{id}", rest.DeleteCondition)
+		r.Get("/profile/medications", rest.ListMedications)
+		r.Post("/profile/medications", rest.CreateMedication)
+		r.Put("/profile/medications/{id}", rest.UpdateMedication)
+		r.Delete("/profile/medications/{id}", rest.DeleteMedication)
+		r.Get("/profile/medical-summary", rest.GetMedicalSummary)
+		r.Get("/profile/emergency-contacts", rest.ListEmergencyContacts)
+		r.Post("/profile/emergency-contacts", rest.CreateEmergencyContact)
+		r.Put("/profile/emergency-contacts/order", rest.ReorderEmergencyContacts)
+		r.Put("/profile/emergency-contacts/{id}", rest.UpdateEmergencyContact)
+		r.Delete("/profile/emergency-contacts/{id}", rest.DeleteEmergencyContact)
+		r.Post("/profile/emergency-contacts/{id}/consent", rest.RequestContactConsent)
+
+		r.Group(func(r chi.Router) {
+			r.Use(role.Authorizer(constants.RoleStaff, constants.RoleAdmin))
+
+			r.Get("/patients/{id}/medical-summary", rest.GetPatientMedicalSummary)
+			r.Get("/patients/{id}/emergency-contacts", rest.GetPatientEmergencyContacts)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(role.Authorizer(constants.RoleAdmin))
+
+			r.Get("/admin/email-templates", rest.ListEmailTemplates)
+			r.Get("/admin/email-templates/{name}", rest.PreviewEmailTemplate)
+			r.Get("/admin/users", rest.SearchUsers)
+			r.Get("/admin/users/{id}", rest.GetAdminUser)
+			r.Get("/admin/users/{id}/view", rest.ViewUserAsSupport)
+			r.Put("/admin/users/{id}/status", rest.SetAccountStatus)
+			r.Put("/admin/users/{id}/role", rest.AssignRole)
+			r.Post("/admin/users/{id}/password-reset", rest.ForcePasswordReset)
+			r.Put("/admin/users/{id}/clinics/{clinicId}", rest.AssignClinic)
+			r.Delete("/admin/users/{id}/clinics/{clinicId}", rest.RemoveClinic)
+			r.Get("/admin/audit-logs", rest.ListAuditLogs)
+		})
 	})
 }
 
@@ -153,20 +260,29 @@ func (rest *REST) GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := rest.userService.GetProfile(ctx, &fc)
+	data, err := rest.userService.GetProfile(ctx, &fc, acting.ProfileID(ctx))
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Get Profile"})
 		return
 	}
 
+	// services reading the profile ask for the units it is kept in
+	if profile, ok := data.(*dto.ResponseGetProfile); ok && r.URL.Query().Get("units") != units.Metric {
+		profile.FromMetric()
+	}
+
 	json.NewEncoder(w).Encode(dto.Object[any]{Data: &data, Message: "OK"})
 }
 
 func (rest *REST) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
-	userId := chi.URLParam(r, "id")
+
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
 
 	payload, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -192,8 +308,19 @@ func (rest *REST) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := rest.userService.UpdateProfile(ctx, userId, &req)
+	err = req.Validate()
 	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
+		return
+	}
+
+	data, err := rest.userService.UpdateProfile(ctx, fClaims.UserID, chi.URLParam(r, "id"), acting.ProfileID(ctx), &req)
+	if errors.Is(err, service.ErrProfileNotFound) || errors.Is(err, service.ErrNotGuardian) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Update Profile"})
+		return
+	} else if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Update Profile"})
 		return
@@ -205,9 +332,20 @@ func (rest *REST) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 func (rest *REST) DeleteProfile(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
-	userId := chi.URLParam(r, "id")
 
-	err := rest.userService.DeleteProfile(ctx, userId)
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	// the id in the path only names the account, the token decides whose it is
+	if chi.URLParam(r, "id") != fClaims.UserID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: service.ErrProfileNotFound.Error(), Message: "Failed to Delete Profile"})
+		return
+	}
+
+	err := rest.userService.DeleteProfile(ctx, fClaims.UserID)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Delete Profile"})
@@ -222,7 +360,7 @@ func (rest *REST) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	r.ParseMultipartForm(10 << 20)
 
-	file, handler, err := r.FormFile("file")
+	file, _, err := r.FormFile("file")
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error()})
@@ -230,17 +368,27 @@ func (rest *REST) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
-	t := time.Now()
-	nanoT := t.Format("20060102150405")
-	fileExt := filepath.Ext(handler.Filename)
-	userId := chi.URLParam(r, "id")
-	fileName := fmt.Sprintf("PP-%s-%s%s", userId, nanoT, fileExt)
-	data, err := rest.userService.UploadPhoto(ctx, rest.env, file, fileName, userId)
-	if err != nil {
+	claims := ctx.Value(oauth.ClaimsContext)
+	c, _ := json.Marshal(claims)
+	var fClaims dto.FirebaseClaims
+	json.Unmarshal(c, &fClaims)
+
+	if chi.URLParam(r, "id") != fClaims.UserID {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: service.ErrProfileNotFound.Error(), Message: "Failed to Change Profile Picture"})
+		return
+	}
+
+	data, err := rest.userService.UploadPhoto(ctx, file, fClaims.UserID)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Change Profile Picture"})
+		return
+	} else if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Change Profile Picture"})
 		return
 	}
 
-	json.NewEncoder(w).Encode(dto.Object[any]{Data: &data, Message: "OK"})
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseProfilePhoto]{Data: data, Message: "OK"})
 }
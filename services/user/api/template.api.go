@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"monorepo/internal/dto"
+	"monorepo/internal/mail"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (rest *REST) ListEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dto.Object[dto.ResponseEmailTemplates]{
+		Data:    &dto.ResponseEmailTemplates{Templates: mail.Templates, Locales: mail.Locales},
+		Message: "OK",
+	})
+}
+
+// PreviewEmailTemplate renders a template with sample data. format=html returns
+// the page itself so it can be opened in a browser, format=text the plain-text part.
+func (rest *REST) PreviewEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	locale := mail.NormalizeLocale(r.URL.Query().Get("locale"))
+
+	rendered, err := rest.emailService.PreviewTemplate(rest.env, name, locale)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		if errors.Is(err, mail.ErrUnknownTemplate) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		json.NewEncoder(w).Encode(dto.Object[any]{Error: err.Error(), Message: "Failed to Preview Email Template"})
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(rendered.HTML))
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(rendered.Text))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(dto.Object[dto.ResponseEmailPreview]{
+			Data: &dto.ResponseEmailPreview{
+				Template: name,
+				Locale:   locale,
+				Subject:  rendered.Subject,
+				Html:     rendered.HTML,
+				Text:     rendered.Text,
+			},
+			Message: "OK",
+		})
+	}
+}
@@ -5,18 +5,25 @@ import (
 	"fmt"
 	"monorepo/internal/config"
 	"monorepo/internal/db"
+	"monorepo/internal/mail"
 	"monorepo/internal/repository"
+	"monorepo/internal/sms"
+	"monorepo/internal/storage"
+	calendarModels "monorepo/services/calendar/models"
+	clinicModels "monorepo/services/clinic/models"
+	fitnessModel "monorepo/services/fitness/model"
+	notificationModels "monorepo/services/notification/models"
 	"monorepo/services/user/api"
 	"monorepo/services/user/models"
 	"monorepo/services/user/service"
 	"net/http"
+	"time"
 
 	firebase "firebase.google.com/go/v4"
 	"github.com/caarlos0/env"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/api/option"
-	"gopkg.in/gomail.v2"
 )
 
 func main() {
@@ -47,26 +54,95 @@ func main() {
 		Pass:    cfg.DbPass,
 	})
 
-	mailer := gomail.NewMessage()
-	dialer := gomail.NewDialer(
-		cfg.SMTPHost,
-		cfg.SMTPPort,
-		cfg.SMTPAuthEmail,
-		cfg.SMTPAuthPassword,
-	)
+	store, err := storage.NewStorage(cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	transport, err := mail.NewTransport(cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize mail transport: %v", err)
+	}
+
+	templates, err := mail.NewRegistry()
+	if err != nil {
+		logrus.Fatalf("Failed to parse email templates: %v", err)
+	}
+
+	smsSender, err := sms.NewSender(cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize sms provider: %v", err)
+	}
 
 	tbUser := repository.NewRepository[models.User, string](pgdb, repository.Tables.User)
 	tbProfile := repository.NewRepository[models.Profile, string](pgdb, repository.Tables.Profile)
 	tbResetPassword := repository.NewRepository[models.ResetPassword, string](pgdb, repository.Tables.ResetPassword)
+	tbSession := repository.NewRepository[models.Session, string](pgdb, repository.Tables.UserSession)
+	tbEmailChange := repository.NewRepository[models.EmailChange, string](pgdb, repository.Tables.EmailChange)
+	tbEmailVerification := repository.NewRepository[models.EmailVerification, string](pgdb, repository.Tables.EmailVerify)
+	tbMFA := repository.NewRepository[models.MFA, string](pgdb, repository.Tables.MFA)
+	tbRecoveryCode := repository.NewRepository[models.RecoveryCode, string](pgdb, repository.Tables.RecoveryCode)
+	tbMFAChallenge := repository.NewRepository[models.MFAChallenge, string](pgdb, repository.Tables.MFAChallenge)
+	tbDeletion := repository.NewRepository[models.AccountDeletion, string](pgdb, repository.Tables.Deletion)
+	tbDeletionLog := repository.NewRepository[models.AccountDeletionLog, string](pgdb, repository.Tables.DeletionLog)
+	tbDeletionCert := repository.NewRepository[models.DeletionCertificate, string](pgdb, repository.Tables.DeletionCert)
+	tbDataExport := repository.NewRepository[models.DataExport, string](pgdb, repository.Tables.DataExport)
+	tbEmailOutbox := repository.NewRepository[models.EmailOutbox, string](pgdb, repository.Tables.EmailOutbox)
+	tbPhoneVerification := repository.NewRepository[models.PhoneVerification, string](pgdb, repository.Tables.PhoneVerify)
+	tbGuardian := repository.NewRepository[models.ProfileGuardian, string](pgdb, repository.Tables.Guardian)
+	tbHandover := repository.NewRepository[models.ProfileHandover, string](pgdb, repository.Tables.Handover)
+	tbAllergy := repository.NewRepository[models.Allergy, string](pgdb, repository.Tables.Allergy)
+	tbCondition := repository.NewRepository[models.Condition, string](pgdb, repository.Tables.Condition)
+	tbMedication := repository.NewRepository[models.Medication, string](pgdb, repository.Tables.Medication)
+	tbContact := repository.NewRepository[models.EmergencyContact, string](pgdb, repository.Tables.Contact)
+	tbAdminAudit := repository.NewRepository[models.AdminAuditLog, string](pgdb, repository.Tables.AdminAudit)
+	tbIdentity := repository.NewRepository[models.UserIdentity, string](pgdb, repository.Tables.Identity)
+	tbClinic := repository.NewRepository[clinicModels.Clinic, string](pgdb, repository.Tables.Clinic)
+	tbClinicStaff := repository.NewRepository[models.ClinicStaff, string](pgdb, repository.Tables.ClinicStaff)
+	tbWeightGoal := repository.NewRepository[fitnessModel.WeightGoal, string](pgdb, repository.Tables.WeightGoal)
+	tbWeightHistory := repository.NewRepository[fitnessModel.WeightHistory, string](pgdb, repository.Tables.WeightHistory)
+	tbFoodDiary := repository.NewRepository[fitnessModel.FoodDiary, string](pgdb, repository.Tables.FoodDiary)
+	tbExerciseLog := repository.NewRepository[fitnessModel.ExerciseLog, string](pgdb, repository.Tables.ExerciseLog)
+	tbCalorieAdjustment := repository.NewRepository[fitnessModel.CalorieAdjustment, string](pgdb, repository.Tables.CalorieAdjust)
+	tbBodyMeasurement := repository.NewRepository[fitnessModel.BodyMeasurement, string](pgdb, repository.Tables.BodyMeasure)
+	tbEvent := repository.NewRepository[calendarModels.Event, string](pgdb, repository.Tables.Event)
+	tbUserMessage := repository.NewRepository[notificationModels.UserMessage, string](pgdb, repository.Tables.UserMessage)
+
+	userService := service.NewUserService(tbUser, tbProfile, tbSession, tbGuardian, tbHandover, fbaClient, store)
+	mfaService := service.NewMFAService(tbUser, tbMFA, tbRecoveryCode, tbMFAChallenge, cfg)
+	deletionService := service.NewDeletionService(tbUser, tbProfile, tbIdentity, tbDeletion, tbDeletionLog, tbDeletionCert, tbDataExport, fbaClient, store, cfg)
+	go deletionService.Run(ctx, time.Hour)
+	exportService := service.NewExportService(tbProfile, tbSession, tbDataExport, tbWeightGoal, tbWeightHistory, tbFoodDiary, tbExerciseLog, tbCalorieAdjustment, tbBodyMeasurement, tbEvent, tbUserMessage, tbAllergy, tbCondition, tbMedication, tbContact, store)
+	go exportService.Run(ctx, time.Minute)
+	outboxService := service.NewOutboxService(tbEmailOutbox, transport, cfg)
+	go outboxService.Run(ctx, time.Second*5)
+	emailService := service.NewEmailService(outboxService, templates, fbaClient, userService, tbUser, tbProfile, tbResetPassword, tbEmailChange, tbEmailVerification)
+
+	contactService := service.NewEmergencyContactService(userService, tbContact, tbEvent, tbAdminAudit, smsSender, cfg)
+	if err := contactService.NormalizePhones(ctx); err != nil {
+		logrus.Errorf("failed to normalize emergency contact phones; err: %s", err)
+	}
 
 	restAPI := api.NewREST(
-		service.NewOauthVerifier(tbUser, fbaClient, cfg),
-		service.NewUserService(tbUser, tbProfile, fbaClient),
-		service.NewEmailService(dialer, mailer, fbaClient, tbUser, tbProfile, tbResetPassword),
+		service.NewOauthVerifier(tbUser, tbSession, fbaClient, mfaService, cfg),
+		userService,
+		emailService,
+		mfaService,
+		deletionService,
+		exportService,
+		service.NewPhoneService(tbProfile, tbPhoneVerification, smsSender),
+		service.NewMedicalService(userService, tbProfile, tbAllergy, tbCondition, tbMedication, tbContact, tbEvent, tbAdminAudit),
+		contactService,
+		service.NewAdminService(userService, emailService, tbUser, tbProfile, tbAdminAudit, tbClinic, tbClinicStaff, fbaClient, cfg),
+		service.NewIdentityService(userService, tbUser, tbIdentity, fbaClient),
 		cfg,
 	)
 
 	restAPI.InitializeRoutes()
+	if h, ok := store.(storage.Handler); ok {
+		restAPI.Router.Mount("/files", h.Handler())
+	}
+
 	servicePort := fmt.Sprintf(":%d", cfg.ServicePort)
 	logrus.Infof("Starting HTTP server in port: %d", cfg.ServicePort)
 	if err := http.ListenAndServe(servicePort, restAPI.Router); err != nil {
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type AccountDeletion struct {
+	ID          string       `db:"id" goqu:"omitempty"`
+	UserID      string       `db:"user_id" goqu:"omitempty"`
+	Status      string       `db:"status" goqu:"omitempty"`
+	Reason      string       `db:"reason" goqu:"omitempty"`
+	ScheduledAt time.Time    `db:"scheduled_at" goqu:"omitempty"`
+	CanceledAt  sql.NullTime `db:"canceled_at" goqu:"omitempty"`
+	CompletedAt sql.NullTime `db:"completed_at" goqu:"omitempty"`
+	CreatedAt   time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt   sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
+
+type AccountDeletionLog struct {
+	ID           string       `db:"id" goqu:"omitempty"`
+	DeletionID   string       `db:"deletion_id" goqu:"omitempty"`
+	Step         string       `db:"step" goqu:"omitempty"`
+	AffectedRows int64        `db:"affected_rows"`
+	Error        string       `db:"error" goqu:"omitempty"`
+	CreatedAt    time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt    sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
+
+// DeletionCertificate proves an erasure took place. It holds no personal data
+// besides the opaque user id.
+type DeletionCertificate struct {
+	ID         string       `db:"id" goqu:"omitempty"`
+	DeletionID string       `db:"deletion_id" goqu:"omitempty"`
+	UserID     string       `db:"user_id" goqu:"omitempty"`
+	Summary    string       `db:"summary" goqu:"omitempty"`
+	Digest     string       `db:"digest" goqu:"omitempty"`
+	IssuedAt   time.Time    `db:"issued_at" goqu:"omitempty"`
+	DeletedAt  sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// AdminAuditLog records an action an admin took on, or a read of, a user account.
+type AdminAuditLog struct {
+	ID           string       `db:"id" goqu:"omitempty"`
+	ActorID      string       `db:"actor_id" goqu:"omitempty"`
+	Action       string       `db:"action" goqu:"omitempty"`
+	TargetUserID string       `db:"target_user_id" goqu:"omitempty"`
+	Detail       string       `db:"detail" goqu:"omitempty"`
+	CreatedAt    time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt    sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
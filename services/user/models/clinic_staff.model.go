@@ -0,0 +1,16 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ClinicStaff assigns a staff account to a clinic, whose patients it may read
+// around their appointments.
+type ClinicStaff struct {
+	ID        string       `db:"id" goqu:"omitempty"`
+	UserID    string       `db:"user_id" goqu:"omitempty"`
+	ClinicID  string       `db:"clinic_id" goqu:"omitempty"`
+	CreatedAt time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type DataExport struct {
+	ID          string       `db:"id" goqu:"omitempty"`
+	UserID      string       `db:"user_id" goqu:"omitempty"`
+	Status      string       `db:"status" goqu:"omitempty"`
+	FileName    string       `db:"file_name" goqu:"omitempty"`
+	Error       string       `db:"error" goqu:"omitempty"`
+	CompletedAt sql.NullTime `db:"completed_at" goqu:"omitempty"`
+	CreatedAt   time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt   sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
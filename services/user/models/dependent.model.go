@@ -0,0 +1,29 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ProfileGuardian links a profile to an account that manages it. Dependents
+// have no account of their own until the profile is handed over.
+type ProfileGuardian struct {
+	ID           string       `db:"id" goqu:"omitempty"`
+	ProfileID    string       `db:"profile_id" goqu:"omitempty"`
+	UserID       string       `db:"user_id" goqu:"omitempty"`
+	Relationship string       `db:"relationship" goqu:"omitempty"`
+	CreatedAt    time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt    sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
+
+type ProfileHandover struct {
+	ID        string       `db:"id" goqu:"omitempty"`
+	ProfileID string       `db:"profile_id" goqu:"omitempty"`
+	UserID    string       `db:"user_id" goqu:"omitempty"`
+	Token     string       `db:"token" goqu:"omitempty"`
+	ExpiresAt time.Time    `db:"expires_at" goqu:"omitempty"`
+	ClaimedBy *string      `db:"claimed_by" goqu:"omitempty"`
+	ClaimedAt sql.NullTime `db:"claimed_at" goqu:"omitempty"`
+	CreatedAt time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type EmailChange struct {
+	ID          string       `db:"id" goqu:"omitempty"`
+	UserID      string       `db:"user_id" goqu:"omitempty"`
+	NewEmail    string       `db:"new_email" goqu:"omitempty"`
+	ChangeToken string       `db:"change_token" goqu:"omitempty"`
+	IsUsed      bool         `db:"is_used" goqu:"omitempty"`
+	CreatedAt   time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt   sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
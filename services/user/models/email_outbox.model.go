@@ -0,0 +1,22 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type EmailOutbox struct {
+	ID            string       `db:"id" goqu:"omitempty"`
+	Sender        string       `db:"sender" goqu:"omitempty"`
+	Recipient     string       `db:"recipient" goqu:"omitempty"`
+	Subject       string       `db:"subject" goqu:"omitempty"`
+	Body          string       `db:"body" goqu:"omitempty"`
+	TextBody      string       `db:"text_body" goqu:"omitempty"`
+	Status        string       `db:"status" goqu:"omitempty"`
+	Attempts      int          `db:"attempts" goqu:"omitempty"`
+	NextAttemptAt time.Time    `db:"next_attempt_at" goqu:"omitempty"`
+	LastError     string       `db:"last_error" goqu:"omitempty"`
+	SentAt        sql.NullTime `db:"sent_at" goqu:"omitempty"`
+	CreatedAt     time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt     sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
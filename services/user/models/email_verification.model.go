@@ -0,0 +1,15 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type EmailVerification struct {
+	ID          string       `db:"id" goqu:"omitempty"`
+	UserID      string       `db:"user_id" goqu:"omitempty"`
+	VerifyToken string       `db:"verify_token" goqu:"omitempty"`
+	IsUsed      bool         `db:"is_used" goqu:"omitempty"`
+	CreatedAt   time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt   sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type EmergencyContact struct {
+	ID            string       `db:"id" json:"id" goqu:"omitempty"`
+	ProfileID     string       `db:"profile_id" json:"profile_id" goqu:"omitempty"`
+	Priority      int          `db:"priority" json:"priority" goqu:"omitempty"`
+	Relation      string       `db:"relation" json:"relation" goqu:"omitempty"`
+	Name          string       `db:"name" json:"name" goqu:"omitempty"`
+	CountryCode   string       `db:"country_code" json:"country_code" goqu:"omitempty"`
+	Phone         string       `db:"phone" json:"phone" goqu:"omitempty"`
+	Consent       string       `db:"consent" json:"consent" goqu:"omitempty"`
+	ConsentToken  *string      `db:"consent_token" json:"-"`
+	ConsentSentAt sql.NullTime `db:"consent_sent_at" json:"-" goqu:"omitempty"`
+	ConsentAt     sql.NullTime `db:"consent_at" json:"consent_at" goqu:"omitempty"`
+	CreatedAt     time.Time    `db:"created_at" json:"created_at" goqu:"omitempty"`
+	DeletedAt     sql.NullTime `db:"deleted_at" json:"-" goqu:"omitempty"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Allergy struct {
+	ID        string       `db:"id" json:"id" goqu:"omitempty"`
+	ProfileID string       `db:"profile_id" json:"profile_id" goqu:"omitempty"`
+	Substance string       `db:"substance" json:"substance" goqu:"omitempty"`
+	Category  string       `db:"category" json:"category" goqu:"omitempty"`
+	Code      *string      `db:"code" json:"code"`
+	Reaction  *string      `db:"reaction" json:"reaction"`
+	Severity  string       `db:"severity" json:"severity" goqu:"omitempty"`
+	CreatedAt time.Time    `db:"created_at" json:"created_at" goqu:"omitempty"`
+	DeletedAt sql.NullTime `db:"deleted_at" json:"-" goqu:"omitempty"`
+}
+
+type Condition struct {
+	ID        string       `db:"id" json:"id" goqu:"omitempty"`
+	ProfileID string       `db:"profile_id" json:"profile_id" goqu:"omitempty"`
+	Name      string       `db:"name" json:"name" goqu:"omitempty"`
+	Code      *string      `db:"code" json:"code"`
+	Status    string       `db:"status" json:"status" goqu:"omitempty"`
+	OnsetAt   *time.Time   `db:"onset_at" json:"onset_at"`
+	Note      *string      `db:"note" json:"note"`
+	CreatedAt time.Time    `db:"created_at" json:"created_at" goqu:"omitempty"`
+	DeletedAt sql.NullTime `db:"deleted_at" json:"-" goqu:"omitempty"`
+}
+
+type Medication struct {
+	ID        string       `db:"id" json:"id" goqu:"omitempty"`
+	ProfileID string       `db:"profile_id" json:"profile_id" goqu:"omitempty"`
+	Name      string       `db:"name" json:"name" goqu:"omitempty"`
+	Code      *string      `db:"code" json:"code"`
+	Dosage    *string      `db:"dosage" json:"dosage"`
+	Frequency *string      `db:"frequency" json:"frequency"`
+	StartedAt *time.Time   `db:"started_at" json:"started_at"`
+	EndedAt   *time.Time   `db:"ended_at" json:"ended_at"`
+	Note      *string      `db:"note" json:"note"`
+	CreatedAt time.Time    `db:"created_at" json:"created_at" goqu:"omitempty"`
+	DeletedAt sql.NullTime `db:"deleted_at" json:"-" goqu:"omitempty"`
+}
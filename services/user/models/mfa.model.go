@@ -0,0 +1,35 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type MFA struct {
+	ID           string       `db:"id" goqu:"omitempty"`
+	UserID       string       `db:"user_id" goqu:"omitempty"`
+	Secret       string       `db:"secret" goqu:"omitempty"`
+	LastUsedStep int64        `db:"last_used_step" goqu:"omitempty"`
+	EnabledAt    sql.NullTime `db:"enabled_at" goqu:"omitempty"`
+	CreatedAt    time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt    sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
+
+type RecoveryCode struct {
+	ID        string       `db:"id" goqu:"omitempty"`
+	UserID    string       `db:"user_id" goqu:"omitempty"`
+	CodeHash  string       `db:"code_hash" goqu:"omitempty"`
+	UsedAt    sql.NullTime `db:"used_at" goqu:"omitempty"`
+	CreatedAt time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
+
+type MFAChallenge struct {
+	ID             string       `db:"id" goqu:"omitempty"`
+	UserID         string       `db:"user_id" goqu:"omitempty"`
+	ChallengeToken string       `db:"challenge_token" goqu:"omitempty"`
+	Attempts       int          `db:"attempts" goqu:"omitempty"`
+	IsUsed         bool         `db:"is_used" goqu:"omitempty"`
+	CreatedAt      time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt      sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
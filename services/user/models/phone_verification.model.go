@@ -0,0 +1,18 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type PhoneVerification struct {
+	ID         string       `db:"id" goqu:"omitempty"`
+	ProfileID  string       `db:"profile_id" goqu:"omitempty"`
+	Phone      string       `db:"phone" goqu:"omitempty"`
+	CodeHash   string       `db:"code_hash" goqu:"omitempty"`
+	Attempts   int          `db:"attempts" goqu:"omitempty"`
+	ExpiresAt  time.Time    `db:"expires_at" goqu:"omitempty"`
+	VerifiedAt sql.NullTime `db:"verified_at" goqu:"omitempty"`
+	CreatedAt  time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt  sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
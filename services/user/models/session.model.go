@@ -0,0 +1,15 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Session struct {
+	ID             string       `db:"id" goqu:"omitempty"`
+	UserID         string       `db:"user_id" goqu:"omitempty"`
+	RefreshTokenID string       `db:"refresh_token_id" goqu:"omitempty"`
+	CreatedAt      time.Time    `db:"created_at" goqu:"omitempty"`
+	RevokedAt      sql.NullTime `db:"revoked_at" goqu:"omitempty"`
+	DeletedAt      sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
@@ -6,12 +6,15 @@ import (
 )
 
 type User struct {
-	ID        string       `db:"id" goqu:"omitempty"`
-	Provider  string       `db:"provider" goqu:"omitempty"`
-	Handle    string       `db:"handle" goqu:"omitempty"`
-	Password  string       `db:"password" goqu:"omitempty"`
-	CreatedAt time.Time    `db:"created_at" goqu:"omitempty"`
-	DeletedAt sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+	ID            string       `db:"id" goqu:"omitempty"`
+	Provider      string       `db:"provider" goqu:"omitempty"`
+	Handle        string       `db:"handle" goqu:"omitempty"`
+	Role          string       `db:"role" goqu:"omitempty"`
+	Password      string       `db:"password" goqu:"omitempty"`
+	EmailVerified bool         `db:"email_verified" goqu:"omitempty"`
+	DisabledAt    sql.NullTime `db:"disabled_at" goqu:"omitempty"`
+	CreatedAt     time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt     sql.NullTime `db:"deleted_at" goqu:"omitempty"`
 }
 
 type Profile struct {
@@ -21,20 +24,20 @@ type Profile struct {
 	Name          string       `db:"name" json:"name" goqu:"omitempty"`
 	CountryCode   string       `db:"country_code" json:"country_code" goqu:"omitempty"`
 	Phone         string       `db:"phone" json:"phone" goqu:"omitempty"`
+	VerifiedPhone *string      `db:"verified_phone" json:"-" goqu:"omitempty"`
 	NIK           *string      `db:"nik" json:"nik" goqu:"omitempty"`
-	Age           *string      `db:"age" json:"age" goqu:"omitempty"`
+	Age           *string      `db:"age" json:"-" goqu:"omitempty"`
 	DOB           *time.Time   `db:"dob" json:"dob" goqu:"omitempty"`
 	Sex           *string      `db:"sex" json:"sex" goqu:"omitempty"`
+	RegionCode    *string      `db:"region_code" json:"region_code" goqu:"omitempty"`
 	BloodType     *string      `db:"blood_type" json:"blood_type" goqu:"omitempty"`
 	Weight        *float64     `db:"weight" json:"weight" goqu:"omitempty"`
 	Height        *float64     `db:"height" json:"height" goqu:"omitempty"`
 	ActivityLevel *string      `db:"activity_level" json:"activity_level" goqu:"omitempty"`
-	Allergies     *string      `db:"allergies" json:"allergies"`
-	ECRelation    *string      `db:"ec_relation" json:"ec_relation" goqu:"omitempty"`
-	ECName        *string      `db:"ec_name" json:"ec_name" goqu:"omitempty"`
-	ECCountryCode *string      `db:"ec_country_code" json:"ec_country_code" goqu:"omitempty"`
-	ECPhone       *string      `db:"ec_phone" json:"ec_phone" goqu:"omitempty"`
+	Locale        *string      `db:"locale" json:"locale" goqu:"omitempty"`
+	Units         *string      `db:"units" json:"units" goqu:"omitempty"`
 	PhotoUrl      *string      `db:"photo_url" json:"photo_url" goqu:"omitempty"`
+	PhotoKey      *string      `db:"photo_key" json:"-" goqu:"omitempty"`
 	CreatedAt     time.Time    `db:"created_at" json:"created_at" goqu:"omitempty"`
 	DeletedAt     sql.NullTime `db:"deleted_at" json:"deleted_at" goqu:"omitempty"`
 }
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// UserIdentity is a sign-in provider linked to an account. Subject is the id
+// the provider knows the user by, FirebaseUID the firebase user it signs in as.
+type UserIdentity struct {
+	ID            string       `db:"id" goqu:"omitempty"`
+	UserID        string       `db:"user_id" goqu:"omitempty"`
+	Provider      string       `db:"provider" goqu:"omitempty"`
+	Subject       string       `db:"subject" goqu:"omitempty"`
+	FirebaseUID   string       `db:"firebase_uid" goqu:"omitempty"`
+	Email         string       `db:"email" goqu:"omitempty"`
+	EmailVerified bool         `db:"email_verified" goqu:"omitempty"`
+	CreatedAt     time.Time    `db:"created_at" goqu:"omitempty"`
+	DeletedAt     sql.NullTime `db:"deleted_at" goqu:"omitempty"`
+}
@@ -0,0 +1,553 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"monorepo/internal/config"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/internal/repository"
+	"monorepo/pkg/common"
+	"monorepo/pkg/phone"
+	"monorepo/pkg/utils"
+	clinicModels "monorepo/services/clinic/models"
+	"monorepo/services/user/models"
+	"strings"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	adminPageLimit    = 20
+	adminPageLimitMax = 100
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// AdminService lets operators look up and manage user accounts. Every read
+// and change is written to the admin audit log.
+type AdminService struct {
+	userService  *UserService
+	emailService *EmailService
+	fbaClient    *auth.Client
+	env          *config.Environment
+	tables       struct {
+		user        common.Repository[models.User, string]
+		profile     common.Repository[models.Profile, string]
+		audit       common.Repository[models.AdminAuditLog, string]
+		clinic      common.Repository[clinicModels.Clinic, string]
+		clinicStaff common.Repository[models.ClinicStaff, string]
+	}
+}
+
+func NewAdminService(
+	userService *UserService,
+	emailService *EmailService,
+	tbUser common.Repository[models.User, string],
+	tbProfile common.Repository[models.Profile, string],
+	tbAudit common.Repository[models.AdminAuditLog, string],
+	tbClinic common.Repository[clinicModels.Clinic, string],
+	tbClinicStaff common.Repository[models.ClinicStaff, string],
+	fbaClient *auth.Client,
+	env *config.Environment,
+) *AdminService {
+	service := &AdminService{userService: userService, emailService: emailService, fbaClient: fbaClient, env: env}
+	service.tables.user = tbUser
+	service.tables.profile = tbProfile
+	service.tables.audit = tbAudit
+	service.tables.clinic = tbClinic
+	service.tables.clinicStaff = tbClinicStaff
+
+	return service
+}
+
+// SearchUsers matches the query against the email, and the name, phone and
+// medical id of the user's own profile.
+func (service *AdminService) SearchUsers(ctx context.Context, actorID string, filter dto.FilterSearchUsers) ([]dto.ResponseAdminUser, error) {
+	query := strings.TrimSpace(filter.Query)
+
+	var where []exp.Expression
+	if query != "" {
+		pattern := "%" + likeEscaper.Replace(query) + "%"
+		match := []exp.Expression{
+			goqu.C("name").ILike(pattern),
+			goqu.C("medical_id").Eq(query),
+		}
+		if number, err := phone.Normalize("", query); err == nil {
+			match = append(match, goqu.C("phone").Eq(number))
+		}
+
+		profiles := goqu.From(repository.Tables.Profile).
+			Select("user_id").
+			Where(goqu.C("deleted_at").IsNull(), goqu.Or(match...))
+
+		where = append(where, goqu.Or(
+			goqu.C("handle").ILike(pattern),
+			goqu.C("id").Eq(query),
+			goqu.C("id").In(profiles),
+		))
+	}
+
+	users, err := service.tables.user.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Filter: where,
+		Page:   max(filter.Page, 1),
+		Limit:  pageLimit(filter.Limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	err = service.audit(ctx, actorID, constants.AuditSearchUsers, "", map[string]any{"query": query, "page": max(filter.Page, 1)})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.ResponseAdminUser, 0, len(users))
+	if len(users) == 0 {
+		return res, nil
+	}
+
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	profiles, err := service.tables.profile.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("user_id").In(ids)},
+		Page:   1,
+		Limit:  len(ids),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	byUser := make(map[string]*models.Profile, len(profiles))
+	for _, profile := range profiles {
+		byUser[profile.UserID] = profile
+	}
+
+	for _, user := range users {
+		res = append(res, adminUserResponse(user, byUser[user.ID]))
+	}
+
+	return res, nil
+}
+
+// GetUser returns the account with its own profile and the sign-in providers
+// linked in firebase.
+func (service *AdminService) GetUser(ctx context.Context, actorID, userID string) (*dto.ResponseAdminUserDetail, error) {
+	user, err := service.userService.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := service.userService.ownProfile(ctx, user.ID)
+	if err != nil && !errors.Is(err, ErrProfileNotFound) {
+		return nil, err
+	}
+
+	res := &dto.ResponseAdminUserDetail{
+		ResponseAdminUser: adminUserResponse(user, profile),
+		Providers:         []dto.ResponseAuthProvider{},
+	}
+
+	u, err := service.fbaClient.GetUserByEmail(ctx, user.Handle)
+	if err != nil && !auth.IsUserNotFound(err) {
+		return nil, err
+	}
+
+	if u != nil {
+		for _, provider := range u.ProviderUserInfo {
+			res.Providers = append(res.Providers, dto.ResponseAuthProvider{
+				ProviderID: provider.ProviderID,
+				UID:        provider.UID,
+				Email:      provider.Email,
+			})
+		}
+
+		if u.UserMetadata != nil && u.UserMetadata.LastLogInTimestamp > 0 {
+			lastLogin := time.UnixMilli(u.UserMetadata.LastLogInTimestamp)
+			res.LastLoginAt = &lastLogin
+		}
+	}
+
+	err = service.audit(ctx, actorID, constants.AuditViewUser, user.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ViewAsSupport returns the profile and dependents the user sees themselves.
+// It is read only and issues no token for the user, medical records are left out.
+func (service *AdminService) ViewAsSupport(ctx context.Context, actorID, userID string) (*dto.ResponseSupportView, error) {
+	user, err := service.userService.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.ResponseSupportView{}
+	res.Profile, err = service.userService.GetProfile(ctx, &dto.FirebaseClaims{
+		UserID: user.ID,
+		Role:   utils.Ternary(user.Role != "", user.Role, constants.RoleUser),
+	}, "")
+	if err != nil && !errors.Is(err, ErrProfileNotFound) {
+		return nil, err
+	}
+
+	res.Dependents, err = service.userService.ListDependents(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.audit(ctx, actorID, constants.AuditViewAsSupport, user.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SetStatus disables or re-enables an account locally and in firebase. A
+// disabled account loses all of its sessions and cannot log in.
+func (service *AdminService) SetStatus(ctx context.Context, actorID, userID string, body *dto.RequestAccountStatus) error {
+	if actorID == userID {
+		return ErrAdminSelf
+	}
+
+	user, err := service.userService.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	disabled := body.Status == constants.AccountDisabled
+
+	// the entry is written first, an action that could not be logged is not taken
+	err = service.audit(ctx, actorID, constants.AuditAccountStatus, user.ID, map[string]any{
+		"status": body.Status,
+		"reason": body.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	u, err := service.fbaClient.GetUserByEmail(ctx, user.Handle)
+	if err != nil && !auth.IsUserNotFound(err) {
+		return err
+	}
+
+	if u != nil {
+		_, err = service.fbaClient.UpdateUser(ctx, u.UID, (&auth.UserToUpdate{}).Disabled(disabled))
+		if err != nil {
+			return err
+		}
+
+		if disabled {
+			err = service.fbaClient.RevokeRefreshTokens(ctx, u.UID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if disabled {
+		err = service.tables.user.Update(ctx, user.ID, &models.User{
+			DisabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+
+		err = service.userService.RevokeSessions(ctx, user.ID, "")
+		if err != nil {
+			return err
+		}
+	} else {
+		// zero values are left out of updates, so clearing the column needs its own statement
+		stmt, args, err := goqu.Dialect("postgres").Update(repository.Tables.User).
+			Set(goqu.Record{"disabled_at": nil}).
+			Where(goqu.C("id").Eq(user.ID)).
+			ToSQL()
+		if err != nil {
+			return err
+		}
+
+		_, err = service.tables.user.Raw(ctx, stmt, args...)
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+	}
+
+	return nil
+}
+
+// AssignRole changes the role of an account. Tokens carry the role as a claim,
+// so the user's sessions are revoked and the new role applies from the next login.
+func (service *AdminService) AssignRole(ctx context.Context, actorID, userID string, body *dto.RequestAssignRole) error {
+	if actorID == userID {
+		return ErrAdminSelf
+	}
+
+	user, err := service.userService.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = service.audit(ctx, actorID, constants.AuditAssignRole, user.ID, map[string]any{
+		"from":   user.Role,
+		"to":     body.Role,
+		"reason": body.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = service.tables.user.Update(ctx, user.ID, &models.User{Role: body.Role})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return service.userService.RevokeSessions(ctx, user.ID, "")
+}
+
+// ForcePasswordReset replaces the password with a random one, so the current
+// password stops working, and emails the user a reset link.
+func (service *AdminService) ForcePasswordReset(ctx context.Context, actorID, userID string, body *dto.RequestAdminPasswordReset) error {
+	user, err := service.userService.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Provider != "email" {
+		return ErrPasswordNotManaged
+	}
+
+	password, err := utils.RandSecureAlphanumericString(32)
+	if err != nil {
+		return err
+	}
+
+	err = service.audit(ctx, actorID, constants.AuditPasswordReset, user.ID, map[string]any{
+		"reason": body.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = service.userService.setPassword(ctx, user, password)
+	if err != nil {
+		return err
+	}
+
+	err = service.userService.RevokeSessions(ctx, user.ID, "")
+	if err != nil {
+		return err
+	}
+
+	return service.emailService.ResetPassword(ctx, service.env, &dto.RequestForgotPassword{Email: user.Handle})
+}
+
+// AssignClinic lets a staff account read the records of patients with an
+// appointment at the clinic.
+func (service *AdminService) AssignClinic(ctx context.Context, actorID, userID, clinicID string, body *dto.RequestClinicStaff) error {
+	user, err := service.userService.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role != constants.RoleStaff && user.Role != constants.RoleAdmin {
+		return ErrNotStaff
+	}
+
+	_, err = service.tables.clinic.Get(ctx, clinicID)
+	if errors.Is(err, ErrNoResult) {
+		return ErrClinicNotFound
+	} else if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	existing, err := service.clinicStaff(ctx, user.ID, clinicID)
+	if err != nil {
+		return err
+	} else if existing != nil {
+		return nil
+	}
+
+	err = service.audit(ctx, actorID, constants.AuditAssignClinic, user.ID, map[string]any{
+		"clinic_id": clinicID,
+		"reason":    body.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = service.tables.clinicStaff.Create(ctx, &models.ClinicStaff{
+		ID:        ulid.Make().String(),
+		UserID:    user.ID,
+		ClinicID:  clinicID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// RemoveClinic ends the assignment of a staff account to the clinic.
+func (service *AdminService) RemoveClinic(ctx context.Context, actorID, userID, clinicID string, body *dto.RequestClinicStaff) error {
+	existing, err := service.clinicStaff(ctx, userID, clinicID)
+	if err != nil {
+		return err
+	} else if existing == nil {
+		return ErrClinicStaffNotFound
+	}
+
+	err = service.audit(ctx, actorID, constants.AuditRemoveClinic, userID, map[string]any{
+		"clinic_id": clinicID,
+		"reason":    body.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = service.tables.clinicStaff.Delete(ctx, existing.ID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+func (service *AdminService) clinicStaff(ctx context.Context, userID, clinicID string) (*models.ClinicStaff, error) {
+	staff, err := service.tables.clinicStaff.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("user_id").Eq(userID),
+			goqu.C("clinic_id").Eq(clinicID),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(staff) == 0 {
+		return nil, nil
+	}
+
+	return staff[0], nil
+}
+
+// ListAuditLogs returns the audit log, newest first.
+func (service *AdminService) ListAuditLogs(ctx context.Context, filter dto.FilterAuditLogs) ([]dto.ResponseAuditLog, error) {
+	var where []exp.Expression
+	if filter.ActorID != "" {
+		where = append(where, goqu.C("actor_id").Eq(filter.ActorID))
+	}
+	if filter.TargetUserID != "" {
+		where = append(where, goqu.C("target_user_id").Eq(filter.TargetUserID))
+	}
+
+	logs, err := service.tables.audit.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Filter: where,
+		Page:   max(filter.Page, 1),
+		Limit:  pageLimit(filter.Limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	res := make([]dto.ResponseAuditLog, 0, len(logs))
+	for _, log := range logs {
+		item := dto.ResponseAuditLog{
+			ID:           log.ID,
+			ActorID:      log.ActorID,
+			Action:       log.Action,
+			TargetUserID: log.TargetUserID,
+			CreatedAt:    log.CreatedAt,
+		}
+		if log.Detail != "" {
+			item.Detail = json.RawMessage(log.Detail)
+		}
+
+		res = append(res, item)
+	}
+
+	return res, nil
+}
+
+func (service *AdminService) audit(ctx context.Context, actorID, action, targetUserID string, detail map[string]any) error {
+	return writeAudit(ctx, service.tables.audit, actorID, action, targetUserID, detail)
+}
+
+// writeAudit adds an entry to the admin audit log. Callers write it before
+// the action, an action that could not be logged is not taken.
+func writeAudit(ctx context.Context, tb common.Repository[models.AdminAuditLog, string], actorID, action, targetUserID string, detail map[string]any) error {
+	entry := &models.AdminAuditLog{
+		ID:           ulid.Make().String(),
+		ActorID:      actorID,
+		Action:       action,
+		TargetUserID: targetUserID,
+		CreatedAt:    time.Now(),
+	}
+
+	if len(detail) > 0 {
+		b, err := json.Marshal(detail)
+		if err != nil {
+			return err
+		}
+		entry.Detail = string(b)
+	}
+
+	err := tb.Create(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return adminPageLimit
+	}
+
+	return min(limit, adminPageLimitMax)
+}
+
+func adminUserResponse(user *models.User, profile *models.Profile) dto.ResponseAdminUser {
+	res := dto.ResponseAdminUser{
+		ID:            user.ID,
+		Email:         user.Handle,
+		Provider:      user.Provider,
+		Role:          utils.Ternary(user.Role != "", user.Role, constants.RoleUser),
+		Status:        constants.AccountActive,
+		EmailVerified: user.EmailVerified,
+		CreatedAt:     user.CreatedAt,
+	}
+
+	if user.DisabledAt.Valid {
+		res.Status = constants.AccountDisabled
+		res.DisabledAt = &user.DisabledAt.Time
+	}
+
+	if profile != nil {
+		res.ProfileID = profile.ID
+		res.MedicalID = profile.MedicalID
+		res.Name = profile.Name
+		res.Phone = profile.Phone
+	}
+
+	return res
+}
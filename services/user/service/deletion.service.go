@@ -0,0 +1,515 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"monorepo/internal/config"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/internal/repository"
+	"monorepo/internal/storage"
+	"monorepo/pkg/common"
+	"monorepo/pkg/utils"
+	"monorepo/services/user/models"
+	"net/url"
+	"path"
+	"slices"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	deletionDefaultGraceDays = 30
+	deletionBatchSize        = 10
+)
+
+// erasureStep removes or anonymizes one kind of personal data and returns the number of affected rows.
+type erasureStep struct {
+	name string
+	run  func(ctx context.Context, user *models.User) (int64, error)
+}
+
+func NewDeletionService(
+	tbUser common.Repository[models.User, string],
+	tbProfile common.Repository[models.Profile, string],
+	tbIdentity common.Repository[models.UserIdentity, string],
+	tbDeletion common.Repository[models.AccountDeletion, string],
+	tbDeletionLog common.Repository[models.AccountDeletionLog, string],
+	tbDeletionCert common.Repository[models.DeletionCertificate, string],
+	tbExport common.Repository[models.DataExport, string],
+	fbaClient *auth.Client,
+	store storage.Storage,
+	env *config.Environment,
+) *DeletionService {
+	service := &DeletionService{}
+	service.fbaClient = fbaClient
+	service.storage = store
+	service.gracePeriod = time.Hour * 24 * time.Duration(utils.Ternary(env.DeletionGraceDays > 0, env.DeletionGraceDays, deletionDefaultGraceDays))
+	service.tables.user = tbUser
+	service.tables.profile = tbProfile
+	service.tables.identity = tbIdentity
+	service.tables.deletion = tbDeletion
+	service.tables.deletionLog = tbDeletionLog
+	service.tables.deletionCert = tbDeletionCert
+	service.tables.export = tbExport
+
+	return service
+}
+
+type DeletionService struct {
+	fbaClient   *auth.Client
+	storage     storage.Storage
+	gracePeriod time.Duration
+	tables      struct {
+		user         common.Repository[models.User, string]
+		profile      common.Repository[models.Profile, string]
+		identity     common.Repository[models.UserIdentity, string]
+		deletion     common.Repository[models.AccountDeletion, string]
+		deletionLog  common.Repository[models.AccountDeletionLog, string]
+		deletionCert common.Repository[models.DeletionCertificate, string]
+		export       common.Repository[models.DataExport, string]
+	}
+}
+
+func (service *DeletionService) getPending(ctx context.Context, userID string) (*models.AccountDeletion, error) {
+	existing, err := service.tables.deletion.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("user_id").Eq(userID),
+			goqu.C("status").Eq(constants.DeletionPending),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(existing) == 0 {
+		return nil, ErrDeletionNotFound
+	}
+
+	return existing[0], nil
+}
+
+// RequestDeletion schedules the erasure of the account after the grace period.
+func (service *DeletionService) RequestDeletion(ctx context.Context, userID string, body *dto.RequestAccountDeletion) (*dto.ResponseAccountDeletion, error) {
+	_, err := service.getPending(ctx, userID)
+	if err == nil {
+		return nil, ErrDeletionPending
+	} else if err != ErrDeletionNotFound {
+		return nil, err
+	}
+
+	deletion := models.AccountDeletion{
+		ID:          ulid.Make().String(),
+		UserID:      userID,
+		Status:      constants.DeletionPending,
+		Reason:      body.Reason,
+		ScheduledAt: time.Now().Add(service.gracePeriod),
+		CreatedAt:   time.Now(),
+	}
+	err = service.tables.deletion.Create(ctx, &deletion)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return toResponseAccountDeletion(&deletion), nil
+}
+
+func (service *DeletionService) GetDeletion(ctx context.Context, userID string) (*dto.ResponseAccountDeletion, error) {
+	deletion, err := service.getPending(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toResponseAccountDeletion(deletion), nil
+}
+
+// CancelDeletion aborts a pending deletion while it is still in the grace period.
+func (service *DeletionService) CancelDeletion(ctx context.Context, userID string) error {
+	deletion, err := service.getPending(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = service.tables.deletion.Update(ctx, deletion.ID, &models.AccountDeletion{
+		Status:     constants.DeletionCanceled,
+		CanceledAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// Run processes due deletions every interval until the context is done.
+func (service *DeletionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := service.ProcessDue(ctx)
+			if err != nil {
+				logrus.Errorf("failed to process account deletions; err: %s", err)
+			}
+		}
+	}
+}
+
+// ProcessDue erases every account whose grace period is over. A failed erasure
+// stays pending and is retried on the next run, all steps are idempotent.
+func (service *DeletionService) ProcessDue(ctx context.Context) error {
+	due, err := service.tables.deletion.List(ctx, &common.FilterOptions{
+		Sort: []exp.OrderedExpression{goqu.I("scheduled_at").Asc()},
+		Filter: []exp.Expression{
+			goqu.C("status").Eq(constants.DeletionPending),
+			goqu.C("scheduled_at").Lte(time.Now()),
+		},
+		Page:  1,
+		Limit: deletionBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	for _, deletion := range due {
+		err = service.erase(ctx, deletion)
+		if err != nil {
+			logrus.Errorf("failed to erase account: %s; deletion: %s; err: %s", deletion.UserID, deletion.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (service *DeletionService) erase(ctx context.Context, deletion *models.AccountDeletion) error {
+	user, err := service.tables.user.Get(ctx, deletion.UserID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	type stepSummary struct {
+		Step         string `json:"step"`
+		AffectedRows int64  `json:"affected_rows"`
+	}
+
+	summary := []stepSummary{}
+	for _, step := range service.steps() {
+		affected, err := step.run(ctx, user)
+
+		log := models.AccountDeletionLog{
+			ID:           ulid.Make().String(),
+			DeletionID:   deletion.ID,
+			Step:         step.name,
+			AffectedRows: affected,
+			CreatedAt:    time.Now(),
+		}
+		if err != nil {
+			log.Error = err.Error()
+		}
+
+		if lerr := service.tables.deletionLog.Create(ctx, &log); lerr != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, lerr)
+		}
+
+		if err != nil {
+			return fmt.Errorf("erasure step %s failed; %w", step.name, err)
+		}
+
+		summary = append(summary, stepSummary{Step: step.name, AffectedRows: affected})
+	}
+
+	// the account itself goes in the statement that completes the deletion and
+	// issues its certificate, a deletion is never left pending without an
+	// account to erase on the next run
+	summary = append(summary, stepSummary{Step: repository.Tables.User, AffectedRows: 1})
+	b, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	completedAt := time.Now()
+	digest := sha256.Sum256([]byte(deletion.ID + "|" + deletion.UserID + "|" + completedAt.UTC().Format(time.RFC3339Nano) + "|" + string(b)))
+	certificate := &models.DeletionCertificate{
+		ID:         ulid.Make().String(),
+		DeletionID: deletion.ID,
+		UserID:     deletion.UserID,
+		Summary:    string(b),
+		Digest:     hex.EncodeToString(digest[:]),
+		IssuedAt:   completedAt,
+	}
+
+	dialect := goqu.Dialect("postgres")
+	affected, err := service.exec(ctx, dialect.Delete(repository.Tables.User).
+		With("completed", dialect.Update(repository.Tables.Deletion).
+			Set(goqu.Record{"status": constants.DeletionCompleted, "completed_at": completedAt}).
+			Where(goqu.C("id").Eq(deletion.ID))).
+		With("certificate", dialect.Insert(repository.Tables.DeletionCert).Rows(certificate)).
+		Where(goqu.C("id").Eq(user.ID)))
+
+	log := models.AccountDeletionLog{
+		ID:           ulid.Make().String(),
+		DeletionID:   deletion.ID,
+		Step:         repository.Tables.User,
+		AffectedRows: affected,
+		CreatedAt:    time.Now(),
+	}
+	if err != nil {
+		log.Error = err.Error()
+	}
+
+	if lerr := service.tables.deletionLog.Create(ctx, &log); lerr != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, lerr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// steps lists every erasure in order. Rows of other services are reached
+// through the shared database since the worker runs without a user token.
+// Appointments are anonymized instead of deleted so clinic capacity history stays correct.
+func (service *DeletionService) steps() []erasureStep {
+	byUser := func(table string) erasureStep {
+		return erasureStep{name: table, run: func(ctx context.Context, user *models.User) (int64, error) {
+			return service.exec(ctx, goqu.Dialect("postgres").Delete(table).Where(goqu.C("user_id").Eq(user.ID)))
+		}}
+	}
+	profileIDs := func(user *models.User) *goqu.SelectDataset {
+		return erasedProfileIDs(user.ID)
+	}
+	byProfile := func(table string) erasureStep {
+		return erasureStep{name: table, run: func(ctx context.Context, user *models.User) (int64, error) {
+			return service.exec(ctx, goqu.Dialect("postgres").Delete(table).Where(goqu.C("profile_id").In(profileIDs(user))))
+		}}
+	}
+
+	return []erasureStep{
+		byProfile(repository.Tables.FoodDiary),
+		byProfile(repository.Tables.ExerciseLog),
+		byProfile(repository.Tables.CalorieAdjust),
+		byProfile(repository.Tables.BodyMeasure),
+		byProfile(repository.Tables.Food),
+		byProfile(repository.Tables.WeightHistory),
+		byProfile(repository.Tables.WeightGoal),
+		byProfile(repository.Tables.PhoneVerify),
+		byProfile(repository.Tables.Handover),
+		byProfile(repository.Tables.Allergy),
+		byProfile(repository.Tables.Condition),
+		byProfile(repository.Tables.Medication),
+		byProfile(repository.Tables.Contact),
+		{name: "event_cancel_upcoming", run: func(ctx context.Context, user *models.User) (int64, error) {
+			return service.exec(ctx, goqu.Dialect("postgres").Update(repository.Tables.Event).
+				Set(goqu.Record{"status": constants.Canceled}).
+				Where(
+					goqu.C("profile_id").In(profileIDs(user)),
+					goqu.C("status").Eq(constants.Scheduled),
+					goqu.C("start_time").Gt(time.Now()),
+				))
+		}},
+		{name: "event_anonymize", run: func(ctx context.Context, user *models.User) (int64, error) {
+			return service.exec(ctx, goqu.Dialect("postgres").Update(repository.Tables.Event).
+				Set(goqu.Record{"profile_id": nil}).
+				Where(goqu.C("profile_id").In(profileIDs(user))))
+		}},
+		byUser(repository.Tables.UserMessage),
+		{name: "profile_photo", run: service.deleteProfilePhotos},
+		{name: repository.Tables.Profile, run: func(ctx context.Context, user *models.User) (int64, error) {
+			return service.exec(ctx, goqu.Dialect("postgres").Delete(repository.Tables.Profile).Where(goqu.C("id").In(profileIDs(user))))
+		}},
+		{name: "data_export_archive", run: service.deleteExportArchives},
+		byUser(repository.Tables.DataExport),
+		byUser(repository.Tables.Guardian),
+		byUser(repository.Tables.Handover),
+		byUser(repository.Tables.ResetPassword),
+		{name: repository.Tables.EmailOutbox, run: func(ctx context.Context, user *models.User) (int64, error) {
+			return service.exec(ctx, goqu.Dialect("postgres").Delete(repository.Tables.EmailOutbox).Where(goqu.C("recipient").In(erasedEmails(user))))
+		}},
+		byUser(repository.Tables.EmailChange),
+		byUser(repository.Tables.EmailVerify),
+		byUser(repository.Tables.ClinicStaff),
+		byUser(repository.Tables.MFA),
+		byUser(repository.Tables.RecoveryCode),
+		byUser(repository.Tables.MFAChallenge),
+		byUser(repository.Tables.UserSession),
+		{name: "firebase_user", run: service.deleteFirebaseUser},
+		byUser(repository.Tables.Identity),
+	}
+}
+
+// erasedProfileIDs selects the account's own profile and the dependents it is
+// the last guardian of. Dependents that still have another guardian stay.
+func erasedProfileIDs(userID string) *goqu.SelectDataset {
+	guarded := goqu.From(repository.Tables.Guardian).Select("profile_id").Where(
+		goqu.C("user_id").Neq(userID),
+		goqu.C("deleted_at").IsNull(),
+	)
+	dependents := goqu.From(repository.Tables.Guardian).Select("profile_id").Where(
+		goqu.C("user_id").Eq(userID),
+		goqu.C("deleted_at").IsNull(),
+		goqu.C("profile_id").NotIn(guarded),
+	)
+
+	return goqu.From(repository.Tables.Profile).Select("id").Where(goqu.Or(
+		goqu.C("user_id").Eq(userID),
+		goqu.And(goqu.C("user_id").Eq(""), goqu.C("id").In(dependents)),
+	))
+}
+
+// erasedEmails selects every address mail of the account went to: the handle,
+// those of the linked sign-in methods and those it asked to change to. It runs
+// before the identities and email changes are erased.
+func erasedEmails(user *models.User) *goqu.SelectDataset {
+	identities := goqu.From(repository.Tables.Identity).Select("email").Where(
+		goqu.C("user_id").Eq(user.ID),
+		goqu.C("email").IsNotNull(),
+	)
+	changes := goqu.From(repository.Tables.EmailChange).Select("new_email").Where(goqu.C("user_id").Eq(user.ID))
+
+	return goqu.Select(goqu.L("?", user.Handle).As("email")).Union(identities).Union(changes)
+}
+
+func (service *DeletionService) exec(ctx context.Context, ds interface{ ToSQL() (string, []any, error) }) (int64, error) {
+	stmt, args, err := ds.ToSQL()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := service.tables.deletionLog.Raw(ctx, stmt, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (service *DeletionService) deleteProfilePhotos(ctx context.Context, user *models.User) (int64, error) {
+	profiles, err := service.tables.profile.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("id").In(erasedProfileIDs(user.ID))},
+		Page:   1,
+		Limit:  100,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, profile := range profiles {
+		var keys []string
+		if profile.PhotoKey != nil && *profile.PhotoKey != "" {
+			for _, variant := range photoVariants {
+				keys = append(keys, photoVariantKey(*profile.PhotoKey, variant.name))
+			}
+		}
+
+		// photos uploaded before content-addressed keys are only known by their url
+		if profile.PhotoUrl != nil && *profile.PhotoUrl != "" {
+			u, err := url.Parse(*profile.PhotoUrl)
+			if err != nil {
+				return deleted, err
+			}
+
+			name, err := url.QueryUnescape(path.Base(u.Path))
+			if err != nil {
+				return deleted, err
+			}
+			keys = append(keys, name)
+		}
+
+		for _, key := range keys {
+			err = service.storage.Delete(ctx, key)
+			if err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// deleteExportArchives removes the personal data archives of the account,
+// including those past their download window that were not purged yet.
+func (service *DeletionService) deleteExportArchives(ctx context.Context, user *models.User) (int64, error) {
+	exports, err := listAll(ctx, service.tables.export, goqu.C("user_id").Eq(user.ID), goqu.C("file_name").Neq(""))
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, export := range exports {
+		err = service.storage.Delete(ctx, export.FileName)
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// deleteFirebaseUser removes the firebase user of the account email and those
+// of the linked sign-in methods, which may have signed in with another email.
+func (service *DeletionService) deleteFirebaseUser(ctx context.Context, user *models.User) (int64, error) {
+	uids := []string{}
+	fbUser, err := service.fbaClient.GetUserByEmail(ctx, user.Handle)
+	if err == nil {
+		uids = append(uids, fbUser.UID)
+	} else if !auth.IsUserNotFound(err) {
+		return 0, err
+	}
+
+	identities, err := service.tables.identity.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("user_id").Eq(user.ID)},
+		Page:   1,
+		Limit:  identityLimit,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	for _, identity := range identities {
+		if identity.FirebaseUID != "" && !slices.Contains(uids, identity.FirebaseUID) {
+			uids = append(uids, identity.FirebaseUID)
+		}
+	}
+
+	var deleted int64
+	for _, uid := range uids {
+		err = service.fbaClient.DeleteUser(ctx, uid)
+		if auth.IsUserNotFound(err) {
+			continue
+		} else if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+func toResponseAccountDeletion(deletion *models.AccountDeletion) *dto.ResponseAccountDeletion {
+	return &dto.ResponseAccountDeletion{
+		ID:          deletion.ID,
+		Status:      deletion.Status,
+		ScheduledAt: deletion.ScheduledAt,
+		CreatedAt:   deletion.CreatedAt,
+	}
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/internal/repository"
+	"monorepo/pkg/common"
+	"monorepo/pkg/phone"
+	"monorepo/pkg/utils"
+	"monorepo/services/user/models"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+)
+
+const profileHandoverTTL = 7 * 24 * time.Hour
+
+// ownProfile returns the profile held by the account itself.
+func (service *UserService) ownProfile(ctx context.Context, userID string) (*models.Profile, error) {
+	profile, err := service.tables.profile.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("id").Desc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(userID)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(profile) == 0 {
+		return nil, ErrProfileNotFound
+	}
+
+	return profile[0], nil
+}
+
+// actingProfile resolves the profile a request acts as. Without profileID it
+// is the account's own profile, otherwise it has to be that same profile or a
+// dependent the account is a guardian of.
+func (service *UserService) actingProfile(ctx context.Context, userID, profileID string) (*models.Profile, *models.ProfileGuardian, error) {
+	if profileID == "" {
+		profile, err := service.ownProfile(ctx, userID)
+		return profile, nil, err
+	}
+
+	profile, err := service.tables.profile.Get(ctx, profileID)
+	if errors.Is(err, ErrNoResult) {
+		return nil, nil, ErrProfileNotFound
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if profile.UserID == userID {
+		return profile, nil, nil
+	}
+
+	guardian, err := service.guardianOf(ctx, profileID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return profile, guardian, nil
+}
+
+func (service *UserService) guardianOf(ctx context.Context, profileID, userID string) (*models.ProfileGuardian, error) {
+	guardians, err := service.tables.guardian.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profileID),
+			goqu.C("user_id").Eq(userID),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(guardians) == 0 {
+		return nil, ErrNotGuardian
+	}
+
+	return guardians[0], nil
+}
+
+func (service *UserService) ListDependents(ctx context.Context, userID string) ([]dto.ResponseDependent, error) {
+	guardians, err := service.tables.guardian.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("id").Asc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(userID)},
+		Page:   1,
+		Limit:  100,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	res := []dto.ResponseDependent{}
+	now := time.Now()
+	for _, guardian := range guardians {
+		profile, err := service.tables.profile.Get(ctx, guardian.ProfileID)
+		if errors.Is(err, ErrNoResult) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+		}
+
+		res = append(res, dependentResponse(profile, guardian, now))
+	}
+
+	return res, nil
+}
+
+// CreateDependent adds a profile managed by the account, e.g. a child or an
+// elderly parent. The guardian's number is used when the dependent has none.
+func (service *UserService) CreateDependent(ctx context.Context, userID string, body *dto.RequestCreateDependent) (*dto.ResponseDependent, error) {
+	own, err := service.ownProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	profile := &models.Profile{
+		ID:          ulid.Make().String(),
+		MedicalID:   ulid.Make().String(),
+		Name:        body.Name,
+		CountryCode: own.CountryCode,
+		Phone:       own.Phone,
+		CreatedAt:   now,
+	}
+
+	if body.Phone != "" {
+		number, err := phone.Parse(body.CountryCode, body.Phone)
+		if err != nil {
+			return nil, err
+		}
+
+		profile.CountryCode = number.CallingCode
+		profile.Phone = number.E164()
+	}
+
+	if !body.DOB.IsZero() {
+		profile.DOB = &body.DOB
+	}
+
+	if body.Sex != "" {
+		profile.Sex = &body.Sex
+	}
+
+	if body.NIK != "" {
+		err = applyNIK(profile, &models.Profile{}, &dto.RequestUpdateProfile{NIK: body.NIK, DOB: body.DOB, Sex: body.Sex})
+		if err != nil {
+			return nil, err
+		}
+
+		profile.NIK = &body.NIK
+	}
+
+	guardian := &models.ProfileGuardian{
+		ID:           ulid.Make().String(),
+		ProfileID:    profile.ID,
+		UserID:       userID,
+		Relationship: body.Relationship,
+		CreatedAt:    now,
+	}
+
+	err = service.tables.profile.Create(ctx, profile)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	err = service.tables.guardian.Create(ctx, guardian)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := dependentResponse(profile, guardian, now)
+	return &res, nil
+}
+
+// RemoveDependent unlinks the account from a dependent. A dependent left
+// without any guardian is deleted along with the link.
+func (service *UserService) RemoveDependent(ctx context.Context, userID, profileID string) error {
+	profile, guardian, err := service.actingProfile(ctx, userID, profileID)
+	if err != nil {
+		return err
+	}
+
+	if guardian == nil {
+		return ErrNotGuardian
+	}
+
+	err = service.tables.guardian.Delete(ctx, guardian.ID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	remaining, err := service.tables.guardian.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("profile_id").Eq(profile.ID)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(remaining) > 0 || profile.UserID != "" {
+		return nil
+	}
+
+	err = service.tables.profile.Delete(ctx, profile.ID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// StartHandover issues a one-time token the dependent redeems from their own
+// account once they are of age. The guardian passes it on in person.
+func (service *UserService) StartHandover(ctx context.Context, userID, profileID string) (*dto.ResponseProfileHandover, error) {
+	profile, guardian, err := service.actingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if guardian == nil {
+		return nil, ErrNotGuardian
+	}
+
+	now := time.Now()
+	if !dependentOfAge(profile, now) {
+		return nil, ErrDependentUnderage
+	}
+
+	token, err := utils.RandSecureAlphanumericString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	handover := &models.ProfileHandover{
+		ID:        ulid.Make().String(),
+		ProfileID: profile.ID,
+		UserID:    userID,
+		Token:     token,
+		ExpiresAt: now.Add(profileHandoverTTL),
+		CreatedAt: now,
+	}
+
+	err = service.tables.handover.Create(ctx, handover)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return &dto.ResponseProfileHandover{Token: handover.Token, ExpiresAt: handover.ExpiresAt}, nil
+}
+
+// ClaimProfile moves a dependent's profile to the claiming account and ends
+// every guardianship over it. The account must not hold a profile already.
+func (service *UserService) ClaimProfile(ctx context.Context, userID, token string) error {
+	_, err := service.ownProfile(ctx, userID)
+	if err == nil {
+		return ErrProfileExist
+	} else if !errors.Is(err, ErrProfileNotFound) {
+		return err
+	}
+
+	now := time.Now()
+	handovers, err := service.tables.handover.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("token").Eq(token),
+			goqu.C("claimed_at").IsNull(),
+			goqu.C("expires_at").Gt(now),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(handovers) == 0 {
+		return ErrHandoverInvalid
+	}
+	handover := handovers[0]
+
+	// only one claim can move the profile, the others see no row affected
+	stmt, args, err := goqu.Dialect("postgres").Update(repository.Tables.Profile).
+		Set(goqu.Record{"user_id": userID}).
+		Where(
+			goqu.C("id").Eq(handover.ProfileID),
+			goqu.C("user_id").Eq(""),
+			goqu.C("deleted_at").IsNull(),
+		).ToSQL()
+	if err != nil {
+		return err
+	}
+
+	result, err := service.tables.handover.Raw(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return ErrHandoverInvalid
+	}
+
+	err = service.tables.handover.Update(ctx, handover.ID, &models.ProfileHandover{
+		ClaimedBy: &userID,
+		ClaimedAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	guardians, err := service.tables.guardian.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("profile_id").Eq(handover.ProfileID)},
+		Page:   1,
+		Limit:  100,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	for _, guardian := range guardians {
+		err = service.tables.guardian.Delete(ctx, guardian.ID)
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+	}
+
+	return nil
+}
+
+func dependentOfAge(profile *models.Profile, now time.Time) bool {
+	return profile.DOB != nil && !profile.DOB.IsZero() && utils.Age(*profile.DOB, now) >= constants.DependentAdultAge
+}
+
+func dependentResponse(profile *models.Profile, guardian *models.ProfileGuardian, now time.Time) dto.ResponseDependent {
+	res := dto.ResponseDependent{
+		ID:           profile.ID,
+		MedicalID:    profile.MedicalID,
+		Name:         profile.Name,
+		Relationship: guardian.Relationship,
+		DOB:          profile.DOB,
+		Age:          profileAge(profile, now),
+		Handover:     dependentOfAge(profile, now),
+	}
+
+	if profile.Sex != nil {
+		res.Sex = *profile.Sex
+	}
+
+	return res
+}
+
+// ActingProfile is actingProfile for services that only need the profile.
+func (service *UserService) ActingProfile(ctx context.Context, userID, profileID string) (*models.Profile, error) {
+	profile, _, err := service.actingProfile(ctx, userID, profileID)
+	return profile, err
+}
@@ -1,52 +1,67 @@
 package service
 
 import (
-	"bytes"
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"monorepo/internal/config"
 	"monorepo/internal/dto"
+	"monorepo/internal/mail"
 	"monorepo/pkg/common"
 	"monorepo/pkg/utils"
 	"monorepo/services/user/models"
-	"text/template"
 	"time"
 
 	"firebase.google.com/go/v4/auth"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/oklog/ulid/v2"
-	"gopkg.in/gomail.v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	emailVerificationTTL      = time.Hour * 24
+	emailVerificationCooldown = time.Minute
+	emailVerificationHourly   = 5
 )
 
 func NewEmailService(
-	dialer *gomail.Dialer,
-	mailer *gomail.Message,
+	outboxService *OutboxService,
+	templates *mail.Registry,
 	fbaClient *auth.Client,
+	userService *UserService,
 	tbUser common.Repository[models.User, string],
 	tbProfile common.Repository[models.Profile, string],
 	tbResetPassword common.Repository[models.ResetPassword, string],
+	tbEmailChange common.Repository[models.EmailChange, string],
+	tbEmailVerification common.Repository[models.EmailVerification, string],
 ) *EmailService {
 	service := &EmailService{}
-	service.dialer = dialer
-	service.mailer = mailer
+	service.outboxService = outboxService
+	service.templates = templates
 	service.fbaClient = fbaClient
+	service.userService = userService
 	service.tables.user = tbUser
 	service.tables.profile = tbProfile
 	service.tables.resetPassword = tbResetPassword
+	service.tables.emailChange = tbEmailChange
+	service.tables.emailVerification = tbEmailVerification
 
 	return service
 }
 
 type EmailService struct {
-	dialer    *gomail.Dialer
-	mailer    *gomail.Message
-	fbaClient *auth.Client
-	tables    struct {
-		user          common.Repository[models.User, string]
-		profile       common.Repository[models.Profile, string]
-		resetPassword common.Repository[models.ResetPassword, string]
+	outboxService *OutboxService
+	templates     *mail.Registry
+	fbaClient     *auth.Client
+	userService   *UserService
+	tables        struct {
+		user              common.Repository[models.User, string]
+		profile           common.Repository[models.Profile, string]
+		resetPassword     common.Repository[models.ResetPassword, string]
+		emailChange       common.Repository[models.EmailChange, string]
+		emailVerification common.Repository[models.EmailVerification, string]
 	}
 }
 
@@ -94,10 +109,10 @@ func (service *EmailService) ResetPassword(ctx context.Context, env *config.Envi
 	}
 
 	sendEmail := dto.Email{
-		From:          env.SMTPAuthEmail,
-		To:            body.Email,
-		Subject:       "Reset Password",
-		TemplateEmail: fmt.Sprintf("%s/forgot-password.html", env.DirPath),
+		From:     env.SMTPAuthEmail,
+		To:       body.Email,
+		Template: mail.TemplateResetPassword,
+		Locale:   profileLocale(existing[0]),
 		Body: dto.EmailBody{
 			UserName:         existing[0].Name,
 			ResetPasswordUrl: fmt.Sprintf("%s?uid=%s&reset-token=%s", env.ResetPasswordUrl, user[0].ID, token),
@@ -105,29 +120,47 @@ func (service *EmailService) ResetPassword(ctx context.Context, env *config.Envi
 		},
 	}
 
-	template, err := service.ParseTemplate(sendEmail.TemplateEmail, sendEmail.Body)
+	return service.SendEmail(ctx, sendEmail)
+}
+
+// SendEmail renders the template in the recipient's locale and queues the message
+// in the outbox, delivery happens in the background with retries.
+func (service *EmailService) SendEmail(ctx context.Context, sendEmail dto.Email) error {
+	rendered, err := service.templates.Render(sendEmail.Template, sendEmail.Locale, sendEmail.Body)
 	if err != nil {
 		return err
 	}
 
-	service.mailer.SetHeader("From", sendEmail.From)
-	service.mailer.SetAddressHeader("Cc", sendEmail.To, sendEmail.Subject)
-	service.mailer.SetHeader("To", sendEmail.To)
-	service.mailer.SetHeader("Subject", sendEmail.Subject)
-	service.mailer.SetBody("text/html", template)
-	return service.dialer.DialAndSend(service.mailer)
+	return service.outboxService.Enqueue(ctx, &mail.Message{
+		From:    sendEmail.From,
+		To:      sendEmail.To,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
+	})
+}
+
+// PreviewTemplate renders a template with sample data.
+func (service *EmailService) PreviewTemplate(env *config.Environment, name, locale string) (*mail.Rendered, error) {
+	return service.templates.Render(name, locale, dto.EmailBody{
+		UserName:         "Budi Santoso",
+		ResetPasswordUrl: env.ResetPasswordUrl + "?uid=preview&reset-token=preview",
+		ChangeEmailUrl:   env.ChangeEmailUrl + "?uid=preview&change-token=preview",
+		VerifyEmailUrl:   env.VerifyEmailUrl + "?uid=preview&verify-token=preview",
+		NewEmail:         "budi.santoso@example.com",
+		CsMail:           env.CsMail,
+		ClinicName:       "Akasia365mc Jakarta",
+		AppointmentAt:    time.Now().Add(time.Hour * 24).Format("02 Jan 2006 15:04"),
+		GoalWeight:       "65",
+	})
 }
 
-func (service *EmailService) ParseTemplate(templateFileName string, data interface{}) (string, error) {
-	t, err := template.ParseFiles(templateFileName)
-	if err != nil {
-		return "", err
-	}
-	buf := new(bytes.Buffer)
-	if err = t.Execute(buf, data); err != nil {
-		return "", err
+func profileLocale(profile *models.Profile) string {
+	if profile == nil || profile.Locale == nil {
+		return ""
 	}
-	return buf.String(), nil
+
+	return *profile.Locale
 }
 
 func (service *EmailService) UpdatePassword(ctx context.Context, env *config.Environment, body *dto.RequestUpdatePassword) error {
@@ -186,23 +219,248 @@ func (service *EmailService) UpdatePassword(ctx context.Context, env *config.Env
 		return err
 	}
 
-	// get user by email
-	u, err := service.fbaClient.GetUserByEmail(ctx, user[0].Handle)
+	// update local and firebase password
+	err = service.userService.ResetPassword(ctx, user[0].ID, body.Password)
+	if err != nil {
+		return err
+	}
+
+	// update flag is_used
+	logReset[0].IsUsed = true
+	err = service.tables.resetPassword.Update(ctx, logReset[0].ID, logReset[0])
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ChangeEmail sends a verification link to the new address. The handle is only
+// changed once the link is confirmed through ConfirmEmailChange.
+func (service *EmailService) ChangeEmail(ctx context.Context, env *config.Environment, userID string, body *dto.RequestChangeEmail) error {
+	user, err := service.userService.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Provider != "email" {
+		return ErrPasswordNotManaged
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password))
+	if err != nil {
+		return ErrInvalidPassword
+	}
+
+	exists, err := service.userService.IsHandleExists(ctx, body.Email)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrHandleExist
+	}
+
+	existing, err := service.tables.profile.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("id").Desc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(user.ID)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return err
+	}
+
+	userName, locale := user.Handle, ""
+	if len(existing) > 0 {
+		userName, locale = existing[0].Name, profileLocale(existing[0])
+	}
+
+	token, err := utils.RandSecureAlphanumericString(16)
 	if err != nil {
 		return err
 	}
 
-	// update firebase
-	params := (&auth.UserToUpdate{}).
-		Password(body.Password)
-	_, err = service.fbaClient.UpdateUser(ctx, u.UID, params)
+	ec := &models.EmailChange{
+		ID:          ulid.Make().String(),
+		UserID:      user.ID,
+		NewEmail:    body.Email,
+		ChangeToken: token,
+		CreatedAt:   time.Now(),
+	}
+	err = service.tables.emailChange.Create(ctx, ec)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return service.SendEmail(ctx, dto.Email{
+		From:     env.SMTPAuthEmail,
+		To:       body.Email,
+		Template: mail.TemplateChangeEmail,
+		Locale:   locale,
+		Body: dto.EmailBody{
+			UserName:       userName,
+			NewEmail:       body.Email,
+			ChangeEmailUrl: fmt.Sprintf("%s?uid=%s&change-token=%s", env.ChangeEmailUrl, user.ID, token),
+			CsMail:         env.CsMail,
+		},
+	})
+}
+
+func (service *EmailService) ConfirmEmailChange(ctx context.Context, body *dto.RequestConfirmEmailChange) error {
+	logChange, err := service.tables.emailChange.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(body.UserID), goqu.C("is_used").Eq(false)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(logChange) == 0 {
+		err = errors.New("request not found")
+		return err
+	}
+
+	if time.Now().After(logChange[0].CreatedAt.Add(time.Hour * 1)) {
+		err = errors.New("token expired")
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(body.ChangeToken), []byte(logChange[0].ChangeToken)) != 1 {
+		err = errors.New("token unknown")
+		return err
+	}
+
+	err = service.userService.UpdateUser(ctx, body.UserID, &dto.RequestUpdateUser{Email: logChange[0].NewEmail})
 	if err != nil {
 		return err
 	}
 
 	// update flag is_used
-	logReset[0].IsUsed = true
-	err = service.tables.resetPassword.Update(ctx, logReset[0].ID, logReset[0])
+	logChange[0].IsUsed = true
+	err = service.tables.emailChange.Update(ctx, logChange[0].ID, logChange[0])
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SendEmailVerification sends a verification link to the user's handle. Sends are
+// throttled to one per emailVerificationCooldown and emailVerificationHourly per hour.
+func (service *EmailService) SendEmailVerification(ctx context.Context, env *config.Environment, user *models.User) error {
+	now := time.Now()
+	recent, err := service.tables.emailVerification.List(ctx, &common.FilterOptions{
+		Sort: []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Filter: []exp.Expression{
+			goqu.C("user_id").Eq(user.ID),
+			goqu.C("created_at").Gt(now.Add(-time.Hour)),
+		},
+		Page:  1,
+		Limit: emailVerificationHourly,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(recent) >= emailVerificationHourly ||
+		(len(recent) > 0 && now.Before(recent[0].CreatedAt.Add(emailVerificationCooldown))) {
+		return ErrTooManyRequests
+	}
+
+	existing, err := service.tables.profile.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("id").Desc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(user.ID)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return err
+	}
+
+	userName, locale := user.Handle, ""
+	if len(existing) > 0 {
+		userName, locale = existing[0].Name, profileLocale(existing[0])
+	}
+
+	token, err := utils.RandSecureAlphanumericString(16)
+	if err != nil {
+		return err
+	}
+
+	ev := &models.EmailVerification{
+		ID:          ulid.Make().String(),
+		UserID:      user.ID,
+		VerifyToken: token,
+		CreatedAt:   now,
+	}
+	err = service.tables.emailVerification.Create(ctx, ev)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return service.SendEmail(ctx, dto.Email{
+		From:     env.SMTPAuthEmail,
+		To:       user.Handle,
+		Template: mail.TemplateVerifyEmail,
+		Locale:   locale,
+		Body: dto.EmailBody{
+			UserName:       userName,
+			VerifyEmailUrl: fmt.Sprintf("%s?uid=%s&verify-token=%s", env.VerifyEmailUrl, user.ID, token),
+			CsMail:         env.CsMail,
+		},
+	})
+}
+
+func (service *EmailService) ResendEmailVerification(ctx context.Context, env *config.Environment, userID string) error {
+	user, err := service.userService.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	return service.SendEmailVerification(ctx, env, user)
+}
+
+func (service *EmailService) VerifyEmail(ctx context.Context, body *dto.RequestVerifyEmail) error {
+	logVerify, err := service.tables.emailVerification.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(body.UserID), goqu.C("is_used").Eq(false)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(logVerify) == 0 {
+		err = errors.New("request not found")
+		return err
+	}
+
+	if time.Now().After(logVerify[0].CreatedAt.Add(emailVerificationTTL)) {
+		err = errors.New("token expired")
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(body.VerifyToken), []byte(logVerify[0].VerifyToken)) != 1 {
+		err = errors.New("token unknown")
+		return err
+	}
+
+	err = service.userService.VerifyEmail(ctx, body.UserID)
+	if err != nil {
+		return err
+	}
+
+	// update flag is_used
+	logVerify[0].IsUsed = true
+	err = service.tables.emailVerification.Update(ctx, logVerify[0].ID, logVerify[0])
 	if err != nil {
 		return err
 	}
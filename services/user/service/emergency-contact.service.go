@@ -0,0 +1,428 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"monorepo/internal/config"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/internal/mail"
+	"monorepo/internal/repository"
+	"monorepo/internal/sms"
+	"monorepo/pkg/common"
+	"monorepo/pkg/phone"
+	"monorepo/pkg/utils"
+	calendarModels "monorepo/services/calendar/models"
+	"monorepo/services/user/models"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	emergencyContactsMax   = 5
+	contactConsentTTL      = 7 * 24 * time.Hour
+	contactConsentCooldown = 24 * time.Hour
+)
+
+var contactConsentMessages = map[string]string{
+	mail.LocaleID: "%s menambahkan Anda sebagai kontak darurat di Akasia365mc. Setujui atau tolak di %s",
+	mail.LocaleEN: "%s added you as an emergency contact on Akasia365mc. Accept or decline at %s",
+}
+
+func NewEmergencyContactService(
+	userService *UserService,
+	tbContact common.Repository[models.EmergencyContact, string],
+	tbEvent common.Repository[calendarModels.Event, string],
+	tbAudit common.Repository[models.AdminAuditLog, string],
+	sender sms.Sender,
+	env *config.Environment,
+) *EmergencyContactService {
+	service := &EmergencyContactService{}
+	service.userService = userService
+	service.sender = sender
+	service.consentURL = env.ContactConsentUrl
+	service.tables.contact = tbContact
+	service.tables.event = tbEvent
+	service.tables.audit = tbAudit
+
+	return service
+}
+
+// EmergencyContactService keeps the emergency contacts of a profile in
+// priority order, 1 being called first. A contact may be asked by sms to
+// consent to being listed.
+type EmergencyContactService struct {
+	userService *UserService
+	sender      sms.Sender
+	consentURL  string
+	tables      struct {
+		contact common.Repository[models.EmergencyContact, string]
+		event   common.Repository[calendarModels.Event, string]
+		audit   common.Repository[models.AdminAuditLog, string]
+	}
+}
+
+// listContacts returns the contacts of a profile by priority.
+func listContacts(ctx context.Context, tbContact common.Repository[models.EmergencyContact, string], profileID string) ([]*models.EmergencyContact, error) {
+	contacts, err := listAll(ctx, tbContact, goqu.C("profile_id").Eq(profileID))
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(contacts, func(a, b *models.EmergencyContact) int { return cmp.Compare(a.Priority, b.Priority) })
+	return contacts, nil
+}
+
+func (service *EmergencyContactService) contact(ctx context.Context, profileID, id string) (*models.EmergencyContact, error) {
+	contact, err := service.tables.contact.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) || (err == nil && contact.ProfileID != profileID) {
+		return nil, ErrContactNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	return contact, nil
+}
+
+func (service *EmergencyContactService) List(ctx context.Context, userID, profileID string) ([]dto.ResponseEmergencyContact, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := listContacts(ctx, service.tables.contact, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Map(contacts, func(row *models.EmergencyContact, _ int) dto.ResponseEmergencyContact { return contactResponse(row) }), nil
+}
+
+// Create appends a contact at the lowest priority.
+func (service *EmergencyContactService) Create(ctx context.Context, userID, profileID string, body *dto.RequestEmergencyContact) (*dto.ResponseEmergencyContact, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := listContacts(ctx, service.tables.contact, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(contacts) >= emergencyContactsMax {
+		return nil, ErrTooManyContacts
+	}
+
+	number, err := phone.Parse(body.CountryCode, body.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	contact := &models.EmergencyContact{
+		ID:          ulid.Make().String(),
+		ProfileID:   profile.ID,
+		Priority:    len(contacts) + 1,
+		Relation:    body.Relation,
+		Name:        body.Name,
+		CountryCode: number.CallingCode,
+		Phone:       number.E164(),
+		Consent:     constants.ConsentNone,
+		CreatedAt:   time.Now(),
+	}
+
+	err = service.tables.contact.Create(ctx, contact)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := contactResponse(contact)
+	return &res, nil
+}
+
+// Update replaces a contact. A new number has to consent again.
+func (service *EmergencyContactService) Update(ctx context.Context, userID, profileID, id string, body *dto.RequestEmergencyContact) (*dto.ResponseEmergencyContact, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, err := service.contact(ctx, profile.ID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	number, err := phone.Parse(body.CountryCode, body.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	record := goqu.Record{
+		"relation":     body.Relation,
+		"name":         body.Name,
+		"country_code": number.CallingCode,
+		"phone":        number.E164(),
+	}
+
+	// consent was given by the old number, and its cooldown does not apply to the new one
+	if number.E164() != contact.Phone {
+		contact.Consent = constants.ConsentNone
+		contact.ConsentToken = nil
+		contact.ConsentSentAt = sql.NullTime{}
+		contact.ConsentAt = sql.NullTime{}
+		record["consent"] = constants.ConsentNone
+		record["consent_token"] = nil
+		record["consent_sent_at"] = nil
+		record["consent_at"] = nil
+	}
+
+	contact.Relation = body.Relation
+	contact.Name = body.Name
+	contact.CountryCode = number.CallingCode
+	contact.Phone = number.E164()
+
+	// cleared consent columns would be left out of Update
+	stmt, args, err := goqu.Dialect("postgres").Update(repository.Tables.Contact).
+		Set(record).
+		Where(goqu.C("id").Eq(contact.ID)).
+		ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := service.tables.contact.Raw(ctx, stmt, args...); err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := contactResponse(contact)
+	return &res, nil
+}
+
+// Delete removes a contact and closes the gap it leaves in the priorities.
+func (service *EmergencyContactService) Delete(ctx context.Context, userID, profileID, id string) error {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return err
+	}
+
+	contact, err := service.contact(ctx, profile.ID, id)
+	if err != nil {
+		return err
+	}
+
+	err = service.tables.contact.Delete(ctx, contact.ID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	contacts, err := listContacts(ctx, service.tables.contact, profile.ID)
+	if err != nil {
+		return err
+	}
+
+	return service.renumber(ctx, contacts)
+}
+
+// Reorder sets the priorities to the order of ids, which has to list every
+// contact of the profile exactly once.
+func (service *EmergencyContactService) Reorder(ctx context.Context, userID, profileID string, ids []string) ([]dto.ResponseEmergencyContact, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := listContacts(ctx, service.tables.contact, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := make([]*models.EmergencyContact, 0, len(contacts))
+	for _, id := range ids {
+		i := slices.IndexFunc(contacts, func(c *models.EmergencyContact) bool { return c.ID == id })
+		if i < 0 || slices.Contains(ordered, contacts[i]) {
+			return nil, ErrContactOrder
+		}
+
+		ordered = append(ordered, contacts[i])
+	}
+
+	if len(ordered) != len(contacts) {
+		return nil, ErrContactOrder
+	}
+
+	err = service.renumber(ctx, ordered)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Map(ordered, func(row *models.EmergencyContact, _ int) dto.ResponseEmergencyContact { return contactResponse(row) }), nil
+}
+
+func (service *EmergencyContactService) renumber(ctx context.Context, contacts []*models.EmergencyContact) error {
+	for i, contact := range contacts {
+		if contact.Priority == i+1 {
+			continue
+		}
+
+		contact.Priority = i + 1
+		err := service.tables.contact.Update(ctx, contact.ID, contact)
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+	}
+
+	return nil
+}
+
+// RequestConsent texts the contact a link to accept or decline being listed,
+// at most once per contactConsentCooldown.
+func (service *EmergencyContactService) RequestConsent(ctx context.Context, userID, profileID, id string) (*dto.ResponseEmergencyContact, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, err := service.contact(ctx, profile.ID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if contact.Consent == constants.ConsentAccepted {
+		return nil, ErrConsentAnswered
+	}
+
+	now := time.Now()
+	if contact.ConsentSentAt.Valid && now.Before(contact.ConsentSentAt.Time.Add(contactConsentCooldown)) {
+		return nil, ErrTooManyRequests
+	}
+
+	token, err := utils.RandSecureAlphanumericString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	contact.Consent = constants.ConsentPending
+	contact.ConsentToken = &token
+	contact.ConsentSentAt = sql.NullTime{Time: now, Valid: true}
+	err = service.tables.contact.Update(ctx, contact.ID, contact)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	link := service.consentURL + "?token=" + url.QueryEscape(token)
+	message := contactConsentMessages[mail.NormalizeLocale(profileLocale(profile))]
+	err = service.sender.Send(ctx, contact.Phone, fmt.Sprintf(message, profile.Name, link))
+	if err != nil {
+		return nil, err
+	}
+
+	res := contactResponse(contact)
+	return &res, nil
+}
+
+// AnswerConsent records the answer of the contact, who has no account and is
+// only identified by the token of the sms.
+func (service *EmergencyContactService) AnswerConsent(ctx context.Context, body *dto.RequestContactConsent) error {
+	now := time.Now()
+	contacts, err := service.tables.contact.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("consent_token").Eq(body.Token),
+			goqu.C("consent").Eq(constants.ConsentPending),
+			goqu.C("consent_sent_at").Gt(now.Add(-contactConsentTTL)),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(contacts) == 0 {
+		return ErrConsentInvalid
+	}
+
+	contact := contacts[0]
+	contact.Consent = utils.Ternary(*body.Accept, constants.ConsentAccepted, constants.ConsentDeclined)
+	contact.ConsentToken = nil
+	contact.ConsentAt = sql.NullTime{Time: now, Valid: true}
+	err = service.tables.contact.Update(ctx, contact.ID, contact)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// NormalizePhones rewrites the numbers of contacts carried over from the
+// profile, which were stored as typed, in E.164. Numbers that do not parse
+// are left for the profile to correct.
+func (service *EmergencyContactService) NormalizePhones(ctx context.Context) error {
+	contacts, err := listAll(ctx, service.tables.contact, goqu.C("phone").NotLike("+%"))
+	if err != nil {
+		return err
+	}
+
+	for _, contact := range contacts {
+		number, err := phone.Parse(contact.CountryCode, contact.Phone)
+		if err != nil {
+			logrus.Warnf("failed to normalize emergency contact phone: %s; err: %s", contact.ID, err)
+			continue
+		}
+
+		// the consent token is always written, it is kept as it is
+		err = service.tables.contact.Update(ctx, contact.ID, &models.EmergencyContact{
+			CountryCode:  number.CallingCode,
+			Phone:        number.E164(),
+			ConsentToken: contact.ConsentToken,
+		})
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+	}
+
+	return nil
+}
+
+// GetPatientContacts returns the contacts to clinic staff, only while the
+// patient has an appointment around now at a clinic of theirs.
+func (service *EmergencyContactService) GetPatientContacts(ctx context.Context, staffID, profileID string) ([]dto.ResponseEmergencyContact, error) {
+	profile, err := service.userService.tables.profile.Get(ctx, profileID)
+	if errors.Is(err, ErrNoResult) {
+		return nil, ErrProfileNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	err = patientAccess(ctx, service.tables.event, service.tables.audit, staffID, profile, constants.AuditViewPatientContacts)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := listContacts(ctx, service.tables.contact, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Map(contacts, func(row *models.EmergencyContact, _ int) dto.ResponseEmergencyContact { return contactResponse(row) }), nil
+}
+
+func contactResponse(row *models.EmergencyContact) dto.ResponseEmergencyContact {
+	return dto.ResponseEmergencyContact{
+		ID:        row.ID,
+		Priority:  row.Priority,
+		Relation:  row.Relation,
+		Name:      row.Name,
+		Phone:     row.Phone,
+		Consent:   row.Consent,
+		ConsentAt: utils.Ternary(row.ConsentAt.Valid, &row.ConsentAt.Time, nil),
+	}
+}
@@ -11,4 +11,45 @@ var (
 	ErrValidationFailed      = errors.New("validation failed")
 	ErrPasswordHashingFailed = errors.New("failed to hash password")
 	ErrNoResult              = repository.ErrNoResult
+	ErrUserNotFound          = errors.New("user does not exist")
+	ErrInvalidPassword       = errors.New("invalid password")
+	ErrPasswordNotManaged    = errors.New("password is managed by the sign-in provider")
+	ErrHandleExist           = errors.New("email is already used by another account")
+	ErrSessionRevoked        = errors.New("session has been revoked")
+	ErrEmailAlreadyVerified  = errors.New("email is already verified")
+	ErrTooManyRequests       = errors.New("too many requests, please try again later")
+	ErrMFANotEnrolled        = errors.New("two-factor authentication is not enrolled")
+	ErrMFAAlreadyEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrMFARequired           = errors.New("two-factor authentication is required for this account")
+	ErrMFAInvalidCode        = errors.New("invalid two-factor authentication code")
+	ErrMFAChallengeInvalid   = errors.New("two-factor login challenge is invalid or expired")
+	ErrDeletionPending       = errors.New("account deletion has already been requested")
+	ErrDeletionNotFound      = errors.New("no pending account deletion")
+	ErrExportNotFound        = errors.New("data export not found")
+	ErrPhoneAlreadyVerified  = errors.New("phone is already verified")
+	ErrPhoneNotVerified      = errors.New("phone is not verified")
+	ErrOTPInvalid            = errors.New("invalid or expired verification code")
+	ErrNIKMismatch           = errors.New("does not match the nik")
+	ErrProfileNotFound       = errors.New("profile not found")
+	ErrProfileExist          = errors.New("profile already exist")
+	ErrNotGuardian           = errors.New("not a guardian of this profile")
+	ErrDependentUnderage     = errors.New("dependent is not of age to take over the profile")
+	ErrHandoverInvalid       = errors.New("profile handover is invalid or expired")
+	ErrMedicalRecordNotFound = errors.New("medical record not found")
+	ErrNotStaff              = errors.New("account is not staff")
+	ErrClinicNotFound        = errors.New("clinic not found")
+	ErrClinicStaffNotFound   = errors.New("account is not assigned to this clinic")
+	ErrNoAppointment         = errors.New("no appointment with this patient at this time")
+	ErrContactNotFound       = errors.New("emergency contact not found")
+	ErrTooManyContacts       = errors.New("too many emergency contacts")
+	ErrContactOrder          = errors.New("order has to list every emergency contact once")
+	ErrConsentAnswered       = errors.New("emergency contact has already consented")
+	ErrConsentInvalid        = errors.New("consent request is invalid or expired")
+	ErrAccountDisabled       = errors.New("account has been disabled")
+	ErrAdminSelf             = errors.New("admins cannot change their own account")
+	ErrIdentityNotFound      = errors.New("sign-in method not found")
+	ErrIdentityLinked        = errors.New("sign-in method is linked to another account")
+	ErrIdentityUnverified    = errors.New("email belongs to an account this sign-in method is not linked to")
+	ErrLastIdentity          = errors.New("the last sign-in method cannot be unlinked")
+	ErrIdentityUnlinked      = errors.New("sign-in method was unlinked, link it again from the account")
 )
@@ -0,0 +1,512 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/internal/storage"
+	"monorepo/pkg/common"
+	"monorepo/pkg/utils"
+	calendarModels "monorepo/services/calendar/models"
+	fitnessModel "monorepo/services/fitness/model"
+	notificationModels "monorepo/services/notification/models"
+	"monorepo/services/user/models"
+	"strconv"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	exportLinkTTL   = time.Minute * 15
+	exportBatchSize = 5
+	exportPageSize  = 100
+
+	// archives hold all personal data of the account, they are purged once
+	// the download window is over
+	exportRetention = time.Hour * 24
+)
+
+func NewExportService(
+	tbProfile common.Repository[models.Profile, string],
+	tbSession common.Repository[models.Session, string],
+	tbExport common.Repository[models.DataExport, string],
+	tbWeightGoal common.Repository[fitnessModel.WeightGoal, string],
+	tbWeightHistory common.Repository[fitnessModel.WeightHistory, string],
+	tbFoodDiary common.Repository[fitnessModel.FoodDiary, string],
+	tbExerciseLog common.Repository[fitnessModel.ExerciseLog, string],
+	tbCalorieAdjustment common.Repository[fitnessModel.CalorieAdjustment, string],
+	tbBodyMeasurement common.Repository[fitnessModel.BodyMeasurement, string],
+	tbEvent common.Repository[calendarModels.Event, string],
+	tbUserMessage common.Repository[notificationModels.UserMessage, string],
+	tbAllergy common.Repository[models.Allergy, string],
+	tbCondition common.Repository[models.Condition, string],
+	tbMedication common.Repository[models.Medication, string],
+	tbContact common.Repository[models.EmergencyContact, string],
+	store storage.Storage,
+) *ExportService {
+	service := &ExportService{}
+	service.storage = store
+	service.tables.profile = tbProfile
+	service.tables.session = tbSession
+	service.tables.export = tbExport
+	service.tables.weightGoal = tbWeightGoal
+	service.tables.weightHistory = tbWeightHistory
+	service.tables.foodDiary = tbFoodDiary
+	service.tables.exerciseLog = tbExerciseLog
+	service.tables.calorieAdjustment = tbCalorieAdjustment
+	service.tables.bodyMeasurement = tbBodyMeasurement
+	service.tables.event = tbEvent
+	service.tables.userMessage = tbUserMessage
+	service.tables.allergy = tbAllergy
+	service.tables.condition = tbCondition
+	service.tables.medication = tbMedication
+	service.tables.contact = tbContact
+
+	return service
+}
+
+// ExportService builds personal data archives. Data of other services is read
+// through the shared database, the same way DeletionService erases it.
+type ExportService struct {
+	storage storage.Storage
+	tables  struct {
+		profile           common.Repository[models.Profile, string]
+		session           common.Repository[models.Session, string]
+		export            common.Repository[models.DataExport, string]
+		weightGoal        common.Repository[fitnessModel.WeightGoal, string]
+		weightHistory     common.Repository[fitnessModel.WeightHistory, string]
+		foodDiary         common.Repository[fitnessModel.FoodDiary, string]
+		exerciseLog       common.Repository[fitnessModel.ExerciseLog, string]
+		calorieAdjustment common.Repository[fitnessModel.CalorieAdjustment, string]
+		bodyMeasurement   common.Repository[fitnessModel.BodyMeasurement, string]
+		event             common.Repository[calendarModels.Event, string]
+		userMessage       common.Repository[notificationModels.UserMessage, string]
+		allergy           common.Repository[models.Allergy, string]
+		condition         common.Repository[models.Condition, string]
+		medication        common.Repository[models.Medication, string]
+		contact           common.Repository[models.EmergencyContact, string]
+	}
+}
+
+// listAll pages through every row matching filter.
+func listAll[T any](ctx context.Context, repo common.Repository[T, string], filter ...exp.Expression) ([]*T, error) {
+	var res []*T
+	for page := 1; ; page++ {
+		rows, err := repo.List(ctx, &common.FilterOptions{
+			Sort:   []exp.OrderedExpression{goqu.I("created_at").Asc()},
+			Filter: filter,
+			Page:   page,
+			Limit:  exportPageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+		}
+
+		res = append(res, rows...)
+		if len(rows) < exportPageSize {
+			return res, nil
+		}
+	}
+}
+
+func (service *ExportService) RequestExport(ctx context.Context, userID string) (*dto.ResponseDataExport, error) {
+	existing, err := service.tables.export.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("user_id").Eq(userID),
+			goqu.C("status").Eq(constants.ExportPending),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	// an export in progress already covers this request
+	if len(existing) > 0 {
+		return service.toResponse(ctx, existing[0])
+	}
+
+	export := models.DataExport{
+		ID:        ulid.Make().String(),
+		UserID:    userID,
+		Status:    constants.ExportPending,
+		CreatedAt: time.Now(),
+	}
+	err = service.tables.export.Create(ctx, &export)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return service.toResponse(ctx, &export)
+}
+
+// GetExport returns the export state, with a short-lived download link once the archive is ready.
+func (service *ExportService) GetExport(ctx context.Context, userID, exportID string) (*dto.ResponseDataExport, error) {
+	export, err := service.tables.export.Get(ctx, exportID)
+	if err != nil || export.UserID != userID {
+		return nil, ErrExportNotFound
+	}
+
+	return service.toResponse(ctx, export)
+}
+
+// Run builds pending exports every interval until the context is done.
+func (service *ExportService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := service.ProcessPending(ctx)
+			if err != nil {
+				logrus.Errorf("failed to process data exports; err: %s", err)
+			}
+
+			err = service.PurgeExpired(ctx)
+			if err != nil {
+				logrus.Errorf("failed to purge data exports; err: %s", err)
+			}
+		}
+	}
+}
+
+func (service *ExportService) ProcessPending(ctx context.Context) error {
+	pending, err := service.tables.export.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Asc()},
+		Filter: []exp.Expression{goqu.C("status").Eq(constants.ExportPending)},
+		Page:   1,
+		Limit:  exportBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	for _, export := range pending {
+		update := models.DataExport{
+			Status:      constants.ExportCompleted,
+			FileName:    fmt.Sprintf("exports/%s/%s.zip", export.UserID, export.ID),
+			CompletedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		}
+
+		err = service.build(ctx, export.UserID, update.FileName)
+		if err != nil {
+			logrus.Errorf("failed to build data export: %s; export: %s; err: %s", export.UserID, export.ID, err)
+			update = models.DataExport{Status: constants.ExportFailed, Error: err.Error()}
+		}
+
+		err = service.tables.export.Update(ctx, export.ID, &update)
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+	}
+
+	return nil
+}
+
+// PurgeExpired deletes the archives whose download window is over, the export
+// stays as expired.
+func (service *ExportService) PurgeExpired(ctx context.Context) error {
+	expired, err := service.tables.export.List(ctx, &common.FilterOptions{
+		Sort: []exp.OrderedExpression{goqu.I("completed_at").Asc()},
+		Filter: []exp.Expression{
+			goqu.C("status").Eq(constants.ExportCompleted),
+			goqu.C("completed_at").Lt(time.Now().Add(-exportRetention)),
+		},
+		Page:  1,
+		Limit: exportPageSize,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	for _, export := range expired {
+		err = service.storage.Delete(ctx, export.FileName)
+		if err != nil {
+			return err
+		}
+
+		err = service.tables.export.Update(ctx, export.ID, &models.DataExport{Status: constants.ExportExpired})
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+	}
+
+	return nil
+}
+
+func (service *ExportService) build(ctx context.Context, userID, fileName string) error {
+	profiles, err := listAll(ctx, service.tables.profile, goqu.C("user_id").Eq(userID))
+	if err != nil {
+		return err
+	}
+
+	profileIDs := utils.Map(profiles, func(row *models.Profile, _ int) string { return row.ID })
+
+	var goals []*fitnessModel.WeightGoal
+	var histories []*fitnessModel.WeightHistory
+	var diary []*fitnessModel.FoodDiary
+	var workouts []*fitnessModel.ExerciseLog
+	var adjustments []*fitnessModel.CalorieAdjustment
+	var measurements []*fitnessModel.BodyMeasurement
+	var events []*calendarModels.Event
+	var allergies []*models.Allergy
+	var conditions []*models.Condition
+	var medications []*models.Medication
+	var contacts []*models.EmergencyContact
+	if len(profileIDs) > 0 {
+		goals, err = listAll(ctx, service.tables.weightGoal, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+
+		histories, err = listAll(ctx, service.tables.weightHistory, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+
+		diary, err = listAll(ctx, service.tables.foodDiary, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+
+		workouts, err = listAll(ctx, service.tables.exerciseLog, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+
+		adjustments, err = listAll(ctx, service.tables.calorieAdjustment, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+
+		measurements, err = listAll(ctx, service.tables.bodyMeasurement, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+
+		events, err = listAll(ctx, service.tables.event,
+			goqu.C("profile_id").In(profileIDs),
+			goqu.C("type").Eq(constants.Appointment),
+		)
+		if err != nil {
+			return err
+		}
+
+		allergies, err = listAll(ctx, service.tables.allergy, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+
+		conditions, err = listAll(ctx, service.tables.condition, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+
+		medications, err = listAll(ctx, service.tables.medication, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+
+		contacts, err = listAll(ctx, service.tables.contact, goqu.C("profile_id").In(profileIDs))
+		if err != nil {
+			return err
+		}
+	}
+
+	messages, err := listAll(ctx, service.tables.userMessage, goqu.C("user_id").Eq(userID))
+	if err != nil {
+		return err
+	}
+
+	sessions, err := listAll(ctx, service.tables.session, goqu.C("user_id").Eq(userID))
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	files := []struct {
+		name   string
+		data   any
+		header []string
+		rows   [][]string
+	}{
+		{name: "profile", data: profiles},
+		{name: "medical_background", data: map[string]any{"allergies": allergies, "conditions": conditions, "medications": medications}},
+		{name: "emergency_contacts", data: contacts},
+		{
+			name: "weight_goals", data: goals,
+			header: []string{"id", "profile_id", "starting_weight", "starting_date", "target_weight", "target_date", "daily_calories_budget", "calories_to_maintain", "flag", "activity_level", "pace", "status", "achieved_at", "ended_at", "end_weight", "created_at"},
+			rows: utils.Map(goals, func(row *fitnessModel.WeightGoal, _ int) []string {
+				return []string{row.ID, row.ProfileID, formatFloat(row.StartingWeight), formatTime(row.StartingDate), formatFloat(row.TargetWeight), formatTime(row.TargetDate),
+					formatFloat(row.DailyCalorieBudget), formatFloat(row.CaloriesToMaintain), row.Flag, row.ActivityLevel, row.Pace,
+					row.Status, formatNullTime(row.AchievedAt), formatNullTime(row.EndedAt), formatFloat(row.EndWeight), formatTime(row.CreatedAt)}
+			}),
+		},
+		{
+			name: "weight_history", data: histories,
+			header: []string{"id", "profile_id", "weight", "created_at"},
+			rows: utils.Map(histories, func(row *fitnessModel.WeightHistory, _ int) []string {
+				return []string{row.ID, row.ProfileID, formatFloat(row.Weight), formatTime(row.CreatedAt)}
+			}),
+		},
+		{
+			name: "food_diary", data: diary,
+			header: []string{"id", "profile_id", "date", "meal", "food_name", "amount", "unit", "grams", "energy", "protein", "carbohydrate", "fat", "fibre", "created_at"},
+			rows: utils.Map(diary, func(row *fitnessModel.FoodDiary, _ int) []string {
+				return []string{row.ID, row.ProfileID, row.Date.Format(time.DateOnly), row.Meal, row.FoodName, formatFloat(row.Amount), row.Unit, formatFloat(row.Grams),
+					formatFloat(row.Energy), formatFloat(row.Protein), formatFloat(row.Carbohydrate), formatFloat(row.Fat), formatFloat(row.Fibre), formatTime(row.CreatedAt)}
+			}),
+		},
+		{
+			name: "exercise_log", data: workouts,
+			header: []string{"id", "profile_id", "date", "activity", "duration", "intensity", "distance", "met", "weight", "calories", "created_at"},
+			rows: utils.Map(workouts, func(row *fitnessModel.ExerciseLog, _ int) []string {
+				return []string{row.ID, row.ProfileID, row.Date.Format(time.DateOnly), row.Activity, strconv.Itoa(row.Duration), row.Intensity, formatFloat(row.Distance),
+					formatFloat(row.MET), formatFloat(row.Weight), formatFloat(row.Calories), formatTime(row.CreatedAt)}
+			}),
+		},
+		{
+			name: "calorie_adjustments", data: adjustments,
+			header: []string{"id", "profile_id", "weight_goal_id", "source", "confidence", "days", "intake", "intake_source", "weight_change",
+				"previous_calories_to_maintain", "calories_to_maintain", "previous_daily_calories_budget", "daily_calories_budget", "created_at"},
+			rows: utils.Map(adjustments, func(row *fitnessModel.CalorieAdjustment, _ int) []string {
+				return []string{row.ID, row.ProfileID, row.WeightGoalID, row.Source, row.Confidence, strconv.Itoa(row.Days), formatFloat(row.Intake), row.IntakeSource, formatFloat(row.WeightChange),
+					formatFloat(row.PreviousCaloriesToMaintain), formatFloat(row.CaloriesToMaintain), formatFloat(row.PreviousDailyCalorieBudget), formatFloat(row.DailyCalorieBudget), formatTime(row.CreatedAt)}
+			}),
+		},
+		{
+			name: "body_measurements", data: measurements,
+			header: []string{"id", "profile_id", "date", "waist", "hip", "neck", "chest", "body_fat", "created_at"},
+			rows: utils.Map(measurements, func(row *fitnessModel.BodyMeasurement, _ int) []string {
+				return []string{row.ID, row.ProfileID, row.Date.Format(time.DateOnly), formatNullFloat(row.Waist), formatNullFloat(row.Hip), formatNullFloat(row.Neck),
+					formatNullFloat(row.Chest), formatNullFloat(row.BodyFat), formatTime(row.CreatedAt)}
+			}),
+		},
+		{
+			name: "appointments", data: events,
+			header: []string{"id", "location_id", "status", "start_time", "end_time", "created_at"},
+			rows: utils.Map(events, func(row *calendarModels.Event, _ int) []string {
+				return []string{row.ID, row.LocationID, row.Status, formatTime(row.StartTime), formatTime(row.EndTime), formatTime(row.CreatedAt)}
+			}),
+		},
+		{
+			name: "notifications", data: messages,
+			header: []string{"message_id", "created_at", "read_at"},
+			rows: utils.Map(messages, func(row *notificationModels.UserMessage, _ int) []string {
+				return []string{row.MessageID, formatTime(row.CreatedAt), formatNullTime(row.ReadAt)}
+			}),
+		},
+		{
+			name: "login_history", data: utils.Map(sessions, func(row *models.Session, _ int) map[string]any {
+				return map[string]any{"session_id": row.ID, "created_at": row.CreatedAt, "revoked_at": utils.Ternary(row.RevokedAt.Valid, &row.RevokedAt.Time, nil)}
+			}),
+			header: []string{"session_id", "created_at", "revoked_at"},
+			rows: utils.Map(sessions, func(row *models.Session, _ int) []string {
+				return []string{row.ID, formatTime(row.CreatedAt), formatNullTime(row.RevokedAt)}
+			}),
+		},
+	}
+
+	for _, file := range files {
+		w, err := zw.Create(file.name + ".json")
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(file.data)
+		if err != nil {
+			return err
+		}
+
+		if file.header == nil {
+			continue
+		}
+
+		w, err = zw.Create(file.name + ".csv")
+		if err != nil {
+			return err
+		}
+
+		cw := csv.NewWriter(w)
+		cw.Write(file.header)
+		cw.WriteAll(file.rows)
+		if err = cw.Error(); err != nil {
+			return err
+		}
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+
+	return service.storage.Put(ctx, fileName, buf, "application/zip", true)
+}
+
+func (service *ExportService) toResponse(ctx context.Context, export *models.DataExport) (*dto.ResponseDataExport, error) {
+	res := dto.ResponseDataExport{
+		ID:        export.ID,
+		Status:    export.Status,
+		CreatedAt: export.CreatedAt,
+	}
+
+	// links never outlive the archive, which may be waiting to be purged
+	ttl := min(exportLinkTTL, time.Until(export.CompletedAt.Time.Add(exportRetention)))
+	if export.Status == constants.ExportCompleted && ttl <= 0 {
+		res.Status = constants.ExportExpired
+	} else if export.Status == constants.ExportCompleted {
+		link, err := service.storage.SignedURL(ctx, export.FileName, ttl)
+		if err != nil {
+			return nil, err
+		}
+
+		expiresAt := time.Now().Add(ttl)
+		res.DownloadUrl = &link
+		res.DownloadExpiresAt = &expiresAt
+		res.CompletedAt = &export.CompletedAt.Time
+	}
+
+	return &res, nil
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatNullFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+
+	return formatFloat(*v)
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+
+	return formatTime(t.Time)
+}
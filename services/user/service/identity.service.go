@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"monorepo/internal/dto"
+	"monorepo/internal/repository"
+	"monorepo/pkg/common"
+	"monorepo/services/user/models"
+	"time"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+)
+
+const identityLimit = 20
+
+func NewIdentityService(
+	userService *UserService,
+	tbUser common.Repository[models.User, string],
+	tbIdentity common.Repository[models.UserIdentity, string],
+	fbaClient *auth.Client,
+) *IdentityService {
+	service := &IdentityService{userService: userService, fbaClient: fbaClient}
+	service.tables.user = tbUser
+	service.tables.identity = tbIdentity
+
+	return service
+}
+
+// IdentityService resolves firebase sign-ins to accounts. An account can have
+// several sign-in providers linked, each known by the provider's own subject,
+// so a provider that hides the email (e.g. apple private relay) still reaches
+// the same account.
+type IdentityService struct {
+	userService *UserService
+	fbaClient   *auth.Client
+	tables      struct {
+		user     common.Repository[models.User, string]
+		identity common.Repository[models.UserIdentity, string]
+	}
+}
+
+// SignIn returns the account the id token signs in to. Unknown identities are
+// linked to the account holding the same email, verified by both the account
+// and the provider, or to a legacy account created through the same provider
+// before identities were tracked.
+// Otherwise a new account is registered from body and reported as created.
+// Identities that were unlinked before have to be linked again with Link.
+func (service *IdentityService) SignIn(ctx context.Context, token *auth.Token, body *dto.RequestRegisterUser) (*models.User, bool, error) {
+	identity := tokenIdentity(token)
+
+	existing, err := service.find(ctx, goqu.C("provider").Eq(identity.Provider), goqu.C("subject").Eq(identity.Subject))
+	if err != nil {
+		return nil, false, err
+	} else if existing != nil {
+		user, err := service.userService.GetUser(ctx, existing.UserID)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return user, false, service.verified(ctx, user, identity)
+	}
+
+	// a sign-in method that was unlinked is only linked again explicitly
+	unlinked, err := service.unlinked(ctx, identity)
+	if err != nil {
+		return nil, false, err
+	} else if unlinked {
+		return nil, false, ErrIdentityUnlinked
+	}
+
+	users, err := service.tables.user.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("handle").Eq(identity.Email)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(users) > 0 {
+		user := users[0]
+		// the email proves the account only when both sides verified it, else an
+		// account registered with someone else's address would stay open to its
+		// registrant once the owner signs in
+		if !identity.EmailVerified || !user.EmailVerified {
+			linked, err := service.find(ctx, goqu.C("user_id").Eq(user.ID))
+			if err != nil {
+				return nil, false, err
+			}
+
+			// a legacy account signs in through the provider it was created with
+			if linked != nil || user.Provider != identity.Provider {
+				return nil, false, ErrIdentityUnverified
+			}
+		}
+
+		if err := service.verified(ctx, user, identity); err != nil {
+			return nil, false, err
+		}
+
+		return user, false, service.link(ctx, user.ID, identity)
+	}
+
+	if identity.EmailVerified {
+		linked, err := service.find(ctx, goqu.C("email").Eq(identity.Email), goqu.C("email_verified").IsTrue())
+		if err != nil {
+			return nil, false, err
+		} else if linked != nil {
+			user, err := service.userService.GetUser(ctx, linked.UserID)
+			if err != nil {
+				return nil, false, err
+			}
+
+			return user, false, service.link(ctx, user.ID, identity)
+		}
+	}
+
+	body.Provider = identity.Provider
+	body.Email = identity.Email
+	body.EmailVerified = identity.EmailVerified
+
+	user, err := service.userService.RegisterUser(ctx, body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return user, true, service.link(ctx, user.ID, identity)
+}
+
+// verified marks the handle of user verified once a provider vouches for it.
+func (service *IdentityService) verified(ctx context.Context, user *models.User, identity *models.UserIdentity) error {
+	if user.EmailVerified || !identity.EmailVerified || identity.Email != user.Handle {
+		return nil
+	}
+
+	err := service.tables.user.Update(ctx, user.ID, &models.User{EmailVerified: true})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	user.EmailVerified = true
+	return nil
+}
+
+// List returns the sign-in methods linked to the account.
+func (service *IdentityService) List(ctx context.Context, userID string) ([]dto.ResponseIdentity, error) {
+	identities, err := service.tables.identity.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Asc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(userID)},
+		Page:   1,
+		Limit:  identityLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	res := make([]dto.ResponseIdentity, 0, len(identities))
+	for _, identity := range identities {
+		res = append(res, identityResponse(identity))
+	}
+
+	return res, nil
+}
+
+// Link adds the provider the id token was issued by to the account.
+func (service *IdentityService) Link(ctx context.Context, userID string, body *dto.RequestLinkIdentity) (*dto.ResponseIdentity, error) {
+	token, err := service.fbaClient.VerifyIDToken(ctx, body.IDToken)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := tokenIdentity(token)
+	existing, err := service.find(ctx, goqu.C("provider").Eq(identity.Provider), goqu.C("subject").Eq(identity.Subject))
+	if err != nil {
+		return nil, err
+	} else if existing != nil && existing.UserID != userID {
+		return nil, ErrIdentityLinked
+	} else if existing != nil {
+		res := identityResponse(existing)
+		return &res, nil
+	}
+
+	err = service.link(ctx, userID, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	res := identityResponse(identity)
+	return &res, nil
+}
+
+// Unlink removes a sign-in method, the account has to keep at least one.
+func (service *IdentityService) Unlink(ctx context.Context, userID, identityID string) error {
+	identities, err := service.tables.identity.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("user_id").Eq(userID)},
+		Page:   1,
+		Limit:  identityLimit,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	found := false
+	for _, identity := range identities {
+		found = found || identity.ID == identityID
+	}
+
+	if !found {
+		return ErrIdentityNotFound
+	} else if len(identities) == 1 {
+		return ErrLastIdentity
+	}
+
+	err = service.tables.identity.Delete(ctx, identityID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+func (service *IdentityService) find(ctx context.Context, filter ...exp.Expression) (*models.UserIdentity, error) {
+	identities, err := service.tables.identity.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Asc()},
+		Filter: filter,
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(identities) == 0 {
+		return nil, nil
+	}
+
+	return identities[0], nil
+}
+
+// unlinked reports whether the identity was linked to an account and unlinked
+// since. Unlinked rows are soft deleted, so they are looked up through the
+// accounts they belonged to.
+func (service *IdentityService) unlinked(ctx context.Context, identity *models.UserIdentity) (bool, error) {
+	removed := goqu.From(repository.Tables.Identity).
+		Select("user_id").
+		Where(
+			goqu.C("provider").Eq(identity.Provider),
+			goqu.C("subject").Eq(identity.Subject),
+			goqu.C("deleted_at").IsNotNull(),
+		)
+
+	users, err := service.tables.user.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("id").In(removed)},
+		Select: []any{"id"},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return false, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	return len(users) > 0, nil
+}
+
+func (service *IdentityService) link(ctx context.Context, userID string, identity *models.UserIdentity) error {
+	identity.ID = ulid.Make().String()
+	identity.UserID = userID
+	identity.CreatedAt = time.Now()
+
+	err := service.tables.identity.Create(ctx, identity)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// tokenIdentity reads the provider subject from the identities claim, e.g.
+// {"google.com": ["1234"]}. Email sign-ins have no such entry and use the firebase uid.
+func tokenIdentity(token *auth.Token) *models.UserIdentity {
+	identity := &models.UserIdentity{
+		Provider:    token.Firebase.SignInProvider,
+		Subject:     token.UID,
+		FirebaseUID: token.UID,
+	}
+	identity.Email, _ = token.Claims["email"].(string)
+	identity.EmailVerified, _ = token.Claims["email_verified"].(bool)
+
+	if ids, ok := token.Firebase.Identities[identity.Provider].([]any); ok && len(ids) > 0 {
+		if subject, ok := ids[0].(string); ok && subject != "" {
+			identity.Subject = subject
+		}
+	}
+
+	return identity
+}
+
+func identityResponse(identity *models.UserIdentity) dto.ResponseIdentity {
+	return dto.ResponseIdentity{
+		ID:        identity.ID,
+		Provider:  identity.Provider,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
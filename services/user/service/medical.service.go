@@ -0,0 +1,554 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/internal/repository"
+	"monorepo/pkg/common"
+	"monorepo/pkg/medcode"
+	"monorepo/pkg/utils"
+	calendarModels "monorepo/services/calendar/models"
+	"monorepo/services/user/models"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+)
+
+// appointmentWindow is how long before and after an appointment clinic staff
+// may read the records of the patient.
+const appointmentWindow = time.Hour
+
+func NewMedicalService(
+	userService *UserService,
+	tbProfile common.Repository[models.Profile, string],
+	tbAllergy common.Repository[models.Allergy, string],
+	tbCondition common.Repository[models.Condition, string],
+	tbMedication common.Repository[models.Medication, string],
+	tbContact common.Repository[models.EmergencyContact, string],
+	tbEvent common.Repository[calendarModels.Event, string],
+	tbAudit common.Repository[models.AdminAuditLog, string],
+) *MedicalService {
+	service := &MedicalService{}
+	service.userService = userService
+	service.tables.profile = tbProfile
+	service.tables.allergy = tbAllergy
+	service.tables.condition = tbCondition
+	service.tables.medication = tbMedication
+	service.tables.contact = tbContact
+	service.tables.event = tbEvent
+	service.tables.audit = tbAudit
+
+	return service
+}
+
+// MedicalService keeps the medical background of a profile. Every method
+// acts on the profile the request acts as, see UserService.ActingProfile.
+type MedicalService struct {
+	userService *UserService
+	tables      struct {
+		profile    common.Repository[models.Profile, string]
+		allergy    common.Repository[models.Allergy, string]
+		condition  common.Repository[models.Condition, string]
+		medication common.Repository[models.Medication, string]
+		contact    common.Repository[models.EmergencyContact, string]
+		event      common.Repository[calendarModels.Event, string]
+		audit      common.Repository[models.AdminAuditLog, string]
+	}
+}
+
+func (service *MedicalService) ListAllergies(ctx context.Context, userID, profileID string) ([]dto.ResponseAllergy, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	allergies, err := listAll(ctx, service.tables.allergy, goqu.C("profile_id").Eq(profile.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Map(allergies, func(row *models.Allergy, _ int) dto.ResponseAllergy { return allergyResponse(row) }), nil
+}
+
+func (service *MedicalService) CreateAllergy(ctx context.Context, userID, profileID string, body *dto.RequestAllergy) (*dto.ResponseAllergy, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	allergy := &models.Allergy{
+		ID:        ulid.Make().String(),
+		ProfileID: profile.ID,
+		CreatedAt: time.Now(),
+	}
+
+	err = applyAllergy(allergy, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.tables.allergy.Create(ctx, allergy)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := allergyResponse(allergy)
+	return &res, nil
+}
+
+func (service *MedicalService) UpdateAllergy(ctx context.Context, userID, profileID, id string, body *dto.RequestAllergy) (*dto.ResponseAllergy, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	allergy, err := service.tables.allergy.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) || (err == nil && allergy.ProfileID != profile.ID) {
+		return nil, ErrMedicalRecordNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	err = applyAllergy(allergy, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.tables.allergy.Update(ctx, allergy.ID, allergy)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := allergyResponse(allergy)
+	return &res, nil
+}
+
+func (service *MedicalService) DeleteAllergy(ctx context.Context, userID, profileID, id string) error {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return err
+	}
+
+	allergy, err := service.tables.allergy.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) || (err == nil && allergy.ProfileID != profile.ID) {
+		return ErrMedicalRecordNotFound
+	} else if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	err = service.tables.allergy.Delete(ctx, allergy.ID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+func (service *MedicalService) ListConditions(ctx context.Context, userID, profileID string) ([]dto.ResponseCondition, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, err := listAll(ctx, service.tables.condition, goqu.C("profile_id").Eq(profile.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Map(conditions, func(row *models.Condition, _ int) dto.ResponseCondition { return conditionResponse(row) }), nil
+}
+
+func (service *MedicalService) CreateCondition(ctx context.Context, userID, profileID string, body *dto.RequestCondition) (*dto.ResponseCondition, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	condition := &models.Condition{
+		ID:        ulid.Make().String(),
+		ProfileID: profile.ID,
+		CreatedAt: time.Now(),
+	}
+
+	err = applyCondition(condition, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.tables.condition.Create(ctx, condition)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := conditionResponse(condition)
+	return &res, nil
+}
+
+func (service *MedicalService) UpdateCondition(ctx context.Context, userID, profileID, id string, body *dto.RequestCondition) (*dto.ResponseCondition, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, err := service.tables.condition.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) || (err == nil && condition.ProfileID != profile.ID) {
+		return nil, ErrMedicalRecordNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	err = applyCondition(condition, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.tables.condition.Update(ctx, condition.ID, condition)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := conditionResponse(condition)
+	return &res, nil
+}
+
+func (service *MedicalService) DeleteCondition(ctx context.Context, userID, profileID, id string) error {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return err
+	}
+
+	condition, err := service.tables.condition.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) || (err == nil && condition.ProfileID != profile.ID) {
+		return ErrMedicalRecordNotFound
+	} else if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	err = service.tables.condition.Delete(ctx, condition.ID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+func (service *MedicalService) ListMedications(ctx context.Context, userID, profileID string) ([]dto.ResponseMedication, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	medications, err := listAll(ctx, service.tables.medication, goqu.C("profile_id").Eq(profile.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Map(medications, func(row *models.Medication, _ int) dto.ResponseMedication { return medicationResponse(row) }), nil
+}
+
+func (service *MedicalService) CreateMedication(ctx context.Context, userID, profileID string, body *dto.RequestMedication) (*dto.ResponseMedication, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	medication := &models.Medication{
+		ID:        ulid.Make().String(),
+		ProfileID: profile.ID,
+		CreatedAt: time.Now(),
+	}
+
+	err = applyMedication(medication, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.tables.medication.Create(ctx, medication)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := medicationResponse(medication)
+	return &res, nil
+}
+
+func (service *MedicalService) UpdateMedication(ctx context.Context, userID, profileID, id string, body *dto.RequestMedication) (*dto.ResponseMedication, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	medication, err := service.tables.medication.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) || (err == nil && medication.ProfileID != profile.ID) {
+		return nil, ErrMedicalRecordNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	err = applyMedication(medication, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.tables.medication.Update(ctx, medication.ID, medication)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	res := medicationResponse(medication)
+	return &res, nil
+}
+
+func (service *MedicalService) DeleteMedication(ctx context.Context, userID, profileID, id string) error {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return err
+	}
+
+	medication, err := service.tables.medication.Get(ctx, id)
+	if errors.Is(err, ErrNoResult) || (err == nil && medication.ProfileID != profile.ID) {
+		return ErrMedicalRecordNotFound
+	} else if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	err = service.tables.medication.Delete(ctx, medication.ID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// GetSummary returns the medical summary of the profile the request acts as.
+func (service *MedicalService) GetSummary(ctx context.Context, userID, profileID string) (*dto.ResponseMedicalSummary, error) {
+	profile, err := service.userService.ActingProfile(ctx, userID, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.summary(ctx, profile)
+}
+
+// GetPatientSummary returns the medical summary to clinic staff, only while
+// the patient has an appointment around now at a clinic of theirs.
+func (service *MedicalService) GetPatientSummary(ctx context.Context, staffID, profileID string) (*dto.ResponseMedicalSummary, error) {
+	profile, err := service.tables.profile.Get(ctx, profileID)
+	if errors.Is(err, ErrNoResult) {
+		return nil, ErrProfileNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	err = patientAccess(ctx, service.tables.event, service.tables.audit, staffID, profile, constants.AuditViewPatientSummary)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.summary(ctx, profile)
+}
+
+// patientAccess lets staff read the records of a patient with an appointment
+// around now at a location of a clinic the staff is assigned to. Every read
+// is written to the admin audit log.
+func patientAccess(ctx context.Context, tbEvent common.Repository[calendarModels.Event, string], tbAudit common.Repository[models.AdminAuditLog, string], staffID string, profile *models.Profile, action string) error {
+	clinics := goqu.From(repository.Tables.ClinicStaff).Select("clinic_id").Where(
+		goqu.C("user_id").Eq(staffID),
+		goqu.C("deleted_at").IsNull(),
+	)
+	locations := goqu.From(repository.Tables.Location).Select("id").Where(
+		goqu.C("clinic_id").In(clinics),
+		goqu.C("deleted_at").IsNull(),
+	)
+
+	now := time.Now()
+	events, err := tbEvent.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profile.ID),
+			goqu.C("location_id").In(locations),
+			goqu.C("type").Eq(constants.Appointment),
+			goqu.C("status").Neq(constants.Canceled),
+			goqu.C("start_time").Lte(now.Add(appointmentWindow)),
+			goqu.C("end_time").Gte(now.Add(-appointmentWindow)),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(events) == 0 {
+		return ErrNoAppointment
+	}
+
+	return writeAudit(ctx, tbAudit, staffID, action, profile.UserID, map[string]any{
+		"profile_id": profile.ID,
+		"event_id":   events[0].ID,
+	})
+}
+
+func (service *MedicalService) summary(ctx context.Context, profile *models.Profile) (*dto.ResponseMedicalSummary, error) {
+	now := time.Now()
+	allergies, err := listAll(ctx, service.tables.allergy, goqu.C("profile_id").Eq(profile.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, err := listAll(ctx, service.tables.condition,
+		goqu.C("profile_id").Eq(profile.ID),
+		goqu.C("status").Neq(constants.ConditionResolved),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	medications, err := listAll(ctx, service.tables.medication,
+		goqu.C("profile_id").Eq(profile.ID),
+		goqu.Or(goqu.C("ended_at").IsNull(), goqu.C("ended_at").Gt(now)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ResponseMedicalSummary{
+		ProfileID:   profile.ID,
+		MedicalID:   profile.MedicalID,
+		Name:        profile.Name,
+		Age:         profileAge(profile, now),
+		Sex:         deref(profile.Sex),
+		BloodType:   deref(profile.BloodType),
+		Allergies:   utils.Map(allergies, func(row *models.Allergy, _ int) dto.ResponseAllergy { return allergyResponse(row) }),
+		Conditions:  utils.Map(conditions, func(row *models.Condition, _ int) dto.ResponseCondition { return conditionResponse(row) }),
+		Medications: utils.Map(medications, func(row *models.Medication, _ int) dto.ResponseMedication { return medicationResponse(row) }),
+	}
+
+	if profile.Weight != nil {
+		res.Weight = *profile.Weight
+	}
+
+	if profile.Height != nil {
+		res.Height = *profile.Height
+	}
+
+	contacts, err := listContacts(ctx, service.tables.contact, profile.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res.EmergencyContacts = utils.Map(contacts, func(row *models.EmergencyContact, _ int) dto.ResponseEmergencyContact { return contactResponse(row) })
+
+	return &res, nil
+}
+
+func applyAllergy(allergy *models.Allergy, body *dto.RequestAllergy) error {
+	code := body.Code
+	if code != "" && body.Category == constants.AllergyDrug {
+		normalized, err := medcode.ATC(code)
+		if err != nil {
+			return err
+		}
+
+		code = normalized
+	}
+
+	allergy.Substance = body.Substance
+	allergy.Category = body.Category
+	allergy.Code = optional(code)
+	allergy.Reaction = optional(body.Reaction)
+	allergy.Severity = body.Severity
+	return nil
+}
+
+func applyCondition(condition *models.Condition, body *dto.RequestCondition) error {
+	code := body.Code
+	if code != "" {
+		normalized, err := medcode.ICD10(code)
+		if err != nil {
+			return err
+		}
+
+		code = normalized
+	}
+
+	condition.Name = body.Name
+	condition.Code = optional(code)
+	condition.Status = body.Status
+	condition.OnsetAt = body.OnsetAt
+	condition.Note = optional(body.Note)
+	return nil
+}
+
+func applyMedication(medication *models.Medication, body *dto.RequestMedication) error {
+	code := body.Code
+	if code != "" {
+		normalized, err := medcode.ATC(code)
+		if err != nil {
+			return err
+		}
+
+		code = normalized
+	}
+
+	medication.Name = body.Name
+	medication.Code = optional(code)
+	medication.Dosage = optional(body.Dosage)
+	medication.Frequency = optional(body.Frequency)
+	medication.StartedAt = body.StartedAt
+	medication.EndedAt = body.EndedAt
+	medication.Note = optional(body.Note)
+	return nil
+}
+
+func allergyResponse(row *models.Allergy) dto.ResponseAllergy {
+	return dto.ResponseAllergy{
+		ID:        row.ID,
+		Substance: row.Substance,
+		Category:  row.Category,
+		Code:      deref(row.Code),
+		Reaction:  deref(row.Reaction),
+		Severity:  row.Severity,
+	}
+}
+
+func conditionResponse(row *models.Condition) dto.ResponseCondition {
+	return dto.ResponseCondition{
+		ID:      row.ID,
+		Name:    row.Name,
+		Code:    deref(row.Code),
+		Status:  row.Status,
+		OnsetAt: row.OnsetAt,
+		Note:    deref(row.Note),
+	}
+}
+
+func medicationResponse(row *models.Medication) dto.ResponseMedication {
+	return dto.ResponseMedication{
+		ID:        row.ID,
+		Name:      row.Name,
+		Code:      deref(row.Code),
+		Dosage:    deref(row.Dosage),
+		Frequency: deref(row.Frequency),
+		StartedAt: row.StartedAt,
+		EndedAt:   row.EndedAt,
+		Note:      deref(row.Note),
+	}
+}
+
+// optional stores an empty value as NULL.
+func optional(s string) *string {
+	return utils.Ternary(s != "", &s, nil)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
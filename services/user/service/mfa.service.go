@@ -0,0 +1,481 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"monorepo/internal/config"
+	"monorepo/internal/constants"
+	"monorepo/internal/dto"
+	"monorepo/pkg/common"
+	"monorepo/pkg/utils"
+	"monorepo/services/user/models"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	mfaDefaultIssuer     = "Akasia365mc"
+	mfaSkew              = 1
+	mfaRecoveryCodeCount = 10
+	mfaChallengeTTL      = time.Minute * 5
+	mfaChallengeAttempts = 5
+)
+
+func NewMFAService(
+	tbUser common.Repository[models.User, string],
+	tbMFA common.Repository[models.MFA, string],
+	tbRecoveryCode common.Repository[models.RecoveryCode, string],
+	tbMFAChallenge common.Repository[models.MFAChallenge, string],
+	env *config.Environment,
+) *MFAService {
+	service := &MFAService{}
+	service.issuer = utils.Ternary(env.MFAIssuer != "", env.MFAIssuer, mfaDefaultIssuer)
+	service.requiredRoles = []string{constants.RoleStaff, constants.RoleAdmin}
+	if env.MFARequiredRoles != "" {
+		service.requiredRoles = strings.Split(env.MFARequiredRoles, ",")
+	}
+	service.tables.user = tbUser
+	service.tables.mfa = tbMFA
+	service.tables.recoveryCode = tbRecoveryCode
+	service.tables.mfaChallenge = tbMFAChallenge
+
+	return service
+}
+
+type MFAService struct {
+	issuer        string
+	requiredRoles []string
+	tables        struct {
+		user         common.Repository[models.User, string]
+		mfa          common.Repository[models.MFA, string]
+		recoveryCode common.Repository[models.RecoveryCode, string]
+		mfaChallenge common.Repository[models.MFAChallenge, string]
+	}
+}
+
+// IsRequired reports whether the role must have two-factor authentication enabled.
+func (service *MFAService) IsRequired(role string) bool {
+	return slices.Contains(service.requiredRoles, utils.Ternary(role != "", role, constants.RoleUser))
+}
+
+func (service *MFAService) getMFA(ctx context.Context, userID string) (*models.MFA, error) {
+	existing, err := service.tables.mfa.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(userID)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(existing) == 0 {
+		return nil, nil
+	}
+
+	return existing[0], nil
+}
+
+func (service *MFAService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	mfa, err := service.getMFA(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return mfa != nil && mfa.EnabledAt.Valid, nil
+}
+
+func (service *MFAService) GetStatus(ctx context.Context, userID string) (*dto.ResponseMFAStatus, error) {
+	user, err := service.tables.user.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	enabled, err := service.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := service.tables.recoveryCode.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("user_id").Eq(userID),
+			goqu.C("used_at").IsNull(),
+		},
+		Page:  1,
+		Limit: mfaRecoveryCodeCount,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	return &dto.ResponseMFAStatus{
+		Enabled:                enabled,
+		Required:               service.IsRequired(user.Role),
+		RecoveryCodesRemaining: len(codes),
+	}, nil
+}
+
+// Enroll creates a pending TOTP secret. It only becomes active once a code
+// generated from it is confirmed through Activate.
+func (service *MFAService) Enroll(ctx context.Context, userID string) (*dto.ResponseMFAEnroll, error) {
+	user, err := service.tables.user.Get(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	existing, err := service.getMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil && existing.EnabledAt.Valid {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	// replace previous pending enrollment
+	if existing != nil {
+		err = service.tables.mfa.Delete(ctx, existing.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.tables.mfa.Create(ctx, &models.MFA{
+		ID:        ulid.Make().String(),
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return &dto.ResponseMFAEnroll{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(service.issuer, user.Handle, secret),
+	}, nil
+}
+
+// Activate confirms a pending enrollment and returns a fresh set of recovery codes.
+func (service *MFAService) Activate(ctx context.Context, userID string, body *dto.RequestMFACode) (*dto.ResponseMFARecoveryCodes, error) {
+	mfa, err := service.getMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if mfa == nil {
+		return nil, ErrMFANotEnrolled
+	}
+
+	if mfa.EnabledAt.Valid {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := utils.ValidateTOTP(mfa.Secret, body.Code, time.Now(), mfaSkew)
+	if !ok {
+		return nil, ErrMFAInvalidCode
+	}
+
+	err = service.tables.mfa.Update(ctx, mfa.ID, &models.MFA{
+		LastUsedStep: step,
+		EnabledAt:    sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return service.generateRecoveryCodes(ctx, userID)
+}
+
+// RegenerateRecoveryCodes invalidates all remaining recovery codes and issues new ones.
+func (service *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID string, body *dto.RequestMFACode) (*dto.ResponseMFARecoveryCodes, error) {
+	err := service.VerifyCode(ctx, userID, body.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.generateRecoveryCodes(ctx, userID)
+}
+
+// Disable turns off two-factor authentication. Users whose role requires it can't disable it.
+func (service *MFAService) Disable(ctx context.Context, userID string, body *dto.RequestMFACode) error {
+	user, err := service.tables.user.Get(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if service.IsRequired(user.Role) {
+		return ErrMFARequired
+	}
+
+	err = service.VerifyCode(ctx, userID, body.Code)
+	if err != nil {
+		return err
+	}
+
+	mfa, err := service.getMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = service.tables.mfa.Delete(ctx, mfa.ID)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return service.deleteRecoveryCodes(ctx, userID)
+}
+
+// VerifyCode accepts either a TOTP code or an unused recovery code. Each TOTP
+// step and each recovery code can only be used once.
+func (service *MFAService) VerifyCode(ctx context.Context, userID, code string) error {
+	mfa, err := service.getMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if mfa == nil || !mfa.EnabledAt.Valid {
+		return ErrMFANotEnrolled
+	}
+
+	step, ok := utils.ValidateTOTP(mfa.Secret, code, time.Now(), mfaSkew)
+	if ok {
+		if step <= mfa.LastUsedStep {
+			return ErrMFAInvalidCode
+		}
+
+		err = service.tables.mfa.Update(ctx, mfa.ID, &models.MFA{LastUsedStep: step})
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+
+		return nil
+	}
+
+	return service.useRecoveryCode(ctx, userID, code)
+}
+
+// CreateLoginChallenge checks the password of a password-grant login. It returns
+// a challenge when the user has two-factor authentication enabled, or nil when
+// the login can proceed in a single step.
+func (service *MFAService) CreateLoginChallenge(ctx context.Context, handle, password string) (*dto.ResponseMFAChallenge, error) {
+	user, err := service.getUserByHandle(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	return service.createChallenge(ctx, user.ID)
+}
+
+// CreateChallenge starts the second login step for a user whose first factor
+// has already been verified elsewhere, e.g. by firebase. It returns nil when
+// two-factor authentication is off.
+func (service *MFAService) CreateChallenge(ctx context.Context, handle string) (*dto.ResponseMFAChallenge, error) {
+	user, err := service.getUserByHandle(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.createChallenge(ctx, user.ID)
+}
+
+func (service *MFAService) getUserByHandle(ctx context.Context, handle string) (*models.User, error) {
+	users, err := service.tables.user.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("handle").Eq(handle)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return users[0], nil
+}
+
+func (service *MFAService) createChallenge(ctx context.Context, userID string) (*dto.ResponseMFAChallenge, error) {
+	enabled, err := service.IsEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	token, err := utils.RandSecureAlphanumericString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.tables.mfaChallenge.Create(ctx, &models.MFAChallenge{
+		ID:             ulid.Make().String(),
+		UserID:         userID,
+		ChallengeToken: token,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return &dto.ResponseMFAChallenge{
+		MFARequired: true,
+		MFAToken:    token,
+		ExpiresIn:   int64(mfaChallengeTTL / time.Second),
+	}, nil
+}
+
+// CompleteChallenge verifies the second factor of a login and returns the handle
+// to issue tokens for.
+func (service *MFAService) CompleteChallenge(ctx context.Context, body *dto.RequestMFALogin) (string, error) {
+	challenges, err := service.tables.mfaChallenge.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("challenge_token").Eq(body.MFAToken),
+			goqu.C("is_used").Eq(false),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(challenges) == 0 {
+		return "", ErrMFAChallengeInvalid
+	}
+
+	challenge := challenges[0]
+	if time.Now().After(challenge.CreatedAt.Add(mfaChallengeTTL)) || challenge.Attempts >= mfaChallengeAttempts {
+		return "", ErrMFAChallengeInvalid
+	}
+
+	err = service.VerifyCode(ctx, challenge.UserID, body.Code)
+	if err != nil {
+		challenge.Attempts++
+		if uerr := service.tables.mfaChallenge.Update(ctx, challenge.ID, &models.MFAChallenge{Attempts: challenge.Attempts}); uerr != nil {
+			return "", fmt.Errorf("%w; %w", ErrRepositoryMutateFail, uerr)
+		}
+
+		return "", err
+	}
+
+	challenge.IsUsed = true
+	err = service.tables.mfaChallenge.Update(ctx, challenge.ID, challenge)
+	if err != nil {
+		return "", fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	user, err := service.tables.user.Get(ctx, challenge.UserID)
+	if err != nil {
+		return "", fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	return user.Handle, nil
+}
+
+func (service *MFAService) generateRecoveryCodes(ctx context.Context, userID string) (*dto.ResponseMFARecoveryCodes, error) {
+	err := service.deleteRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ResponseMFARecoveryCodes{}
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		code, err := utils.RandSecureAlphanumericString(10)
+		if err != nil {
+			return nil, err
+		}
+		code = strings.ToLower(code[:5] + "-" + code[5:])
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrPasswordHashingFailed, err)
+		}
+
+		err = service.tables.recoveryCode.Create(ctx, &models.RecoveryCode{
+			ID:        ulid.Make().String(),
+			UserID:    userID,
+			CodeHash:  string(hash),
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+
+		res.RecoveryCodes = append(res.RecoveryCodes, code)
+	}
+
+	return &res, nil
+}
+
+func (service *MFAService) useRecoveryCode(ctx context.Context, userID, code string) error {
+	codes, err := service.tables.recoveryCode.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{
+			goqu.C("user_id").Eq(userID),
+			goqu.C("used_at").IsNull(),
+		},
+		Page:  1,
+		Limit: mfaRecoveryCodeCount,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	code = strings.ToLower(strings.TrimSpace(code))
+	for _, rc := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(rc.CodeHash), []byte(code)) != nil {
+			continue
+		}
+
+		err = service.tables.recoveryCode.Update(ctx, rc.ID, &models.RecoveryCode{
+			UsedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+
+		return nil
+	}
+
+	return ErrMFAInvalidCode
+}
+
+func (service *MFAService) deleteRecoveryCodes(ctx context.Context, userID string) error {
+	codes, err := service.tables.recoveryCode.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("user_id").Eq(userID)},
+		Page:   1,
+		Limit:  mfaRecoveryCodeCount * 2,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	for _, rc := range codes {
+		err = service.tables.recoveryCode.Delete(ctx, rc.ID)
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"monorepo/internal/config"
+	"monorepo/internal/constants"
+	"monorepo/internal/mail"
+	"monorepo/internal/repository"
+	"monorepo/pkg/common"
+	"monorepo/pkg/utils"
+	"monorepo/services/user/models"
+	"sync"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	outboxBatchSize   = 20
+	outboxLease       = time.Minute * 5
+	outboxBaseBackoff = time.Second * 30
+	outboxMaxBackoff  = time.Hour
+
+	outboxDefaultWorkers     = 4
+	outboxDefaultMaxAttempts = 8
+)
+
+func NewOutboxService(
+	tbOutbox common.Repository[models.EmailOutbox, string],
+	transport mail.Transport,
+	env *config.Environment,
+) *OutboxService {
+	service := &OutboxService{}
+	service.transport = transport
+	service.workers = utils.Ternary(env.MailWorkers > 0, env.MailWorkers, outboxDefaultWorkers)
+	service.maxAttempts = utils.Ternary(env.MailMaxAttempts > 0, env.MailMaxAttempts, outboxDefaultMaxAttempts)
+	service.tables.outbox = tbOutbox
+
+	return service
+}
+
+// OutboxService persists outgoing emails and delivers them in the background,
+// so a failing smtp server delays a message instead of losing it.
+type OutboxService struct {
+	transport   mail.Transport
+	workers     int
+	maxAttempts int
+	tables      struct {
+		outbox common.Repository[models.EmailOutbox, string]
+	}
+}
+
+// Enqueue stores a rendered message, it is sent by the next worker run.
+func (service *OutboxService) Enqueue(ctx context.Context, msg *mail.Message) error {
+	now := time.Now()
+	err := service.tables.outbox.Create(ctx, &models.EmailOutbox{
+		ID:            ulid.Make().String(),
+		Sender:        msg.From,
+		Recipient:     msg.To,
+		Subject:       msg.Subject,
+		Body:          msg.HTML,
+		TextBody:      msg.Text,
+		Status:        constants.OutboxPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// Run polls due messages every interval and hands them to a pool of workers
+// until the context is done.
+func (service *OutboxService) Run(ctx context.Context, interval time.Duration) {
+	jobs := make(chan *models.EmailOutbox)
+
+	var wg sync.WaitGroup
+	for i := 0; i < service.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				service.deliver(ctx, job)
+			}
+		}()
+	}
+	defer wg.Wait()
+	defer close(jobs)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			due, err := service.claimDue(ctx)
+			if err != nil {
+				logrus.Errorf("failed to claim outgoing emails; err: %s", err)
+			}
+
+			for _, job := range due {
+				select {
+				case jobs <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// claimDue picks messages that are due. A row is claimed by bumping its attempts
+// only if nobody else did, which keeps several instances from sending it twice.
+// Claimed rows are leased, a worker that dies mid-send releases them once the lease ends.
+func (service *OutboxService) claimDue(ctx context.Context) ([]*models.EmailOutbox, error) {
+	now := time.Now()
+	due, err := service.tables.outbox.List(ctx, &common.FilterOptions{
+		Sort: []exp.OrderedExpression{goqu.I("next_attempt_at").Asc()},
+		Filter: []exp.Expression{
+			goqu.C("status").In(constants.OutboxPending, constants.OutboxSending),
+			goqu.C("next_attempt_at").Lte(now),
+		},
+		Page:  1,
+		Limit: outboxBatchSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	claimed := make([]*models.EmailOutbox, 0, len(due))
+	for _, msg := range due {
+		stmt, args, err := goqu.Dialect("postgres").Update(repository.Tables.EmailOutbox).
+			Set(goqu.Record{
+				"status":          constants.OutboxSending,
+				"attempts":        msg.Attempts + 1,
+				"next_attempt_at": now.Add(outboxLease),
+			}).
+			Where(goqu.C("id").Eq(msg.ID), goqu.C("attempts").Eq(msg.Attempts)).
+			ToSQL()
+		if err != nil {
+			return claimed, err
+		}
+
+		res, err := service.tables.outbox.Raw(ctx, stmt, args...)
+		if err != nil {
+			return claimed, err
+		}
+
+		if n, _ := res.RowsAffected(); n == 1 {
+			msg.Attempts++
+			claimed = append(claimed, msg)
+		}
+	}
+
+	return claimed, nil
+}
+
+// deliver sends a claimed message and records the outcome. Failures are retried
+// with exponential backoff and dead-lettered after maxAttempts.
+func (service *OutboxService) deliver(ctx context.Context, msg *models.EmailOutbox) {
+	update := models.EmailOutbox{
+		Status: constants.OutboxSent,
+		SentAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	err := service.transport.Send(ctx, &mail.Message{
+		From:    msg.Sender,
+		To:      msg.Recipient,
+		Subject: msg.Subject,
+		HTML:    msg.Body,
+		Text:    msg.TextBody,
+	})
+	if err != nil {
+		update = models.EmailOutbox{
+			Status:        constants.OutboxPending,
+			NextAttemptAt: time.Now().Add(outboxBackoff(msg.Attempts)),
+			LastError:     err.Error(),
+		}
+
+		if msg.Attempts >= service.maxAttempts {
+			update.Status = constants.OutboxDead
+			logrus.Errorf("email dead-lettered: %s; attempts: %d; err: %s", msg.ID, msg.Attempts, err)
+		}
+	}
+
+	err = service.tables.outbox.Update(ctx, msg.ID, &update)
+	if err != nil {
+		logrus.Errorf("failed to record email delivery: %s; err: %s", msg.ID, err)
+	}
+}
+
+// outboxBackoff doubles the delay after every attempt, capped at outboxMaxBackoff.
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxBaseBackoff
+	for i := 1; i < attempts && delay < outboxMaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, outboxMaxBackoff)
+}
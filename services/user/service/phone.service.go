@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"monorepo/internal/dto"
+	"monorepo/internal/mail"
+	"monorepo/internal/repository"
+	"monorepo/internal/sms"
+	"monorepo/pkg/common"
+	"monorepo/services/user/models"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/oklog/ulid/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	phoneOTPLength      = 6
+	phoneOTPTTL         = time.Minute * 5
+	phoneOTPMaxAttempts = 5
+	phoneOTPCooldown    = time.Minute
+	phoneOTPHourly      = 5
+)
+
+var phoneOTPMessages = map[string]string{
+	mail.LocaleID: "Kode verifikasi Akasia365mc Anda: %s. Berlaku 5 menit, jangan berikan kode ini kepada siapa pun.",
+	mail.LocaleEN: "Your Akasia365mc verification code is %s. It expires in 5 minutes, do not share it with anyone.",
+}
+
+func NewPhoneService(
+	tbProfile common.Repository[models.Profile, string],
+	tbPhoneVerification common.Repository[models.PhoneVerification, string],
+	sender sms.Sender,
+) *PhoneService {
+	service := &PhoneService{}
+	service.sender = sender
+	service.tables.profile = tbProfile
+	service.tables.phoneVerification = tbPhoneVerification
+
+	return service
+}
+
+// PhoneService verifies profile phone numbers with a one-time code sent by sms.
+// A profile phone is verified while it equals Profile.VerifiedPhone, changing the
+// number therefore requires a new verification.
+type PhoneService struct {
+	sender sms.Sender
+	tables struct {
+		profile           common.Repository[models.Profile, string]
+		phoneVerification common.Repository[models.PhoneVerification, string]
+	}
+}
+
+func (service *PhoneService) getProfile(ctx context.Context, userID string) (*models.Profile, error) {
+	profile, err := service.tables.profile.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("id").Desc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(userID)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(profile) == 0 {
+		return nil, errors.New("profile not found")
+	}
+
+	return profile[0], nil
+}
+
+func isPhoneVerified(profile *models.Profile) bool {
+	return profile.VerifiedPhone != nil && *profile.VerifiedPhone == profile.Phone
+}
+
+// SendOTP texts a verification code to the profile phone. Sends are throttled to
+// one per phoneOTPCooldown and phoneOTPHourly per hour.
+func (service *PhoneService) SendOTP(ctx context.Context, userID string) (*dto.ResponsePhoneOTP, error) {
+	profile, err := service.getProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isPhoneVerified(profile) {
+		return nil, ErrPhoneAlreadyVerified
+	}
+
+	now := time.Now()
+	recent, err := service.tables.phoneVerification.List(ctx, &common.FilterOptions{
+		Sort: []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profile.ID),
+			goqu.C("created_at").Gt(now.Add(-time.Hour)),
+		},
+		Page:  1,
+		Limit: phoneOTPHourly,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(recent) >= phoneOTPHourly ||
+		(len(recent) > 0 && now.Before(recent[0].CreatedAt.Add(phoneOTPCooldown))) {
+		return nil, ErrTooManyRequests
+	}
+
+	code, err := randomDigits(phoneOTPLength)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrPasswordHashingFailed, err)
+	}
+
+	pv := &models.PhoneVerification{
+		ID:        ulid.Make().String(),
+		ProfileID: profile.ID,
+		Phone:     profile.Phone,
+		CodeHash:  string(hash),
+		ExpiresAt: now.Add(phoneOTPTTL),
+		CreatedAt: now,
+	}
+	err = service.tables.phoneVerification.Create(ctx, pv)
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	message := phoneOTPMessages[mail.NormalizeLocale(profileLocale(profile))]
+	err = service.sender.Send(ctx, profile.Phone, fmt.Sprintf(message, code))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ResponsePhoneOTP{Phone: profile.Phone, ExpiresAt: pv.ExpiresAt}, nil
+}
+
+// VerifyOTP checks the code of the latest verification of the current phone,
+// each verification allows phoneOTPMaxAttempts guesses.
+func (service *PhoneService) VerifyOTP(ctx context.Context, userID string, body *dto.RequestVerifyPhone) error {
+	profile, err := service.getProfile(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if isPhoneVerified(profile) {
+		return ErrPhoneAlreadyVerified
+	}
+
+	latest, err := service.tables.phoneVerification.List(ctx, &common.FilterOptions{
+		Sort: []exp.OrderedExpression{goqu.I("created_at").Desc()},
+		Filter: []exp.Expression{
+			goqu.C("profile_id").Eq(profile.ID),
+			goqu.C("phone").Eq(profile.Phone),
+			goqu.C("verified_at").IsNull(),
+		},
+		Page:  1,
+		Limit: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(latest) == 0 {
+		return ErrOTPInvalid
+	}
+
+	pv := latest[0]
+	if time.Now().After(pv.ExpiresAt) || pv.Attempts >= phoneOTPMaxAttempts {
+		return ErrOTPInvalid
+	}
+
+	// count the guess before checking it, the increment only takes while under
+	// the limit so parallel requests can not exceed it
+	stmt, args, err := goqu.Dialect("postgres").Update(repository.Tables.PhoneVerify).
+		Set(goqu.Record{"attempts": goqu.L("attempts + 1")}).
+		Where(goqu.C("id").Eq(pv.ID), goqu.C("attempts").Lt(phoneOTPMaxAttempts)).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+
+	res, err := service.tables.phoneVerification.Raw(ctx, stmt, args...)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	if counted, err := res.RowsAffected(); err != nil {
+		return err
+	} else if counted == 0 {
+		return ErrOTPInvalid
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(pv.CodeHash), []byte(body.Code)) != nil {
+		return ErrOTPInvalid
+	}
+
+	err = service.tables.phoneVerification.Update(ctx, pv.ID, &models.PhoneVerification{
+		VerifiedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	err = service.tables.profile.Update(ctx, profile.ID, &models.Profile{VerifiedPhone: &pv.Phone})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// VerifiedPhone returns the phone of a profile only once it is verified, reminders
+// must not be sent to unverified numbers.
+func (service *PhoneService) VerifiedPhone(ctx context.Context, profileID string) (string, error) {
+	profile, err := service.tables.profile.Get(ctx, profileID)
+	if err != nil {
+		return "", fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if !isPhoneVerified(profile) {
+		return "", ErrPhoneNotVerified
+	}
+
+	return profile.Phone, nil
+}
+
+func randomDigits(n int) (string, error) {
+	b := make([]byte, n)
+	for i := range b {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		b[i] = byte('0' + d.Int64())
+	}
+
+	return string(b), nil
+}
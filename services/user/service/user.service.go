@@ -1,20 +1,24 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
-	"monorepo/internal/config"
 	"monorepo/internal/dto"
+	"monorepo/internal/repository"
+	"monorepo/internal/storage"
 	"monorepo/pkg/common"
+	"monorepo/pkg/imaging"
+	"monorepo/pkg/nik"
+	"monorepo/pkg/phone"
+	"monorepo/pkg/units"
 	"monorepo/pkg/utils"
 	"monorepo/services/user/models"
-	"net/url"
-	"os"
+	"strconv"
 	"time"
 
 	"firebase.google.com/go/v4/auth"
@@ -22,29 +26,56 @@ import (
 	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/go-playground/validator/v10"
 	"github.com/oklog/ulid/v2"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	photoMaxBytes = 5 << 20
+	photoLinkTTL  = time.Hour
+)
+
+var photoVariants = []struct {
+	name string
+	size int
+}{
+	{name: "lg", size: 1024},
+	{name: "md", size: 512},
+	{name: "sm", size: 128},
+}
+
 func NewUserService(
 	tbUser common.Repository[models.User, string],
 	tbProfile common.Repository[models.Profile, string],
+	tbSession common.Repository[models.Session, string],
+	tbGuardian common.Repository[models.ProfileGuardian, string],
+	tbHandover common.Repository[models.ProfileHandover, string],
 	fbaClient *auth.Client,
+	store storage.Storage,
 ) *UserService {
 	service := &UserService{}
 	service.fbaClient = fbaClient
+	service.storage = store
 	service.validate = validator.New()
 	service.tables.user = tbUser
 	service.tables.profile = tbProfile
+	service.tables.session = tbSession
+	service.tables.guardian = tbGuardian
+	service.tables.handover = tbHandover
 
 	return service
 }
 
 type UserService struct {
 	fbaClient *auth.Client
+	storage   storage.Storage
 	validate  *validator.Validate
 	tables    struct {
-		user    common.Repository[models.User, string]
-		profile common.Repository[models.Profile, string]
+		user     common.Repository[models.User, string]
+		profile  common.Repository[models.Profile, string]
+		session  common.Repository[models.Session, string]
+		guardian common.Repository[models.ProfileGuardian, string]
+		handover common.Repository[models.ProfileHandover, string]
 	}
 }
 
@@ -87,11 +118,12 @@ func (service *UserService) RegisterUser(ctx context.Context, body *dto.RequestR
 	}
 
 	newUser := &models.User{
-		ID:        ulid.Make().String(),
-		Provider:  body.Provider,
-		Handle:    body.Email,
-		Password:  string(hashedPassword),
-		CreatedAt: time.Now(),
+		ID:            ulid.Make().String(),
+		Provider:      body.Provider,
+		Handle:        body.Email,
+		Password:      string(hashedPassword),
+		EmailVerified: body.EmailVerified,
+		CreatedAt:     time.Now(),
 	}
 	err = service.tables.user.Create(ctx, newUser)
 	if err != nil {
@@ -101,9 +133,221 @@ func (service *UserService) RegisterUser(ctx context.Context, body *dto.RequestR
 	return newUser, nil
 }
 
-func (service *UserService) ChangePassword(ctx context.Context) error { return nil }
-func (service *UserService) ResetPassword(ctx context.Context) error  { return nil }
-func (service *UserService) UpdateUser(ctx context.Context) error     { return nil }
+func (service *UserService) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := service.tables.user.List(ctx, &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("id").Eq(userID)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(user) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return user[0], nil
+}
+
+// ChangePassword verifies the current password, stores the new one locally and
+// in firebase, then revokes every session except the one making the request.
+func (service *UserService) ChangePassword(ctx context.Context, userID, sessionID string, body *dto.RequestChangePassword) error {
+	user, err := service.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Provider != "email" {
+		return ErrPasswordNotManaged
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.CurrentPassword))
+	if err != nil {
+		return ErrInvalidPassword
+	}
+
+	err = service.setPassword(ctx, user, body.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	return service.RevokeSessions(ctx, user.ID, sessionID)
+}
+
+// ResetPassword sets a new password for a user who proved ownership through a
+// reset token, and revokes all of their sessions.
+func (service *UserService) ResetPassword(ctx context.Context, userID, password string) error {
+	user, err := service.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = service.setPassword(ctx, user, password)
+	if err != nil {
+		return err
+	}
+
+	return service.RevokeSessions(ctx, user.ID, "")
+}
+
+// UpdateUser changes the login email (handle) once the new address has been verified.
+func (service *UserService) UpdateUser(ctx context.Context, userID string, body *dto.RequestUpdateUser) error {
+	user, err := service.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	exists, err := service.IsHandleExists(ctx, body.Email)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrHandleExist
+	}
+
+	// get user by email
+	u, err := service.fbaClient.GetUserByEmail(ctx, user.Handle)
+	if err != nil {
+		return err
+	}
+
+	// the handle changes first, firebase failing puts it back so sign-in and
+	// handle never disagree
+	err = service.tables.user.Update(ctx, user.ID, &models.User{Handle: body.Email, EmailVerified: true})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	// update firebase email
+	params := (&auth.UserToUpdate{}).
+		Email(body.Email).
+		EmailVerified(true)
+	_, err = service.fbaClient.UpdateUser(ctx, u.UID, params)
+	if err != nil {
+		return errors.Join(err, service.restoreHandle(ctx, user))
+	}
+
+	// issued tokens carry the old handle as credential
+	return service.RevokeSessions(ctx, user.ID, "")
+}
+
+// restoreHandle puts back the handle and verification of user. Zero values are
+// left out of updates, so it needs its own statement.
+func (service *UserService) restoreHandle(ctx context.Context, user *models.User) error {
+	stmt, args, err := goqu.Dialect("postgres").Update(repository.Tables.User).
+		Set(goqu.Record{"handle": user.Handle, "email_verified": user.EmailVerified}).
+		Where(goqu.C("id").Eq(user.ID)).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+
+	if _, err := service.tables.user.Raw(ctx, stmt, args...); err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// VerifyEmail marks the user's email as verified locally and in firebase.
+func (service *UserService) VerifyEmail(ctx context.Context, userID string) error {
+	user, err := service.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// get user by email
+	u, err := service.fbaClient.GetUserByEmail(ctx, user.Handle)
+	if err != nil {
+		return err
+	}
+
+	// update firebase
+	params := (&auth.UserToUpdate{}).
+		EmailVerified(true)
+	_, err = service.fbaClient.UpdateUser(ctx, u.UID, params)
+	if err != nil {
+		return err
+	}
+
+	err = service.tables.user.Update(ctx, user.ID, &models.User{EmailVerified: true})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// RevokeSessions revokes all active sessions of a user, keeping exceptSessionID alive if given.
+func (service *UserService) RevokeSessions(ctx context.Context, userID, exceptSessionID string) error {
+	filter := []exp.Expression{
+		goqu.C("user_id").Eq(userID),
+		goqu.C("revoked_at").IsNull(),
+	}
+	if exceptSessionID != "" {
+		filter = append(filter, goqu.C("id").Neq(exceptSessionID))
+	}
+
+	now := time.Now()
+	for {
+		sessions, err := service.tables.session.List(ctx, &common.FilterOptions{
+			Filter: filter,
+			Page:   1,
+			Limit:  100,
+		})
+		if err != nil {
+			return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+		}
+
+		if len(sessions) == 0 {
+			return nil
+		}
+
+		for _, session := range sessions {
+			err = service.tables.session.Update(ctx, session.ID, &models.Session{
+				RevokedAt: sql.NullTime{Time: now, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+			}
+		}
+	}
+}
+
+func (service *UserService) setPassword(ctx context.Context, user *models.User, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrPasswordHashingFailed, err)
+	}
+
+	// get user by email
+	u, err := service.fbaClient.GetUserByEmail(ctx, user.Handle)
+	if err != nil {
+		return err
+	}
+
+	// update firebase
+	params := (&auth.UserToUpdate{}).
+		Password(password)
+	_, err = service.fbaClient.UpdateUser(ctx, u.UID, params)
+	if err != nil {
+		return err
+	}
+
+	err = service.fbaClient.RevokeRefreshTokens(ctx, u.UID)
+	if err != nil {
+		return err
+	}
+
+	err = service.tables.user.Update(ctx, user.ID, &models.User{Password: string(hashedPassword)})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
 
 func (service *UserService) CreateProfile(ctx context.Context, body *dto.RequestCreateProfile) (*dto.ResponseCreateProfile, error) {
 	user, err := service.tables.user.List(ctx, &common.FilterOptions{
@@ -136,17 +380,34 @@ func (service *UserService) CreateProfile(ctx context.Context, body *dto.Request
 		return nil, err
 	}
 
+	number, err := phone.Parse(body.CountryCode, body.Phone)
+	if err != nil {
+		return nil, err
+	}
+
 	newProfile := &models.Profile{
 		ID:          ulid.Make().String(),
 		UserID:      body.UserID,
 		MedicalID:   ulid.Make().String(),
 		Name:        body.Name,
-		CountryCode: body.CountryCode,
-		Phone:       body.Phone,
+		CountryCode: number.CallingCode,
+		Phone:       number.E164(),
 		NIK:         &body.NIK,
 		PhotoUrl:    &body.PhotoUrl,
 		CreatedAt:   time.Now(),
 	}
+
+	// prefill what the nik tells about the holder
+	if body.NIK != "" {
+		decoded, err := nik.Decode(body.NIK, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		newProfile.DOB = &decoded.DOB
+		newProfile.Sex = &decoded.Sex
+		newProfile.RegionCode = &decoded.DistrictCode
+	}
 	err = service.tables.profile.Create(ctx, newProfile)
 	if err != nil {
 		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
@@ -166,24 +427,15 @@ func (service *UserService) CreateProfile(ctx context.Context, body *dto.Request
 	return &res, nil
 }
 
-func (service *UserService) GetProfile(ctx context.Context, body *dto.FirebaseClaims) (any, error) {
-	profile, err := service.tables.profile.List(ctx, &common.FilterOptions{
-		Sort:   []exp.OrderedExpression{goqu.I("id").Desc()},
-		Filter: []exp.Expression{goqu.C("user_id").Eq(body.UserID)},
-		Page:   1,
-		Limit:  1,
-	})
+// GetProfile returns the profile the request acts as, see actingProfile.
+func (service *UserService) GetProfile(ctx context.Context, body *dto.FirebaseClaims, profileID string) (any, error) {
+	profile, guardian, err := service.actingProfile(ctx, body.UserID, profileID)
 	if err != nil {
-		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
-	}
-
-	if len(profile) == 0 {
-		err = errors.New("profile not found")
 		return nil, err
 	}
 
 	res := dto.ResponseGetProfile{}
-	p, err := json.Marshal(profile[0])
+	p, err := json.Marshal(profile)
 	if err != nil {
 		return nil, err
 	}
@@ -194,26 +446,48 @@ func (service *UserService) GetProfile(ctx context.Context, body *dto.FirebaseCl
 	}
 
 	res.Role = body.Role
+	res.Units = utils.Ternary(res.Units != "", res.Units, units.Metric)
+	res.Age = profileAge(profile, time.Now())
+	res.Province = nik.Province(res.RegionCode)
+	res.PhoneVerified = profile.VerifiedPhone != nil && *profile.VerifiedPhone == profile.Phone
+
+	if guardian != nil {
+		res.GuardianID = guardian.UserID
+		res.Relationship = guardian.Relationship
+	}
+
+	if profile.PhotoKey != nil && *profile.PhotoKey != "" {
+		photo, err := service.photoURLs(ctx, *profile.PhotoKey)
+		if err != nil {
+			return nil, err
+		}
+
+		res.PhotoUrl = photo.PhotoUrl
+		res.Photos = photo.Photos
+	}
 
 	return &res, nil
 }
 
-func (service *UserService) UpdateProfile(ctx context.Context, userId string, body *dto.RequestUpdateProfile) (any, error) {
+// UpdateProfile changes the profile userId acts as, see actingProfile.
+// accountID, the account named in the path, has to be userId itself: acting
+// for a dependent is authorized by the guardian's token, never by the path.
+func (service *UserService) UpdateProfile(ctx context.Context, userId, accountID, profileID string, body *dto.RequestUpdateProfile) (any, error) {
 	updateProfile := models.Profile{}
 
+	if accountID != userId {
+		return nil, ErrProfileNotFound
+	}
+
 	// get profile
-	profile, err := service.tables.profile.List(ctx, &common.FilterOptions{
-		Sort:   []exp.OrderedExpression{goqu.I("id").Desc()},
-		Filter: []exp.Expression{goqu.C("user_id").Eq(userId)},
-		Page:   1,
-		Limit:  1,
-	})
+	profile, guardian, err := service.actingProfile(ctx, userId, profileID)
 	if err != nil {
-		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+		return nil, err
 	}
 
-	if len(profile) == 0 {
-		err = errors.New("profile not found")
+	// weights and heights are kept in kg and cm whatever the units shown
+	err = body.ToMetric(deref(profile.Units))
+	if err != nil {
 		return nil, err
 	}
 
@@ -229,10 +503,30 @@ func (service *UserService) UpdateProfile(ctx context.Context, userId string, bo
 	}
 
 	if updateProfile.DOB.IsZero() {
-		updateProfile.DOB = profile[0].DOB
+		updateProfile.DOB = profile.DOB
+	}
+
+	err = applyNIK(&updateProfile, profile, body)
+	if err != nil {
+		return nil, err
 	}
 
-	if updateProfile.PhotoUrl != nil {
+	// a new phone number has to be verified again, see PhoneService
+	if body.Phone != "" {
+		number, err := phone.Parse(utils.Ternary(body.CountryCode != "", body.CountryCode, profile.CountryCode), body.Phone)
+		if err != nil {
+			return nil, err
+		}
+
+		updateProfile.CountryCode = number.CallingCode
+		updateProfile.Phone = number.E164()
+	} else {
+		// the country code alone is meaningless without the number
+		updateProfile.CountryCode = ""
+	}
+
+	// a dependent has no firebase user of its own
+	if updateProfile.PhotoUrl != nil && guardian == nil {
 		user, err := service.tables.user.List(ctx, &common.FilterOptions{
 			Sort:   []exp.OrderedExpression{goqu.I("id").Desc()},
 			Filter: []exp.Expression{goqu.C("id").Eq(userId)},
@@ -258,23 +552,34 @@ func (service *UserService) UpdateProfile(ctx context.Context, userId string, bo
 		}
 	}
 
-	err = service.tables.profile.Update(ctx, profile[0].ID, &updateProfile)
+	err = service.tables.profile.Update(ctx, profile.ID, &updateProfile)
 	if err != nil {
 		return nil, err
 	}
 
 	// get updated profile
-	updatedProfile, err := service.tables.profile.List(ctx, &common.FilterOptions{
-		Sort:   []exp.OrderedExpression{goqu.I("id").Desc()},
-		Filter: []exp.Expression{goqu.C("user_id").Eq(userId)},
-		Page:   1,
-		Limit:  1,
-	})
+	updatedProfile, err := service.tables.profile.Get(ctx, profile.ID)
 	if err != nil {
 		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
 	}
 
-	return updatedProfile[0], nil
+	profileInUnits(updatedProfile)
+	return updatedProfile, nil
+}
+
+// profileInUnits shows the weight and height of a profile, kept in kg and cm,
+// in the units it prefers.
+func profileInUnits(profile *models.Profile) {
+	system := deref(profile.Units)
+	if profile.Weight != nil {
+		weight := units.FromKg(*profile.Weight, units.WeightUnit(system))
+		profile.Weight = &weight
+	}
+
+	if profile.Height != nil {
+		height := units.FromCm(*profile.Height, units.HeightUnit(system))
+		profile.Height = &height
+	}
 }
 
 func (service *UserService) DeleteProfile(ctx context.Context, userId string) error {
@@ -308,42 +613,127 @@ func (service *UserService) DeleteProfile(ctx context.Context, userId string) er
 	return nil
 }
 
-func (service *UserService) UploadPhoto(ctx context.Context, env *config.Environment, file multipart.File, fileName, userId string) (any, error) {
-	// create temp file
-	tempFile, err := os.CreateTemp("./", fileName)
+// UploadPhoto validates the picture, strips its metadata and stores resized
+// variants under a content-addressed prefix. Photos are private and served through signed urls.
+func (service *UserService) UploadPhoto(ctx context.Context, file io.Reader, userId string) (*dto.ResponseProfilePhoto, error) {
+	data, err := imaging.ReadAll(file, photoMaxBytes)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer tempFile.Close()
 
-	// read file
-	fileBytes, err := io.ReadAll(file)
+	img, err := imaging.Decode(data)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	tempFile.Write(fileBytes)
 
-	link := fmt.Sprintf("https://%s.%s/%s", env.OSSBucketName, env.OSSEndpoint, url.QueryEscape(fileName))
-	err = utils.PutFile(env.OSSBucketName, env.OSSEndpoint, env.OSSAccessKeyID,
-		env.OSSAccessKeySecret, tempFile.Name(), fileName)
+	profile, err := service.tables.profile.List(ctx, &common.FilterOptions{
+		Sort:   []exp.OrderedExpression{goqu.I("id").Desc()},
+		Filter: []exp.Expression{goqu.C("user_id").Eq(userId)},
+		Page:   1,
+		Limit:  1,
+	})
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
 	}
 
-	// save data to db
-	updatePhoto := dto.RequestUpdateProfile{
-		PhotoUrl: link,
+	if len(profile) == 0 {
+		return nil, errors.New("profile not found")
 	}
-	_, err = service.UpdateProfile(ctx, userId, &updatePhoto)
+
+	// keys are scoped by profile, profiles uploading the same image must not
+	// share objects one of them may delete
+	key := storage.ContentKey("profile-photos/"+profile[0].ID, data)
+	for _, variant := range photoVariants {
+		content, err := imaging.EncodeJPEG(imaging.Fit(img, variant.size))
+		if err != nil {
+			return nil, err
+		}
+
+		err = service.storage.Put(ctx, photoVariantKey(key, variant.name), bytes.NewReader(content), "image/jpeg", true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = service.tables.profile.Update(ctx, profile[0].ID, &models.Profile{PhotoKey: &key})
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	// the new photo is in place, a failure only leaves the previous one behind
+	if previous := profile[0].PhotoKey; previous != nil && *previous != "" && *previous != key {
+		for _, variant := range photoVariants {
+			err = service.storage.Delete(ctx, photoVariantKey(*previous, variant.name))
+			if err != nil {
+				logrus.Errorf("failed to delete previous profile photo: %s; profile: %s; err: %s", *previous, profile[0].ID, err)
+			}
+		}
+	}
+
+	return service.photoURLs(ctx, key)
+}
+
+func (service *UserService) photoURLs(ctx context.Context, key string) (*dto.ResponseProfilePhoto, error) {
+	res := dto.ResponseProfilePhoto{Photos: map[string]string{}}
+	for _, variant := range photoVariants {
+		link, err := service.storage.SignedURL(ctx, photoVariantKey(key, variant.name), photoLinkTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Photos[variant.name] = link
+	}
+	res.PhotoUrl = res.Photos[photoVariants[0].name]
+
+	return &res, nil
+}
+
+func photoVariantKey(key, variant string) string {
+	return key + "/" + variant + ".jpg"
+}
+
+// applyNIK cross-checks the date of birth and sex of an update against the nik,
+// then fills them and the region from it. A stored nik that can not be decoded
+// predates the decoder and is left alone.
+func applyNIK(update *models.Profile, current *models.Profile, body *dto.RequestUpdateProfile) error {
+	value := body.NIK
+	if value == "" && current.NIK != nil {
+		value = *current.NIK
+	}
+
+	if value == "" {
+		return nil
 	}
 
-	// remove temp file
-	err = os.Remove(tempFile.Name())
+	decoded, err := nik.Decode(value, time.Now())
 	if err != nil {
-		return "", err
+		return utils.Ternary(body.NIK != "", err, nil)
+	}
+
+	if !body.DOB.IsZero() && !decoded.SameDate(body.DOB) {
+		return fmt.Errorf("dob %w", ErrNIKMismatch)
+	}
+
+	if body.Sex != "" && body.Sex != decoded.Sex {
+		return fmt.Errorf("sex %w", ErrNIKMismatch)
+	}
+
+	update.DOB = &decoded.DOB
+	update.Sex = &decoded.Sex
+	update.RegionCode = &decoded.DistrictCode
+	return nil
+}
+
+// profileAge is computed from the date of birth so it never goes stale. The
+// stored age is only a fallback for profiles created without one.
+func profileAge(profile *models.Profile, now time.Time) string {
+	if profile.DOB != nil && !profile.DOB.IsZero() {
+		return strconv.Itoa(utils.Age(*profile.DOB, now))
+	}
+
+	if profile.Age != nil {
+		return *profile.Age
 	}
 
-	return link, nil
+	return ""
 }
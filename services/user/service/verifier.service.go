@@ -5,31 +5,41 @@ import (
 	"errors"
 	"fmt"
 	"monorepo/internal/config"
+	"monorepo/internal/constants"
 	"monorepo/pkg/common"
+	"monorepo/pkg/utils"
 	"monorepo/services/user/models"
 	"net/http"
+	"strconv"
+	"time"
 
 	"firebase.google.com/go/v4/auth"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
 	"github.com/go-chi/oauth"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type OauthVerifier struct {
-	fbaClient *auth.Client
-	env       *config.Environment
-	tables    struct {
-		user common.Repository[models.User, string]
+	fbaClient  *auth.Client
+	env        *config.Environment
+	mfaService *MFAService
+	tables     struct {
+		user    common.Repository[models.User, string]
+		session common.Repository[models.Session, string]
 	}
 }
 
 func NewOauthVerifier(
 	tbUser common.Repository[models.User, string],
+	tbSession common.Repository[models.Session, string],
 	fbaClient *auth.Client,
+	mfaService *MFAService,
 	env *config.Environment,
 ) *OauthVerifier {
-	verifier := &OauthVerifier{fbaClient: fbaClient, env: env}
+	verifier := &OauthVerifier{fbaClient: fbaClient, mfaService: mfaService, env: env}
 	verifier.tables.user = tbUser
+	verifier.tables.session = tbSession
 
 	return verifier
 }
@@ -55,16 +65,24 @@ func (verifier *OauthVerifier) ValidateUser(username, password, scope string, r
 		return err
 	}
 
-	// TODO: compare password
-	if username == user[0].Handle {
-		return nil
+	err = bcrypt.CompareHashAndPassword([]byte(user[0].Password), []byte(password))
+	if err != nil {
+		return errors.New("invalid user")
+	}
+
+	if user[0].DisabledAt.Valid {
+		return ErrAccountDisabled
 	}
 
-	// if username == user[0].Handle && password == "12345" {
-	// 	return nil
-	// }
+	// users with two-factor authentication must log in through the mfa challenge
+	enabled, err := verifier.mfaService.IsEnabled(r.Context(), user[0].ID)
+	if err != nil {
+		return err
+	} else if enabled {
+		return ErrMFARequired
+	}
 
-	return errors.New("invalid user")
+	return nil
 }
 
 // ValidateClient validates clientID and secret returning an error if the client credentials are wrong
@@ -86,7 +104,7 @@ func (verifier *OauthVerifier) AddClaims(tokenType oauth.TokenType, credential,
 	ctx := r.Context()
 	existing, err := verifier.tables.user.List(ctx, &common.FilterOptions{
 		Page: 1, Limit: 1,
-		Select: []any{"id", "handle", "created_at"},
+		Select: []any{"id", "handle", "role", "email_verified", "disabled_at", "created_at"},
 		Filter: []exp.Expression{
 			goqu.C("handle").Eq(credential),
 		},
@@ -98,9 +116,23 @@ func (verifier *OauthVerifier) AddClaims(tokenType oauth.TokenType, credential,
 	}
 
 	user := existing[0]
+	if user.DisabledAt.Valid {
+		return nil, ErrAccountDisabled
+	}
+
 	claims := map[string]string{
-		"x-hasura-default-role": "user",
-		"x-hasura-user-id":      user.ID,
+		"x-hasura-default-role":   utils.Ternary(user.Role != "", user.Role, constants.RoleUser),
+		"x-hasura-user-id":        user.ID,
+		"x-hasura-session-id":     tokenID,
+		"x-hasura-email-verified": strconv.FormatBool(user.EmailVerified),
+	}
+
+	if verifier.mfaService.IsRequired(user.Role) {
+		enabled, err := verifier.mfaService.IsEnabled(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		claims["x-hasura-mfa-required"] = strconv.FormatBool(!enabled)
 	}
 
 	return claims, nil
@@ -112,12 +144,68 @@ func (*OauthVerifier) AddProperties(tokenType oauth.TokenType, credential, token
 	return props, nil
 }
 
-// ValidateTokenID validates token ID
-func (*OauthVerifier) ValidateTokenID(tokenType oauth.TokenType, credential, tokenID, refreshTokenID string) error {
+// ValidateTokenID rejects refresh requests whose session has been revoked
+func (verifier *OauthVerifier) ValidateTokenID(tokenType oauth.TokenType, credential, tokenID, refreshTokenID string) error {
+	sessions, err := verifier.tables.session.List(context.Background(), &common.FilterOptions{
+		Filter: []exp.Expression{goqu.C("refresh_token_id").Eq(refreshTokenID)},
+		Page:   1,
+		Limit:  1,
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	}
+
+	if len(sessions) == 0 || sessions[0].RevokedAt.Valid {
+		return ErrSessionRevoked
+	}
+
 	return nil
 }
 
-// StoreTokenID saves the token id generated for the user
-func (*OauthVerifier) StoreTokenID(tokenType oauth.TokenType, credential, tokenID, refreshTokenID string) error {
+// StoreTokenID saves the token id generated for the user as a session
+func (verifier *OauthVerifier) StoreTokenID(tokenType oauth.TokenType, credential, tokenID, refreshTokenID string) error {
+	ctx := context.Background()
+	existing, err := verifier.tables.user.List(ctx, &common.FilterOptions{
+		Page: 1, Limit: 1,
+		Select: []any{"id", "handle", "created_at"},
+		Filter: []exp.Expression{
+			goqu.C("handle").Eq(credential),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryQueryFail, err)
+	} else if len(existing) <= 0 {
+		return ErrNoResult
+	}
+
+	err = verifier.tables.session.Create(ctx, &models.Session{
+		ID:             tokenID,
+		UserID:         existing[0].ID,
+		RefreshTokenID: refreshTokenID,
+		CreatedAt:      time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("%w; %w", ErrRepositoryMutateFail, err)
+	}
+
+	return nil
+}
+
+// ValidateSession checks that the session carried by an access token is still active.
+// Tokens issued before sessions were tracked have no session id and are accepted until they expire.
+func (verifier *OauthVerifier) ValidateSession(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	session, err := verifier.tables.session.Get(ctx, sessionID)
+	if err != nil {
+		return ErrSessionRevoked
+	}
+
+	if session.RevokedAt.Valid {
+		return ErrSessionRevoked
+	}
+
 	return nil
 }